@@ -7,12 +7,30 @@ import (
 	"net/http"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/kube-orchestra/maestro/internal/admission"
+	"github.com/kube-orchestra/maestro/internal/audit"
+	"github.com/kube-orchestra/maestro/internal/authn"
+	"github.com/kube-orchestra/maestro/internal/authz"
+	"github.com/kube-orchestra/maestro/internal/db"
+	"github.com/kube-orchestra/maestro/internal/encryption"
+	"github.com/kube-orchestra/maestro/internal/limits"
+	"github.com/kube-orchestra/maestro/internal/manifest"
 	"github.com/kube-orchestra/maestro/internal/mqtt"
+	"github.com/kube-orchestra/maestro/internal/placement"
+	"github.com/kube-orchestra/maestro/internal/policy"
+	auditeventsv1 "github.com/kube-orchestra/maestro/internal/service/v1/auditevents"
 	consumerv1 "github.com/kube-orchestra/maestro/internal/service/v1/consumers"
+	deadlettersv1 "github.com/kube-orchestra/maestro/internal/service/v1/deadletters"
+	placementsv1 "github.com/kube-orchestra/maestro/internal/service/v1/placements"
+	policiesv1 "github.com/kube-orchestra/maestro/internal/service/v1/policies"
+	resourcebundlesv1 "github.com/kube-orchestra/maestro/internal/service/v1/resourcebundles"
 	resourcesv1 "github.com/kube-orchestra/maestro/internal/service/v1/resources"
+	signingkeysv1 "github.com/kube-orchestra/maestro/internal/service/v1/signingkeys"
+	tenantsv1 "github.com/kube-orchestra/maestro/internal/service/v1/tenants"
+	"github.com/kube-orchestra/maestro/internal/signing"
 	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -20,9 +38,33 @@ const listenAddress = "0.0.0.0:8080"
 const listenAddressGateway = "0.0.0.0:8090"
 
 func main() {
+	retention, err := db.NewHistoryRetention()
+	if err != nil {
+		log.Fatalln("Failed to read the status history retention:", err)
+	}
+	db.SetHistoryRetention(retention)
+
+	err = db.MigrateConsumers()
+	if err != nil {
+		log.Fatalln("Failed to migrate the consumers stored before tenants:", err)
+	}
+	err = db.MigrateTemplatedResources()
+	if err != nil {
+		log.Fatalln("Failed to index the templated resources:", err)
+	}
+	err = db.CountConsumerResources()
+	if err != nil {
+		log.Fatalln("Failed to count the resources of the consumers:", err)
+	}
+
+	signer, err := signing.New()
+	if err != nil {
+		log.Fatalln("Failed to load message signing keys:", err)
+	}
+
 	mqttConnection := mqtt.NewConnection()
+	mqttConnection.Signer = signer
 	mqttConnection.StartSender()
-	mqttConnection.StartStatusReceiver()
 
 	// gRPC config
 
@@ -32,19 +74,118 @@ func main() {
 		log.Fatalln("Failed to listen:", err)
 	}
 
-	// Create a gRPC server object
-	s := grpc.NewServer()
+	auditSinks, err := audit.NewSinks()
+	if err != nil {
+		log.Fatalln("Failed to create audit sinks:", err)
+	}
+	auditor := audit.NewAuditor(auditSinks)
+
+	authenticator, err := authn.New()
+	if err != nil {
+		log.Fatalln("Failed to configure authentication:", err)
+	}
+	tlsConfig := authenticator.TLSConfig()
+
+	authorizer, err := authz.New()
+	if err != nil {
+		log.Fatalln("Failed to load authorization policy:", err)
+	}
+
+	// Create a gRPC server object, authenticating calls before auditing
+	// them, so calls denied by the authorization policy are audited
+	serverOptions := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			authenticator.UnaryServerInterceptor(),
+			auditor.UnaryServerInterceptor(),
+			authorizer.UnaryServerInterceptor(),
+		),
+		grpc.StreamInterceptor(authenticator.StreamServerInterceptor()),
+	}
+	if tlsConfig != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	s := grpc.NewServer(serverOptions...)
 	// Register reflection service on gRPC server.
 	reflection.Register(s)
 
-	// Attach the consumers service to the server
-	var consumersAPI = consumerv1.NewConsumerService()
-	v1.RegisterConsumerServiceServer(s, consumersAPI)
+	// Attach the tenants service to the server
+	var tenantsAPI = tenantsv1.NewTenantService()
+	v1.RegisterTenantServiceServer(s, tenantsAPI)
+
+	validator, err := manifest.NewValidator()
+	if err != nil {
+		log.Fatalln("Failed to create manifest validator:", err)
+	}
+
+	// objects of namespaced kinds without namespace have the keys of the
+	// default namespace
+	db.SetClusterScoped(validator.ClusterScoped)
+	err = db.MigrateResourceKeys()
+	if err != nil {
+		log.Fatalln("Failed to migrate resource keys:", err)
+	}
+
+	resourceLimits, err := limits.New()
+	if err != nil {
+		log.Fatalln("Failed to load resource limits:", err)
+	}
+
+	admissionChain, err := admission.New()
+	if err != nil {
+		log.Fatalln("Failed to load admission webhooks:", err)
+	}
+
+	policies, err := policy.New()
+	if err != nil {
+		log.Fatalln("Failed to load admission policies:", err)
+	}
+
+	encryptor, err := encryption.New()
+	if err != nil {
+		log.Fatalln("Failed to load encryption configuration:", err)
+	}
+
+	// Attach the policies service to the server
+	var policiesAPI = policiesv1.NewPolicyService(policies)
+	v1.RegisterPolicyServiceServer(s, policiesAPI)
 
 	// Attach the resources service to the server
-	var resourcesAPI = resourcesv1.NewResourceService(mqttConnection.ResourceChannel)
+	var resourcesAPI = resourcesv1.NewResourceService(mqttConnection.ResourceChannel, validator, resourceLimits, admissionChain, policies, encryptor)
 	v1.RegisterResourceServiceServer(s, resourcesAPI)
 
+	// held resources are published and rollouts of placements advance as
+	// resources report their status
+	placementReconciler := placement.NewReconciler(resourcesAPI)
+	mqttConnection.ResourceStatusObservers = append(mqttConnection.ResourceStatusObservers,
+		resourcesAPI.ResourceStatusChanged,
+		placementReconciler.ResourceStatusChanged,
+	)
+	mqttConnection.StartStatusReceiver()
+
+	// Attach the placements service to the server
+	var placementsAPI = placementsv1.NewPlacementService(validator, placementReconciler, encryptor)
+	v1.RegisterPlacementServiceServer(s, placementsAPI)
+
+	// Attach the consumers service to the server
+	var consumersAPI = consumerv1.NewConsumerService(placementReconciler)
+	v1.RegisterConsumerServiceServer(s, consumersAPI)
+
+	// Attach the resource bundles service to the server
+	var resourceBundlesAPI = resourcebundlesv1.NewResourceBundleService(mqttConnection.ResourceBundleChannel, validator, resourceLimits, encryptor)
+	v1.RegisterResourceBundleServiceServer(s, resourceBundlesAPI)
+
+	// Attach the audit service to the server
+	var auditAPI = auditeventsv1.NewAuditService()
+	v1.RegisterAuditServiceServer(s, auditAPI)
+
+	// Attach the signing keys service to the server
+	var signingKeysAPI = signingkeysv1.NewSigningKeyService(signer)
+	v1.RegisterSigningKeyServiceServer(s, signingKeysAPI)
+
+	// Attach the dead letters service to the server
+	var deadLettersAPI = deadlettersv1.NewDeadLetterService(mqttConnection)
+	v1.RegisterDeadLetterServiceServer(s, deadLettersAPI)
+
 	// Serve gRPC server
 	log.Println("Serving gRPC on", listenAddress)
 	go func() {
@@ -57,15 +198,25 @@ func main() {
 		context.Background(),
 		"localhost:8080",
 		grpc.WithBlock(),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		authenticator.GatewayDialOption(),
 	)
 	if err != nil {
 		log.Fatalln("Failed to dial server:", err)
 	}
 
-	gwmux := runtime.NewServeMux()
+	// forward the caller credentials, bearer tokens in the Authorization
+	// header and client certificates in metadata
+	gwmux := runtime.NewServeMux(
+		runtime.WithMetadata(authenticator.GatewayMetadata),
+		runtime.WithIncomingHeaderMatcher(authn.GatewayHeaderMatcher),
+	)
 
 	// Register Greeter
+	err = v1.RegisterTenantServiceHandler(context.Background(), gwmux, conn)
+	if err != nil {
+		log.Fatalln("Failed to register tenant service handler:", err)
+	}
+
 	err = v1.RegisterConsumerServiceHandler(context.Background(), gwmux, conn)
 	if err != nil {
 		log.Fatalln("Failed to register consumer service handler:", err)
@@ -76,9 +227,44 @@ func main() {
 		log.Fatalln("Failed to register resource service handler:", err)
 	}
 
+	err = v1.RegisterResourceBundleServiceHandler(context.Background(), gwmux, conn)
+	if err != nil {
+		log.Fatalln("Failed to register resource bundle service handler:", err)
+	}
+
+	err = v1.RegisterPlacementServiceHandler(context.Background(), gwmux, conn)
+	if err != nil {
+		log.Fatalln("Failed to register placement service handler:", err)
+	}
+
+	err = v1.RegisterPolicyServiceHandler(context.Background(), gwmux, conn)
+	if err != nil {
+		log.Fatalln("Failed to register policy service handler:", err)
+	}
+
+	err = v1.RegisterAuditServiceHandler(context.Background(), gwmux, conn)
+	if err != nil {
+		log.Fatalln("Failed to register audit service handler:", err)
+	}
+
+	err = v1.RegisterSigningKeyServiceHandler(context.Background(), gwmux, conn)
+	if err != nil {
+		log.Fatalln("Failed to register signing key service handler:", err)
+	}
+
+	err = v1.RegisterDeadLetterServiceHandler(context.Background(), gwmux, conn)
+	if err != nil {
+		log.Fatalln("Failed to register dead letter service handler:", err)
+	}
+
 	mux := http.NewServeMux()
 	mux.Handle("/", gwmux)
 
+	// mount a path to expose the generated OpenAPI specification on disk
+	mux.HandleFunc("/swagger-ui/tenant.swagger.json", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./swagger/api/v1/tenant.swagger.json")
+	})
+
 	// mount a path to expose the generated OpenAPI specification on disk
 	mux.HandleFunc("/swagger-ui/consumer.swagger.json", func(w http.ResponseWriter, r *http.Request) {
 		http.ServeFile(w, r, "./swagger/api/v1/consumer.swagger.json")
@@ -89,11 +275,46 @@ func main() {
 		http.ServeFile(w, r, "./swagger/api/v1/resource.swagger.json")
 	})
 
+	// mount a path to expose the generated OpenAPI specification on disk
+	mux.HandleFunc("/swagger-ui/resourcebundle.swagger.json", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./swagger/api/v1/resourcebundle.swagger.json")
+	})
+
+	// mount a path to expose the generated OpenAPI specification on disk
+	mux.HandleFunc("/swagger-ui/audit.swagger.json", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./swagger/api/v1/audit.swagger.json")
+	})
+
+	// mount a path to expose the generated OpenAPI specification on disk
+	mux.HandleFunc("/swagger-ui/policy.swagger.json", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./swagger/api/v1/policy.swagger.json")
+	})
+
+	// mount a path to expose the generated OpenAPI specification on disk
+	mux.HandleFunc("/swagger-ui/placement.swagger.json", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./swagger/api/v1/placement.swagger.json")
+	})
+
+	// mount a path to expose the generated OpenAPI specification on disk
+	mux.HandleFunc("/swagger-ui/signingkey.swagger.json", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./swagger/api/v1/signingkey.swagger.json")
+	})
+
+	// mount a path to expose the generated OpenAPI specification on disk
+	mux.HandleFunc("/swagger-ui/deadletter.swagger.json", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./swagger/api/v1/deadletter.swagger.json")
+	})
+
 	// mount the Swagger UI that uses the OpenAPI specification path above
 	mux.Handle("/swagger-ui/", http.StripPrefix("/swagger-ui/", http.FileServer(http.Dir("./swagger-ui"))))
 
 	log.Println("Serving gRPC-Gateway on", listenAddressGateway)
-	err = http.ListenAndServe(listenAddressGateway, mux)
+	gatewayServer := &http.Server{Addr: listenAddressGateway, Handler: mux, TLSConfig: authenticator.TLSConfig()}
+	if tlsConfig != nil {
+		err = gatewayServer.ListenAndServeTLS("", "")
+	} else {
+		err = gatewayServer.ListenAndServe()
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
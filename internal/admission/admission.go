@@ -0,0 +1,353 @@
+package admission
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kube-orchestra/maestro/internal/audit"
+	"github.com/kube-orchestra/maestro/internal/authn"
+	"github.com/kube-orchestra/maestro/internal/manifest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const admissionWebhooksFile = "ADMISSION_WEBHOOKS_FILE"
+
+// Types of webhooks. Mutating webhooks run first, in order, each seeing the
+// object patched by the previous ones. Validating webhooks then see the
+// final object.
+const (
+	TypeMutating   = "Mutating"
+	TypeValidating = "Validating"
+)
+
+// Failure policies, applied when a webhook can't be called or returns an
+// invalid response.
+const (
+	FailurePolicyFail   = "Fail"
+	FailurePolicyIgnore = "Ignore"
+)
+
+const (
+	defaultTimeoutSeconds = 10
+	maxTimeoutSeconds     = 30
+	// maxResponseBytes bounds the responses read from webhooks.
+	maxResponseBytes = 3 << 20
+)
+
+// ErrorDenied is returned when a webhook doesn't allow the object.
+type ErrorDenied struct {
+	Webhook string
+	Message string
+}
+
+func (e *ErrorDenied) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("admission webhook %q denied the request", e.Webhook)
+	}
+	return fmt.Sprintf("admission webhook %q denied the request: %s", e.Webhook, e.Message)
+}
+
+func (e *ErrorDenied) GRPCStatus() *status.Status {
+	return status.New(codes.PermissionDenied, e.Error())
+}
+
+// ErrorWebhookFailed is returned when a webhook whose failure policy is
+// Fail can't be called or returns an invalid response. The call may be
+// retried.
+type ErrorWebhookFailed struct {
+	Webhook string
+	Err     error
+}
+
+func (e *ErrorWebhookFailed) Error() string {
+	return fmt.Sprintf("failed calling admission webhook %q: %v", e.Webhook, e.Err)
+}
+
+func (e *ErrorWebhookFailed) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrorWebhookFailed) GRPCStatus() *status.Status {
+	return status.New(codes.Unavailable, e.Error())
+}
+
+type Config struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+type Webhook struct {
+	Name string `json:"name"`
+	// Mutating or Validating.
+	Type string `json:"type"`
+	// http or https URL the reviews are POSTed to.
+	URL string `json:"url"`
+	// Optional PEM bundle verifying https URLs, the system roots otherwise.
+	CAFile string `json:"caFile,omitempty"`
+	// 10 seconds by default, at most 30.
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+	// Fail, the default, or Ignore.
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+
+	// Client calls the webhook instead of a client built from CAFile, e.g.
+	// the client of an httptest server.
+	Client *http.Client `json:"-"`
+}
+
+// Chain calls the admission webhooks before resources are stored.
+type Chain struct {
+	mutating   []Webhook
+	validating []Webhook
+}
+
+// New loads the webhooks of the file ADMISSION_WEBHOOKS_FILE. Without file,
+// the chain admits every object as it is.
+func New() (*Chain, error) {
+	path := os.Getenv(admissionWebhooksFile)
+	if path == "" {
+		return &Chain{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("failed to read admission webhooks %s: %w", path, err)
+	}
+
+	chain, err := NewChain(config.Webhooks)
+	if err != nil {
+		return nil, fmt.Errorf("admission webhooks %s: %w", path, err)
+	}
+	return chain, nil
+}
+
+// NewChain validates the webhooks, applies their defaults and creates the
+// chain calling them.
+func NewChain(webhooks []Webhook) (*Chain, error) {
+	chain := &Chain{}
+	for i, w := range webhooks {
+		if w.Name == "" {
+			return nil, fmt.Errorf("webhooks[%d]: name is required", i)
+		}
+		u, err := url.Parse(w.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("webhooks[%d]: url must be an absolute http or https URL", i)
+		}
+
+		if w.TimeoutSeconds == 0 {
+			w.TimeoutSeconds = defaultTimeoutSeconds
+		}
+		if w.TimeoutSeconds < 0 || w.TimeoutSeconds > maxTimeoutSeconds {
+			return nil, fmt.Errorf("webhooks[%d]: timeoutSeconds must be between 1 and %d", i, maxTimeoutSeconds)
+		}
+
+		switch w.FailurePolicy {
+		case "":
+			w.FailurePolicy = FailurePolicyFail
+		case FailurePolicyFail, FailurePolicyIgnore:
+		default:
+			return nil, fmt.Errorf("webhooks[%d]: failurePolicy must be %s or %s", i, FailurePolicyFail, FailurePolicyIgnore)
+		}
+
+		if w.Client == nil {
+			w.Client, err = newClient(w.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("webhooks[%d]: %w", i, err)
+			}
+		}
+
+		switch w.Type {
+		case TypeMutating:
+			chain.mutating = append(chain.mutating, w)
+		case TypeValidating:
+			chain.validating = append(chain.validating, w)
+		default:
+			return nil, fmt.Errorf("webhooks[%d]: type must be %s or %s", i, TypeMutating, TypeValidating)
+		}
+	}
+	return chain, nil
+}
+
+func newClient(caFile string) (*http.Client, error) {
+	if caFile == "" {
+		return &http.Client{}, nil
+	}
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in %s", caFile)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	return &http.Client{Transport: transport}, nil
+}
+
+// WithCaller fills the UserInfo of req from the authenticated caller of ctx.
+func WithCaller(ctx context.Context, req *Request) *Request {
+	req.UserInfo = UserInfo{Username: audit.Principal(ctx)}
+	if id, ok := authn.IdentityFrom(ctx); ok {
+		req.UserInfo.Groups = id.Groups
+	}
+	return req
+}
+
+// Mutate passes req.Object through the mutating webhooks, replacing it with
+// the patched object. It tells whether any webhook patched it.
+func (c *Chain) Mutate(ctx context.Context, req *Request) (bool, error) {
+	mutated := false
+	for i := range c.mutating {
+		w := &c.mutating[i]
+		resp, err := w.review(ctx, req)
+		if err != nil {
+			if ignoreErr := w.failure(err); ignoreErr != nil {
+				return false, ignoreErr
+			}
+			continue
+		}
+		if !resp.Allowed {
+			return false, denied(w, resp)
+		}
+		if len(resp.Patch) == 0 {
+			continue
+		}
+
+		err = applyPatch(req, resp)
+		if err != nil {
+			if ignoreErr := w.failure(err); ignoreErr != nil {
+				return false, ignoreErr
+			}
+			continue
+		}
+		mutated = true
+	}
+	return mutated, nil
+}
+
+// Validate passes req.Object through the validating webhooks, failing with
+// ErrorDenied when any of them doesn't allow it.
+func (c *Chain) Validate(ctx context.Context, req *Request) error {
+	for i := range c.validating {
+		w := &c.validating[i]
+		resp, err := w.review(ctx, req)
+		if err != nil {
+			if ignoreErr := w.failure(err); ignoreErr != nil {
+				return ignoreErr
+			}
+			continue
+		}
+		if !resp.Allowed {
+			return denied(w, resp)
+		}
+	}
+	return nil
+}
+
+// review POSTs req to the webhook and returns its response.
+func (w *Webhook) review(ctx context.Context, req *Request) (*Response, error) {
+	req.UID = uuid.NewString()
+	body, err := reviewBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(w.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := w.Client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status %s", httpResp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(httpResp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, err
+	}
+	review := &Review{}
+	if err := json.Unmarshal(data, review); err != nil {
+		return nil, fmt.Errorf("invalid review: %w", err)
+	}
+	if review.Response == nil {
+		return nil, fmt.Errorf("review has no response")
+	}
+	if review.Response.UID != req.UID {
+		return nil, fmt.Errorf("response uid %q doesn't match request uid %q", review.Response.UID, req.UID)
+	}
+
+	for _, warning := range review.Response.Warnings {
+		log.Printf("Admission webhook %q warning: %s", w.Name, warning)
+	}
+	return review.Response, nil
+}
+
+// failure applies the failure policy of the webhook to err, returning
+// ErrorWebhookFailed, or nil when the failure is ignored.
+func (w *Webhook) failure(err error) error {
+	if w.FailurePolicy == FailurePolicyIgnore {
+		log.Printf("Ignoring failed admission webhook %q: %v", w.Name, err)
+		return nil
+	}
+	return &ErrorWebhookFailed{Webhook: w.Name, Err: err}
+}
+
+func denied(w *Webhook, resp *Response) error {
+	denied := &ErrorDenied{Webhook: w.Name}
+	if resp.Status != nil {
+		denied.Message = resp.Status.Message
+	}
+	return denied
+}
+
+func applyPatch(req *Request, resp *Response) error {
+	if resp.PatchType != PatchTypeJSONPatch {
+		return fmt.Errorf("unsupported patchType %q", resp.PatchType)
+	}
+
+	original, err := json.Marshal(req.Object.Object)
+	if err != nil {
+		return err
+	}
+	patched, err := manifest.JSONPatch(original, resp.Patch)
+	if err != nil {
+		return fmt.Errorf("invalid patch: %w", err)
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(patched, &object); err != nil {
+		return fmt.Errorf("invalid patch: %w", err)
+	}
+	req.Object.Object = object
+	return nil
+}
@@ -0,0 +1,341 @@
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		failurePolicy string
+		respond       func(w http.ResponseWriter, req *Request)
+		wantErr       error
+	}{
+		{
+			name: "allowed",
+			respond: func(w http.ResponseWriter, req *Request) {
+				writeResponse(w, &Response{UID: req.UID, Allowed: true, Warnings: []string{"deprecated field"}})
+			},
+		},
+		{
+			name: "denied",
+			respond: func(w http.ResponseWriter, req *Request) {
+				writeResponse(w, &Response{UID: req.UID, Status: &Status{Code: 403, Message: "replicas must be even"}})
+			},
+			wantErr: &ErrorDenied{Webhook: "test", Message: "replicas must be even"},
+		},
+		{
+			name:          "denied ignoring failures",
+			failurePolicy: FailurePolicyIgnore,
+			respond: func(w http.ResponseWriter, req *Request) {
+				writeResponse(w, &Response{UID: req.UID})
+			},
+			wantErr: &ErrorDenied{Webhook: "test"},
+		},
+		{
+			name: "uid mismatch",
+			respond: func(w http.ResponseWriter, req *Request) {
+				writeResponse(w, &Response{UID: "other", Allowed: true})
+			},
+			wantErr: &ErrorWebhookFailed{Webhook: "test"},
+		},
+		{
+			name: "no response",
+			respond: func(w http.ResponseWriter, req *Request) {
+				_ = json.NewEncoder(w).Encode(&Review{APIVersion: ReviewAPIVersion, Kind: ReviewKind})
+			},
+			wantErr: &ErrorWebhookFailed{Webhook: "test"},
+		},
+		{
+			name: "invalid review",
+			respond: func(w http.ResponseWriter, req *Request) {
+				_, _ = w.Write([]byte("allowed"))
+			},
+			wantErr: &ErrorWebhookFailed{Webhook: "test"},
+		},
+		{
+			name: "non 200",
+			respond: func(w http.ResponseWriter, req *Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			wantErr: &ErrorWebhookFailed{Webhook: "test"},
+		},
+		{
+			name:          "non 200 ignored",
+			failurePolicy: FailurePolicyIgnore,
+			respond: func(w http.ResponseWriter, req *Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+		},
+		{
+			name:    "timeout",
+			respond: hang,
+			wantErr: &ErrorWebhookFailed{Webhook: "test"},
+		},
+		{
+			name:          "timeout ignored",
+			failurePolicy: FailurePolicyIgnore,
+			respond:       hang,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := newTestChain(t, TypeValidating, tt.failurePolicy, tt.respond)
+
+			err := chain.Validate(context.Background(), newRequest())
+			checkError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestMutate(t *testing.T) {
+	addLabel := func(w http.ResponseWriter, req *Request) {
+		writeResponse(w, &Response{
+			UID:       req.UID,
+			Allowed:   true,
+			Patch:     []byte(`[{"op": "add", "path": "/metadata/labels", "value": {"team": "web"}}]`),
+			PatchType: PatchTypeJSONPatch,
+		})
+	}
+	withLabel := newRequest().Object.DeepCopy()
+	withLabel.SetLabels(map[string]string{"team": "web"})
+
+	tests := []struct {
+		name          string
+		failurePolicy string
+		respond       func(w http.ResponseWriter, req *Request)
+		wantMutated   bool
+		wantObject    *unstructured.Unstructured
+		wantErr       error
+	}{
+		{
+			name:        "json patch",
+			respond:     addLabel,
+			wantMutated: true,
+			wantObject:  withLabel,
+		},
+		{
+			name: "allowed without patch",
+			respond: func(w http.ResponseWriter, req *Request) {
+				writeResponse(w, &Response{UID: req.UID, Allowed: true})
+			},
+			wantObject: newRequest().Object,
+		},
+		{
+			name: "denied",
+			respond: func(w http.ResponseWriter, req *Request) {
+				writeResponse(w, &Response{UID: req.UID, Status: &Status{Message: "no"}})
+			},
+			wantErr: &ErrorDenied{Webhook: "test", Message: "no"},
+		},
+		{
+			name: "unsupported patch type",
+			respond: func(w http.ResponseWriter, req *Request) {
+				writeResponse(w, &Response{UID: req.UID, Allowed: true, Patch: []byte(`{}`), PatchType: "MergePatch"})
+			},
+			wantErr: &ErrorWebhookFailed{Webhook: "test"},
+		},
+		{
+			name:          "invalid patch ignored",
+			failurePolicy: FailurePolicyIgnore,
+			respond: func(w http.ResponseWriter, req *Request) {
+				writeResponse(w, &Response{
+					UID:       req.UID,
+					Allowed:   true,
+					Patch:     []byte(`[{"op": "remove", "path": "/spec/missing"}]`),
+					PatchType: PatchTypeJSONPatch,
+				})
+			},
+			wantObject: newRequest().Object,
+		},
+		{
+			name:    "timeout",
+			respond: hang,
+			wantErr: &ErrorWebhookFailed{Webhook: "test"},
+		},
+		{
+			name:          "timeout ignored",
+			failurePolicy: FailurePolicyIgnore,
+			respond:       hang,
+			wantObject:    newRequest().Object,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := newTestChain(t, TypeMutating, tt.failurePolicy, tt.respond)
+
+			req := newRequest()
+			mutated, err := chain.Mutate(context.Background(), req)
+			checkError(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+			if mutated != tt.wantMutated {
+				t.Errorf("mutated = %v, want %v", mutated, tt.wantMutated)
+			}
+			if !reflect.DeepEqual(req.Object.Object, tt.wantObject.Object) {
+				t.Errorf("got object %v, want %v", req.Object.Object, tt.wantObject.Object)
+			}
+		})
+	}
+}
+
+func TestMutateInOrder(t *testing.T) {
+	patch := func(path, value string) func(w http.ResponseWriter, req *Request) {
+		return func(w http.ResponseWriter, req *Request) {
+			writeResponse(w, &Response{
+				UID:       req.UID,
+				Allowed:   true,
+				Patch:     []byte(`[{"op": "add", "path": "` + path + `", "value": "` + value + `"}]`),
+				PatchType: PatchTypeJSONPatch,
+			})
+		}
+	}
+
+	var validated *unstructured.Unstructured
+	chain, err := NewChain([]Webhook{
+		newTestWebhook(t, "first", TypeMutating, "", patch("/metadata/namespace", "web")),
+		newTestWebhook(t, "second", TypeMutating, "", func(w http.ResponseWriter, req *Request) {
+			// sees the object patched by the first webhook
+			patch("/metadata/name", req.Object.GetNamespace()+"-app")(w, req)
+		}),
+		newTestWebhook(t, "validating", TypeValidating, "", func(w http.ResponseWriter, req *Request) {
+			validated = req.Object
+			writeResponse(w, &Response{UID: req.UID, Allowed: true})
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := newRequest()
+	if _, err := chain.Mutate(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.Validate(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if validated == nil || validated.GetNamespace() != "web" || validated.GetName() != "web-app" {
+		t.Errorf("validated object %v, want the patched object", validated)
+	}
+}
+
+func TestNewChainInvalid(t *testing.T) {
+	valid := Webhook{Name: "test", Type: TypeValidating, URL: "https://webhook.example.com"}
+	tests := []struct {
+		name    string
+		change  func(w *Webhook)
+		wantErr string
+	}{
+		{name: "missing name", change: func(w *Webhook) { w.Name = "" }, wantErr: "name is required"},
+		{name: "relative url", change: func(w *Webhook) { w.URL = "/review" }, wantErr: "url"},
+		{name: "unknown type", change: func(w *Webhook) { w.Type = "Auditing" }, wantErr: "type"},
+		{name: "timeout", change: func(w *Webhook) { w.TimeoutSeconds = maxTimeoutSeconds + 1 }, wantErr: "timeoutSeconds"},
+		{name: "failure policy", change: func(w *Webhook) { w.FailurePolicy = "Retry" }, wantErr: "failurePolicy"},
+		{name: "missing ca file", change: func(w *Webhook) { w.CAFile = "/missing/ca.pem" }, wantErr: "ca.pem"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := valid
+			tt.change(&w)
+			_, err := NewChain([]Webhook{w})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// newTestChain returns a chain of a single webhook named test.
+func newTestChain(t *testing.T, webhookType, failurePolicy string, respond func(w http.ResponseWriter, req *Request)) *Chain {
+	t.Helper()
+	chain, err := NewChain([]Webhook{newTestWebhook(t, "test", webhookType, failurePolicy, respond)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return chain
+}
+
+// newTestWebhook starts a webhook server passing the requests of the
+// reviews it receives to respond.
+func newTestWebhook(t *testing.T, name, webhookType, failurePolicy string, respond func(w http.ResponseWriter, req *Request)) Webhook {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		review := &Review{}
+		if err := json.NewDecoder(r.Body).Decode(review); err != nil || review.Request == nil {
+			http.Error(w, "invalid review", http.StatusBadRequest)
+			return
+		}
+		if review.APIVersion != ReviewAPIVersion || review.Kind != ReviewKind || review.Request.UID == "" {
+			http.Error(w, "invalid review", http.StatusBadRequest)
+			return
+		}
+		respond(w, review.Request)
+	}))
+	t.Cleanup(server.Close)
+
+	return Webhook{
+		Name:           name,
+		Type:           webhookType,
+		URL:            server.URL,
+		TimeoutSeconds: 1,
+		FailurePolicy:  failurePolicy,
+		Client:         server.Client(),
+	}
+}
+
+func writeResponse(w http.ResponseWriter, resp *Response) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(&Review{APIVersion: ReviewAPIVersion, Kind: ReviewKind, Response: resp})
+}
+
+// hang doesn't respond before the webhook times out.
+func hang(_ http.ResponseWriter, req *Request) {
+	time.Sleep(1500 * time.Millisecond)
+}
+
+func newRequest() *Request {
+	return &Request{
+		Operation:  OperationCreate,
+		ResourceId: "resource1",
+		TenantId:   "default",
+		ConsumerId: "cluster1",
+		Object: &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "app"},
+			"data":       map[string]interface{}{"key": "value"},
+		}},
+	}
+}
+
+// checkError checks err has the type of want, and its webhook and message.
+func checkError(t *testing.T, err, want error) {
+	t.Helper()
+	switch want := want.(type) {
+	case nil:
+		if err != nil {
+			t.Fatalf("got error %v, want none", err)
+		}
+	case *ErrorDenied:
+		var denied *ErrorDenied
+		if !errors.As(err, &denied) || *denied != *want {
+			t.Fatalf("got error %v, want %v", err, want)
+		}
+	case *ErrorWebhookFailed:
+		var failed *ErrorWebhookFailed
+		if !errors.As(err, &failed) || failed.Webhook != want.Webhook || failed.Err == nil {
+			t.Fatalf("got error %v, want %v", err, want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package admission
+
+import (
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// The payload sent to the webhooks follows the Kubernetes AdmissionReview,
+// with the tenant and consumer of the resource in place of the Kubernetes
+// resource coordinates.
+
+const (
+	ReviewAPIVersion = "admission.maestro.kube-orchestra.io/v1"
+	ReviewKind       = "AdmissionReview"
+)
+
+// Operations of the reviewed calls.
+const (
+	OperationCreate = "CREATE"
+	OperationUpdate = "UPDATE"
+)
+
+// PatchTypeJSONPatch is the only patch type mutating webhooks may return.
+const PatchTypeJSONPatch = "JSONPatch"
+
+type Review struct {
+	APIVersion string    `json:"apiVersion"`
+	Kind       string    `json:"kind"`
+	Request    *Request  `json:"request,omitempty"`
+	Response   *Response `json:"response,omitempty"`
+}
+
+// Request describes the object of a resource about to be stored.
+type Request struct {
+	// UID identifies the review, the response must carry it back.
+	UID       string `json:"uid"`
+	Operation string `json:"operation"`
+	// Id of the resource.
+	ResourceId     string            `json:"resourceId"`
+	TenantId       string            `json:"tenantId"`
+	ConsumerId     string            `json:"consumerId"`
+	ConsumerLabels map[string]string `json:"consumerLabels,omitempty"`
+	UserInfo       UserInfo          `json:"userInfo"`
+	// Object is the object to store. Mutating webhooks update it in place.
+	Object *unstructured.Unstructured `json:"object"`
+	// OldObject is the stored object of updates.
+	OldObject *unstructured.Unstructured `json:"oldObject,omitempty"`
+}
+
+type UserInfo struct {
+	Username string   `json:"username"`
+	Groups   []string `json:"groups,omitempty"`
+}
+
+type Response struct {
+	UID     string `json:"uid"`
+	Allowed bool   `json:"allowed"`
+	// Status explains why the object isn't allowed.
+	Status *Status `json:"status,omitempty"`
+	// Patch is a JSON patch, RFC 6902, to apply to the object, only for
+	// mutating webhooks. It is base64 encoded in JSON.
+	Patch     []byte `json:"patch,omitempty"`
+	PatchType string `json:"patchType,omitempty"`
+	// Warnings are logged, they don't fail the call.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type Status struct {
+	Code    int32  `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// reviewBody returns the JSON review of req.
+func reviewBody(req *Request) ([]byte, error) {
+	return json.Marshal(&Review{
+		APIVersion: ReviewAPIVersion,
+		Kind:       ReviewKind,
+		Request:    req,
+	})
+}
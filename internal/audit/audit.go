@@ -0,0 +1,138 @@
+package audit
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kube-orchestra/maestro/internal/db"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Anonymous is the principal of unauthenticated calls.
+const Anonymous = "anonymous"
+
+// readOnlyMethods don't mutate anything and aren't audited.
+var readOnlyMethods = map[string]bool{
+	"Read":    true,
+	"Lookup":  true,
+	"History": true,
+	"Query":   true,
+	"Usage":   true,
+	"List":    true,
+}
+
+type principalKey struct{}
+
+type recordKey struct{}
+
+// record collects what a service knows about the target of a call.
+type record struct {
+	tenantId      string
+	targetKind    string
+	targetId      string
+	oldGeneration int64
+	newGeneration int64
+	changes       []string
+}
+
+// WithPrincipal returns a copy of ctx carrying the caller of the call.
+func WithPrincipal(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// Principal returns the caller of the call, Anonymous if unknown.
+func Principal(ctx context.Context) string {
+	if p, ok := ctx.Value(principalKey{}).(string); ok && p != "" {
+		return p
+	}
+	return Anonymous
+}
+
+// SetTarget records the target of the audited call of ctx, for calls
+// whose request doesn't name it, e.g. creations.
+func SetTarget(ctx context.Context, kind, id string) {
+	if r, ok := ctx.Value(recordKey{}).(*record); ok {
+		r.targetKind = kind
+		r.targetId = id
+	}
+}
+
+// SetChange records the generations of the target before and after the
+// audited call of ctx, and the paths of the fields it changed.
+func SetChange(ctx context.Context, oldGeneration, newGeneration int64, changes []string) {
+	if r, ok := ctx.Value(recordKey{}).(*record); ok {
+		r.oldGeneration = oldGeneration
+		r.newGeneration = newGeneration
+		r.changes = changes
+	}
+}
+
+// Auditor records the mutating calls of a gRPC server to its sinks.
+type Auditor struct {
+	sinks []Sink
+}
+
+func NewAuditor(sinks []Sink) *Auditor {
+	return &Auditor{sinks: sinks}
+}
+
+// UnaryServerInterceptor records every mutating call, whether it
+// succeeds or not.
+func (a *Auditor) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		service, method := splitMethod(info.FullMethod)
+		if readOnlyMethods[method] {
+			return handler(ctx, req)
+		}
+
+		r := &record{targetKind: strings.TrimSuffix(service, "Service")}
+		if withId, ok := req.(interface{ GetId() string }); ok {
+			r.targetId = withId.GetId()
+		}
+		if withTenant, ok := req.(interface{ GetTenantId() string }); ok {
+			r.tenantId = db.TenantOrDefault(withTenant.GetTenantId())
+		}
+
+		resp, err := handler(context.WithValue(ctx, recordKey{}, r), req)
+
+		a.write(&db.AuditEvent{
+			Id:            uuid.NewString(),
+			Timestamp:     time.Now().UnixNano(),
+			Principal:     Principal(ctx),
+			Method:        info.FullMethod,
+			TargetKind:    r.targetKind,
+			TargetId:      r.targetId,
+			OldGeneration: r.oldGeneration,
+			NewGeneration: r.newGeneration,
+			Changes:       r.changes,
+			Code:          status.Code(err).String(),
+			TenantId:      r.tenantId,
+		})
+
+		return resp, err
+	}
+}
+
+// write hands e to every sink. Failing sinks are logged, they don't fail
+// the audited call.
+func (a *Auditor) write(e *db.AuditEvent) {
+	for _, sink := range a.sinks {
+		if err := sink.Write(e); err != nil {
+			log.Printf("Failed to write audit event %s: %v", e.Id, err)
+		}
+	}
+}
+
+// splitMethod splits "/v1.ResourceService/Update" into "ResourceService"
+// and "Update".
+func splitMethod(fullMethod string) (string, string) {
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if i := strings.LastIndex(service, "."); i >= 0 {
+		service = service[i+1:]
+	}
+	return service, method
+}
@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// maxChanges bounds the paths reported by Diff.
+const maxChanges = 50
+
+// Diff returns the sorted dot-separated paths of the fields that differ
+// between the JSON encodings of old and new. Lists are compared as a
+// whole. At most maxChanges paths are returned, the last one being "..."
+// when there are more.
+func Diff(old, new interface{}) []string {
+	var changes []string
+	diff("", toJSONValue(old), toJSONValue(new), &changes)
+	sort.Strings(changes)
+
+	if len(changes) > maxChanges {
+		changes = append(changes[:maxChanges-1], "...")
+	}
+	return changes
+}
+
+func diff(path string, old, new interface{}, changes *[]string) {
+	oldMap, oldIsMap := old.(map[string]interface{})
+	newMap, newIsMap := new.(map[string]interface{})
+	if !oldIsMap || !newIsMap {
+		if !reflect.DeepEqual(old, new) {
+			*changes = append(*changes, path)
+		}
+		return
+	}
+
+	for key, oldValue := range oldMap {
+		diff(join(path, key), oldValue, newMap[key], changes)
+	}
+	for key, newValue := range newMap {
+		if _, ok := oldMap[key]; !ok {
+			diff(join(path, key), nil, newValue, changes)
+		}
+	}
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return strings.Join([]string{path, key}, ".")
+}
+
+// toJSONValue decodes the JSON encoding of v, so values of different Go
+// types compare equal when they encode the same way.
+func toJSONValue(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil
+	}
+	return decoded
+}
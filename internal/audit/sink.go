@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/kube-orchestra/maestro/internal/db"
+)
+
+const auditSinks = "AUDIT_SINKS"
+
+// Sink stores audit events.
+type Sink interface {
+	Write(e *db.AuditEvent) error
+}
+
+// NewSinks returns the sinks listed in AUDIT_SINKS, a comma separated list
+// of "stdout", "store" and "file:<path>". It defaults to "store", which
+// keeps the events queryable through the AuditService.
+func NewSinks() ([]Sink, error) {
+	config := os.Getenv(auditSinks)
+	if config == "" {
+		config = "store"
+	}
+
+	var sinks []Sink
+	for _, name := range strings.Split(config, ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "stdout":
+			sinks = append(sinks, NewWriterSink(os.Stdout))
+		case name == "store":
+			sinks = append(sinks, StoreSink{})
+		case strings.HasPrefix(name, "file:"):
+			f, err := os.OpenFile(strings.TrimPrefix(name, "file:"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, NewWriterSink(f))
+		case name == "":
+		default:
+			return nil, fmt.Errorf("unknown audit sink %q in %s", name, auditSinks)
+		}
+	}
+	return sinks, nil
+}
+
+// WriterSink writes audit events to w as JSON lines.
+type WriterSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{enc: json.NewEncoder(w)}
+}
+
+func (s *WriterSink) Write(e *db.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(e)
+}
+
+// StoreSink stores audit events in the AuditEvents table.
+type StoreSink struct{}
+
+func (StoreSink) Write(e *db.AuditEvent) error {
+	return db.PutAuditEvent(e)
+}
@@ -0,0 +1,233 @@
+package authn
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kube-orchestra/maestro/internal/audit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	tlsCertFile         = "TLS_CERT_FILE"
+	tlsKeyFile          = "TLS_KEY_FILE"
+	authnClientCAFile   = "AUTHN_CLIENT_CA_FILE"
+	authnTokenFile      = "AUTHN_TOKEN_FILE"
+	authnAllowAnonymous = "AUTHN_ALLOW_ANONYMOUS"
+)
+
+// Identity is the authenticated caller of a call.
+type Identity struct {
+	Name   string
+	Groups []string
+}
+
+// Authenticator authenticates the caller of a call from its credentials.
+// It returns a nil Identity and no error when the call carries no
+// credentials it handles.
+type Authenticator interface {
+	Authenticate(ctx context.Context) (*Identity, error)
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying id.
+func WithIdentity(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// IdentityFrom returns the identity of the caller, false for anonymous
+// calls.
+func IdentityFrom(ctx context.Context) (*Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(*Identity)
+	return id, ok
+}
+
+// Authn authenticates the calls of the gRPC server, and of the gateway in
+// front of it.
+type Authn struct {
+	authenticators []Authenticator
+	allowAnonymous bool
+	tlsConfig      *tls.Config
+	gatewaySecret  string
+}
+
+// New configures authentication from the environment:
+//   - TLS_CERT_FILE and TLS_KEY_FILE serve both APIs over TLS,
+//   - AUTHN_CLIENT_CA_FILE authenticates client certificates issued by
+//     these CAs, the certificate CN being the name and O the groups,
+//   - AUTHN_TOKEN_FILE authenticates static bearer tokens,
+//   - AUTHN_OIDC_* authenticate OIDC bearer tokens, see newOIDCAuthenticator.
+//
+// Once an authenticator is configured, calls without credentials are
+// rejected, unless AUTHN_ALLOW_ANONYMOUS is true.
+func New() (*Authn, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	a := &Authn{gatewaySecret: hex.EncodeToString(secret)}
+
+	certFile, keyFile := os.Getenv(tlsCertFile), os.Getenv(tlsKeyFile)
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s and %s: %w", tlsCertFile, tlsKeyFile, err)
+		}
+		a.tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	}
+
+	if caFile := os.Getenv(authnClientCAFile); caFile != "" {
+		if a.tlsConfig == nil {
+			return nil, fmt.Errorf("%s requires %s and %s", authnClientCAFile, tlsCertFile, tlsKeyFile)
+		}
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", caFile)
+		}
+		a.tlsConfig.ClientCAs = pool
+		a.tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		a.authenticators = append(a.authenticators, &clientCertAuthenticator{gatewaySecret: a.gatewaySecret})
+	}
+
+	if tokenFile := os.Getenv(authnTokenFile); tokenFile != "" {
+		tokens, err := newTokenAuthenticator(tokenFile)
+		if err != nil {
+			return nil, err
+		}
+		a.authenticators = append(a.authenticators, tokens)
+	}
+
+	oidcAuthenticator, err := newOIDCAuthenticator()
+	if err != nil {
+		return nil, err
+	}
+	if oidcAuthenticator != nil {
+		a.authenticators = append(a.authenticators, oidcAuthenticator)
+	}
+
+	a.allowAnonymous = len(a.authenticators) == 0
+	if v := os.Getenv(authnAllowAnonymous); v != "" {
+		a.allowAnonymous, err = strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a boolean: %w", authnAllowAnonymous, err)
+		}
+	}
+
+	return a, nil
+}
+
+// TLSConfig returns the server TLS configuration, nil when the APIs are
+// served in plain text.
+func (a *Authn) TLSConfig() *tls.Config {
+	if a.tlsConfig == nil {
+		return nil
+	}
+	return a.tlsConfig.Clone()
+}
+
+// GatewayDialOption returns the transport credentials of the gateway to
+// dial the gRPC server. Over TLS, the gateway only trusts the certificate
+// the server was configured with.
+func (a *Authn) GatewayDialOption() grpc.DialOption {
+	if a.tlsConfig == nil {
+		return grpc.WithTransportCredentials(insecure.NewCredentials())
+	}
+
+	serverCert := a.tlsConfig.Certificates[0].Certificate[0]
+	return grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// the peer certificate is pinned below instead
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 || !bytes.Equal(rawCerts[0], serverCert) {
+				return errors.New("unexpected gRPC server certificate")
+			}
+			return nil
+		},
+	}))
+}
+
+// authenticate returns ctx carrying the identity of the caller.
+func (a *Authn) authenticate(ctx context.Context) (context.Context, error) {
+	for _, authenticator := range a.authenticators {
+		id, err := authenticator.Authenticate(ctx)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if id != nil {
+			return audit.WithPrincipal(WithIdentity(ctx, id), id.Name), nil
+		}
+	}
+
+	if _, ok := bearerToken(ctx); ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid bearer token")
+	}
+	if !a.allowAnonymous {
+		return nil, status.Error(codes.Unauthenticated, "credentials are required")
+	}
+	return ctx, nil
+}
+
+func (a *Authn) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (a *Authn) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// bearerToken returns the token of the "authorization: Bearer <token>"
+// metadata of the call.
+func bearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	for _, v := range md.Get("authorization") {
+		scheme, token, found := strings.Cut(v, " ")
+		if found && strings.EqualFold(scheme, "Bearer") && token != "" {
+			return token, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,394 @@
+package authn
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testIssuer   = "https://issuer.example.com"
+	testClientID = "maestro"
+)
+
+func TestTokens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.csv")
+	tokens := "# token,user,uid,groups\n" +
+		"token-1,alice,1,\"admins,devs\"\n" +
+		"token-2,bob,2\n"
+	if err := os.WriteFile(path, []byte(tokens), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(authnTokenFile, path)
+	t.Setenv(authnOIDCIssuerURL, "")
+	t.Setenv(authnAllowAnonymous, "")
+	a, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		md       metadata.MD
+		want     *Identity
+		wantCode codes.Code
+	}{
+		{
+			name: "token with groups",
+			md:   metadata.Pairs("authorization", "Bearer token-1"),
+			want: &Identity{Name: "alice", Groups: []string{"admins", "devs"}},
+		},
+		{
+			name: "token without groups",
+			md:   metadata.Pairs("authorization", "bearer token-2"),
+			want: &Identity{Name: "bob"},
+		},
+		{
+			name:     "unknown token",
+			md:       metadata.Pairs("authorization", "Bearer token-3"),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "no credentials",
+			md:       metadata.MD{},
+			wantCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testAuthenticate(t, a, tt.md, tt.want, tt.wantCode)
+		})
+	}
+}
+
+func TestTokensInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.csv")
+	if err := os.WriteFile(path, []byte("token-1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := newTokenAuthenticator(path)
+	if err == nil || !strings.Contains(err.Error(), "line 1: a token and a user are required") {
+		t.Errorf("got error %v, want the line without user", err)
+	}
+}
+
+func TestAllowAnonymous(t *testing.T) {
+	a := &Authn{authenticators: []Authenticator{&tokenAuthenticator{}}, allowAnonymous: true}
+	testAuthenticate(t, a, metadata.MD{}, nil, codes.OK)
+	// callers presenting invalid credentials aren't anonymous
+	testAuthenticate(t, a, metadata.Pairs("authorization", "Bearer token-1"), nil, codes.Unauthenticated)
+}
+
+func TestOIDC(t *testing.T) {
+	key, jwksFile := newTestJWKS(t)
+	t.Setenv(authnOIDCIssuerURL, testIssuer)
+	t.Setenv(authnOIDCClientID, testClientID)
+	t.Setenv(authnOIDCJWKSFile, jwksFile)
+	t.Setenv(authnOIDCUsernameClaim, "email")
+	t.Setenv(authnOIDCGroupsClaim, "")
+	oidcAuthenticator, err := newOIDCAuthenticator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &Authn{authenticators: []Authenticator{oidcAuthenticator}}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	valid := map[string]interface{}{
+		"iss":    testIssuer,
+		"aud":    testClientID,
+		"sub":    "1234",
+		"email":  "alice@example.com",
+		"groups": []string{"admins"},
+		"iat":    now.Unix(),
+		"exp":    now.Add(time.Hour).Unix(),
+	}
+	with := func(claim string, value interface{}) map[string]interface{} {
+		claims := map[string]interface{}{}
+		for k, v := range valid {
+			claims[k] = v
+		}
+		if value == nil {
+			delete(claims, claim)
+		} else {
+			claims[claim] = value
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		want     *Identity
+		wantCode codes.Code
+	}{
+		{
+			name:  "valid",
+			token: signJWT(t, key, valid),
+			want:  &Identity{Name: "alice@example.com", Groups: []string{"admins"}},
+		},
+		{
+			name:     "expired",
+			token:    signJWT(t, key, with("exp", now.Add(-time.Minute).Unix())),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "wrong audience",
+			token:    signJWT(t, key, with("aud", "other")),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "wrong issuer",
+			token:    signJWT(t, key, with("iss", "https://other.example.com")),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "unknown key",
+			token:    signJWT(t, otherKey, valid),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "missing username claim",
+			token:    signJWT(t, key, with("email", nil)),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "not a JWT",
+			token:    "token-1",
+			wantCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testAuthenticate(t, a, metadata.Pairs("authorization", "Bearer "+tt.token), tt.want, tt.wantCode)
+		})
+	}
+}
+
+func TestOIDCDiscovery(t *testing.T) {
+	key, jwksFile := newTestJWKS(t)
+	jwks, err := os.ReadFile(jwksFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                server.URL,
+			"jwks_uri":                              server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(jwks)
+	})
+
+	t.Setenv(authnOIDCIssuerURL, server.URL)
+	t.Setenv(authnOIDCClientID, testClientID)
+	t.Setenv(authnOIDCJWKSFile, "")
+	t.Setenv(authnOIDCUsernameClaim, "")
+	t.Setenv(authnOIDCGroupsClaim, "")
+	oidcAuthenticator, err := newOIDCAuthenticator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &Authn{authenticators: []Authenticator{oidcAuthenticator}}
+
+	claims := map[string]interface{}{
+		"iss": server.URL,
+		"aud": testClientID,
+		"sub": "alice",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	testAuthenticate(t, a, metadata.Pairs("authorization", "Bearer "+signJWT(t, key, claims)), &Identity{Name: "alice"}, codes.OK)
+
+	claims["aud"] = "other"
+	testAuthenticate(t, a, metadata.Pairs("authorization", "Bearer "+signJWT(t, key, claims)), nil, codes.Unauthenticated)
+}
+
+func TestGatewayClientCert(t *testing.T) {
+	cert := newTestCert(t)
+	forwarded := base64.StdEncoding.EncodeToString(cert.Raw)
+	a := &Authn{authenticators: []Authenticator{&clientCertAuthenticator{gatewaySecret: "secret"}}, gatewaySecret: "secret"}
+
+	tests := []struct {
+		name     string
+		md       metadata.MD
+		want     *Identity
+		wantCode codes.Code
+	}{
+		{
+			name: "forwarded by the gateway",
+			md:   metadata.Pairs(gatewaySecretKey, "secret", gatewayClientCertKey, forwarded),
+			want: &Identity{Name: "alice", Groups: []string{"admins"}},
+		},
+		{
+			name:     "without secret",
+			md:       metadata.Pairs(gatewayClientCertKey, forwarded),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "wrong secret",
+			md:       metadata.Pairs(gatewaySecretKey, "forged", gatewayClientCertKey, forwarded),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "several secrets",
+			md:       metadata.Pairs(gatewaySecretKey, "forged", gatewaySecretKey, "secret", gatewayClientCertKey, forwarded),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "invalid certificate",
+			md:       metadata.Pairs(gatewaySecretKey, "secret", gatewayClientCertKey, "forged"),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "no certificate",
+			md:       metadata.Pairs(gatewaySecretKey, "secret"),
+			wantCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testAuthenticate(t, a, tt.md, tt.want, tt.wantCode)
+		})
+	}
+}
+
+func TestGatewayHeaderMatcher(t *testing.T) {
+	tests := []struct {
+		header  string
+		wantKey string
+		wantOK  bool
+	}{
+		{header: "Grpc-Metadata-X-Maestro-Client-Certificate"},
+		{header: "grpc-metadata-x-maestro-gateway-secret"},
+		{header: "Grpc-Metadata-X-Maestro-Other"},
+		{header: "Grpc-Metadata-Request-Id", wantKey: "Request-Id", wantOK: true},
+		{header: "Authorization", wantKey: "grpcgateway-Authorization", wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			key, ok := GatewayHeaderMatcher(tt.header)
+			if key != tt.wantKey || ok != tt.wantOK {
+				t.Errorf("got %q %v, want %q %v", key, ok, tt.wantKey, tt.wantOK)
+			}
+		})
+	}
+}
+
+// testAuthenticate checks a call with metadata md is authenticated as
+// want, or fails with wantCode.
+func testAuthenticate(t *testing.T, a *Authn, md metadata.MD, want *Identity, wantCode codes.Code) {
+	t.Helper()
+	ctx, err := a.authenticate(metadata.NewIncomingContext(context.Background(), md))
+	if got := status.Code(err); got != wantCode {
+		t.Fatalf("got code %v (%v), want %v", got, err, wantCode)
+	}
+	if err != nil {
+		return
+	}
+	id, _ := IdentityFrom(ctx)
+	if !reflect.DeepEqual(id, want) {
+		t.Errorf("got identity %+v, want %+v", id, want)
+	}
+}
+
+// newTestJWKS returns a new RSA key and the path of a JWKS file holding its
+// public key.
+func newTestJWKS(t *testing.T) (*rsa.PrivateKey, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+		Key:       key.Public(),
+		KeyID:     "key-1",
+		Algorithm: string(jose.RS256),
+		Use:       "sig",
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return key, path
+}
+
+func signJWT(t *testing.T, key *rsa.PrivateKey, claims map[string]interface{}) string {
+	t.Helper()
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key}, (&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "key-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jws, err := signer.Sign(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jws.CompactSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// newTestCert returns a self-signed client certificate of alice, in the
+// admins group.
+func newTestCert(t *testing.T) *x509.Certificate {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "alice", Organization: []string{"admins"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
@@ -0,0 +1,90 @@
+package authn
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// Metadata set by the gateway on the calls it forwards. Callers can't set
+// them, see GatewayHeaderMatcher.
+const (
+	gatewaySecretKey     = "x-maestro-gateway-secret"
+	gatewayClientCertKey = "x-maestro-client-certificate" // base64 of the DER encoding
+)
+
+// clientCertAuthenticator authenticates verified client certificates,
+// presented to the gRPC server or to the gateway.
+type clientCertAuthenticator struct {
+	gatewaySecret string
+}
+
+func (a *clientCertAuthenticator) Authenticate(ctx context.Context) (*Identity, error) {
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.VerifiedChains) > 0 {
+			return certIdentity(tlsInfo.State.VerifiedChains[0][0]), nil
+		}
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+	certs := md.Get(gatewayClientCertKey)
+	if len(certs) == 0 {
+		return nil, nil
+	}
+	secrets := md.Get(gatewaySecretKey)
+	if len(certs) != 1 || len(secrets) != 1 || subtle.ConstantTimeCompare([]byte(secrets[0]), []byte(a.gatewaySecret)) != 1 {
+		return nil, errors.New("client certificate not forwarded by the gateway")
+	}
+
+	der, err := base64.StdEncoding.DecodeString(certs[0])
+	if err != nil {
+		return nil, errors.New("invalid forwarded client certificate")
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return certIdentity(cert), nil
+}
+
+// certIdentity names the caller after the CN of its certificate, in the
+// groups of its O, like Kubernetes does.
+func certIdentity(cert *x509.Certificate) *Identity {
+	return &Identity{Name: cert.Subject.CommonName, Groups: cert.Subject.Organization}
+}
+
+// GatewayMetadata forwards the verified client certificate of an HTTP
+// request to the gRPC server, along with the secret proving it comes from
+// the gateway. Use it with runtime.WithMetadata.
+func (a *Authn) GatewayMetadata(_ context.Context, req *http.Request) metadata.MD {
+	if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 {
+		return nil
+	}
+
+	return metadata.Pairs(
+		gatewaySecretKey, a.gatewaySecret,
+		gatewayClientCertKey, base64.StdEncoding.EncodeToString(req.TLS.VerifiedChains[0][0].Raw),
+	)
+}
+
+// GatewayHeaderMatcher forwards HTTP headers like runtime.DefaultHeaderMatcher,
+// except the ones that would set the metadata reserved to the gateway. Use
+// it with runtime.WithIncomingHeaderMatcher.
+func GatewayHeaderMatcher(key string) (string, bool) {
+	if strings.HasPrefix(strings.ToLower(key), strings.ToLower(runtime.MetadataHeaderPrefix)+"x-maestro-") {
+		return "", false
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
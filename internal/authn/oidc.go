@@ -0,0 +1,158 @@
+package authn
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/go-jose/go-jose/v3"
+)
+
+const (
+	authnOIDCIssuerURL     = "AUTHN_OIDC_ISSUER_URL"
+	authnOIDCClientID      = "AUTHN_OIDC_CLIENT_ID"
+	authnOIDCJWKSFile      = "AUTHN_OIDC_JWKS_FILE"
+	authnOIDCUsernameClaim = "AUTHN_OIDC_USERNAME_CLAIM"
+	authnOIDCGroupsClaim   = "AUTHN_OIDC_GROUPS_CLAIM"
+)
+
+// oidcAuthenticator authenticates OIDC ID tokens and other JWTs.
+type oidcAuthenticator struct {
+	verifier      *oidc.IDTokenVerifier
+	usernameClaim string
+	groupsClaim   string
+}
+
+// newOIDCAuthenticator returns an authenticator of the JWT bearer tokens
+// issued by AUTHN_OIDC_ISSUER_URL for AUTHN_OIDC_CLIENT_ID, nil when no
+// issuer is set. Tokens are verified with the keys of AUTHN_OIDC_JWKS_FILE,
+// or the keys discovered from the issuer. The name and groups of the
+// caller are read from the AUTHN_OIDC_USERNAME_CLAIM ("sub" by default)
+// and AUTHN_OIDC_GROUPS_CLAIM ("groups" by default) claims.
+func newOIDCAuthenticator() (*oidcAuthenticator, error) {
+	issuer := os.Getenv(authnOIDCIssuerURL)
+	if issuer == "" {
+		return nil, nil
+	}
+	clientID := os.Getenv(authnOIDCClientID)
+	if clientID == "" {
+		return nil, fmt.Errorf("%s requires %s", authnOIDCIssuerURL, authnOIDCClientID)
+	}
+	config := &oidc.Config{ClientID: clientID}
+
+	a := &oidcAuthenticator{
+		usernameClaim: os.Getenv(authnOIDCUsernameClaim),
+		groupsClaim:   os.Getenv(authnOIDCGroupsClaim),
+	}
+	if a.usernameClaim == "" {
+		a.usernameClaim = "sub"
+	}
+	if a.groupsClaim == "" {
+		a.groupsClaim = "groups"
+	}
+
+	if jwksFile := os.Getenv(authnOIDCJWKSFile); jwksFile != "" {
+		keySet, algorithms, err := readKeySet(jwksFile)
+		if err != nil {
+			return nil, err
+		}
+		config.SupportedSigningAlgs = algorithms
+		a.verifier = oidc.NewVerifier(issuer, keySet, config)
+		return a, nil
+	}
+
+	provider, err := oidc.NewProvider(context.Background(), issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC issuer %s: %w", issuer, err)
+	}
+	a.verifier = provider.Verifier(config)
+	return a, nil
+}
+
+// readKeySet returns the signing keys of a JWKS file, and the algorithms
+// they declare. Keys without "alg" accept any asymmetric algorithm.
+func readKeySet(path string) (*oidc.StaticKeySet, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var jwks jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, nil, fmt.Errorf("failed to read JWKS %s: %w", path, err)
+	}
+
+	keySet := &oidc.StaticKeySet{}
+	algorithms := map[string]bool{}
+	for _, key := range jwks.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		keySet.PublicKeys = append(keySet.PublicKeys, key.Key)
+
+		if key.Algorithm != "" {
+			algorithms[key.Algorithm] = true
+			continue
+		}
+		for _, alg := range asymmetricAlgorithms {
+			algorithms[alg] = true
+		}
+	}
+	if len(keySet.PublicKeys) == 0 {
+		return nil, nil, fmt.Errorf("no signing key found in JWKS %s", path)
+	}
+
+	var supported []string
+	for _, alg := range asymmetricAlgorithms {
+		if algorithms[alg] {
+			supported = append(supported, alg)
+		}
+	}
+	return keySet, supported, nil
+}
+
+var asymmetricAlgorithms = []string{
+	oidc.RS256, oidc.RS384, oidc.RS512,
+	oidc.ES256, oidc.ES384, oidc.ES512,
+	oidc.PS256, oidc.PS384, oidc.PS512,
+	oidc.EdDSA,
+}
+
+func (a *oidcAuthenticator) Authenticate(ctx context.Context) (*Identity, error) {
+	token, ok := bearerToken(ctx)
+	// tokens that aren't JWTs are left to the other authenticators
+	if !ok || strings.Count(token, ".") != 2 {
+		return nil, nil
+	}
+
+	idToken, err := a.verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	name, _ := claims[a.usernameClaim].(string)
+	if name == "" {
+		return nil, fmt.Errorf("the token has no %q claim", a.usernameClaim)
+	}
+	id := &Identity{Name: name}
+
+	switch groups := claims[a.groupsClaim].(type) {
+	case string:
+		id.Groups = []string{groups}
+	case []interface{}:
+		for _, g := range groups {
+			if g, ok := g.(string); ok {
+				id.Groups = append(id.Groups, g)
+			}
+		}
+	}
+	return id, nil
+}
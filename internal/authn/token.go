@@ -0,0 +1,59 @@
+package authn
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// tokenAuthenticator authenticates static bearer tokens.
+type tokenAuthenticator struct {
+	// identities by the sha256 of their token
+	identities map[[sha256.Size]byte]*Identity
+}
+
+// newTokenAuthenticator reads the tokens of a CSV file in the format of
+// the Kubernetes static token file: token,user,uid,"group1,group2". The
+// uid is ignored and the groups are optional.
+func newTokenAuthenticator(path string) (*tokenAuthenticator, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	r.Comment = '#'
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	a := &tokenAuthenticator{identities: map[[sha256.Size]byte]*Identity{}}
+	for i, record := range records {
+		if len(record) < 2 || record[0] == "" || record[1] == "" {
+			return nil, fmt.Errorf("%s: line %d: a token and a user are required", path, i+1)
+		}
+
+		id := &Identity{Name: record[1]}
+		if len(record) > 3 && record[3] != "" {
+			id.Groups = strings.Split(record[3], ",")
+		}
+		a.identities[sha256.Sum256([]byte(record[0]))] = id
+	}
+	return a, nil
+}
+
+func (a *tokenAuthenticator) Authenticate(ctx context.Context) (*Identity, error) {
+	token, ok := bearerToken(ctx)
+	if !ok {
+		return nil, nil
+	}
+	// unknown tokens are left to the other authenticators
+	return a.identities[sha256.Sum256([]byte(token))], nil
+}
@@ -0,0 +1,316 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/kube-orchestra/maestro/internal/audit"
+	"github.com/kube-orchestra/maestro/internal/authn"
+	"github.com/kube-orchestra/maestro/internal/db"
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const authzPolicyFile = "AUTHZ_POLICY_FILE"
+
+// ErrorPermissionDenied is returned when the policy doesn't allow the
+// caller to apply the verb to the resource.
+type ErrorPermissionDenied struct {
+	Principal string
+	Verb      string
+	Resource  string
+}
+
+func (e *ErrorPermissionDenied) Error() string {
+	return fmt.Sprintf("%s cannot %s %s", e.Principal, e.Verb, e.Resource)
+}
+
+func (e *ErrorPermissionDenied) GRPCStatus() *status.Status {
+	return status.New(codes.PermissionDenied, e.Error())
+}
+
+// check describes how a method is authorized.
+type check struct {
+	verb     string
+	resource string
+	// consumerLabels returns the label sets the consumer of the target
+	// must match, nil when the target has no consumer or its consumer
+	// doesn't exist in tenant.
+	consumerLabels func(tenant string, req interface{}) ([]labels.Set, error)
+}
+
+var checks = map[string]check{
+	"/v1.ConsumerService/Read": {VerbRead, ResourceConsumers, func(tenant string, req interface{}) ([]labels.Set, error) {
+		return storedConsumerLabels(tenant, req.(*v1.ConsumerReadRequest).Id)
+	}},
+	"/v1.ConsumerService/Create": {VerbCreate, ResourceConsumers, func(_ string, req interface{}) ([]labels.Set, error) {
+		return []labels.Set{labelSet(req.(*v1.ConsumerCreateRequest).Labels)}, nil
+	}},
+	"/v1.ConsumerService/Update": {VerbUpdate, ResourceConsumers, func(tenant string, req interface{}) ([]labels.Set, error) {
+		r := req.(*v1.ConsumerUpdateRequest)
+		return withStoredConsumerLabels(tenant, r.Id, labelSet(r.Labels))
+	}},
+	"/v1.ConsumerService/Patch": {VerbUpdate, ResourceConsumers, func(tenant string, req interface{}) ([]labels.Set, error) {
+		r := req.(*v1.ConsumerPatchRequest)
+		c, err := db.GetConsumer(tenant, r.Id)
+		if err != nil {
+			return nil, ignoreNotFound(err)
+		}
+		patched := labelSet(c.Labels)
+		for _, l := range r.AddLabels {
+			patched[l.Key] = l.Value
+		}
+		for _, key := range r.RemoveLabels {
+			delete(patched, key)
+		}
+		return []labels.Set{labelSet(c.Labels), patched}, nil
+	}},
+
+	"/v1.ResourceService/Read": {VerbRead, ResourceResources, func(tenant string, req interface{}) ([]labels.Set, error) {
+		return resourceConsumerLabels(tenant, req.(*v1.ResourceReadRequest).Id)
+	}},
+	"/v1.ResourceService/Lookup": {VerbRead, ResourceResources, func(tenant string, req interface{}) ([]labels.Set, error) {
+		return storedConsumerLabels(tenant, req.(*v1.ResourceLookupRequest).ConsumerId)
+	}},
+	"/v1.ResourceService/History": {VerbRead, ResourceResources, func(tenant string, req interface{}) ([]labels.Set, error) {
+		return resourceConsumerLabels(tenant, req.(*v1.ResourceHistoryRequest).Id)
+	}},
+	"/v1.ResourceService/Usage": {VerbRead, ResourceResources, func(tenant string, req interface{}) ([]labels.Set, error) {
+		return storedConsumerLabels(tenant, req.(*v1.ResourceUsageRequest).ConsumerId)
+	}},
+	"/v1.ResourceService/Create": {VerbCreate, ResourceResources, func(tenant string, req interface{}) ([]labels.Set, error) {
+		return storedConsumerLabels(tenant, req.(*v1.ResourceCreateRequest).ConsumerId)
+	}},
+	"/v1.ResourceService/Update": {VerbUpdate, ResourceResources, func(tenant string, req interface{}) ([]labels.Set, error) {
+		return resourceConsumerLabels(tenant, req.(*v1.ResourceUpdateRequest).Id)
+	}},
+	"/v1.ResourceService/Patch": {VerbUpdate, ResourceResources, func(tenant string, req interface{}) ([]labels.Set, error) {
+		return resourceConsumerLabels(tenant, req.(*v1.ResourcePatchRequest).Id)
+	}},
+	"/v1.ResourceService/Delete": {VerbDelete, ResourceResources, func(tenant string, req interface{}) ([]labels.Set, error) {
+		return resourceConsumerLabels(tenant, req.(*v1.ResourceDeleteRequest).Id)
+	}},
+	"/v1.ResourceService/SetDependencies": {VerbUpdate, ResourceResources, func(tenant string, req interface{}) ([]labels.Set, error) {
+		return resourceConsumerLabels(tenant, req.(*v1.ResourceDependenciesRequest).Id)
+	}},
+	"/v1.ResourceService/SetFeedbackRules": {VerbUpdate, ResourceResources, func(tenant string, req interface{}) ([]labels.Set, error) {
+		return resourceConsumerLabels(tenant, req.(*v1.ResourceFeedbackRulesRequest).Id)
+	}},
+
+	"/v1.ResourceBundleService/Read": {VerbRead, ResourceResourceBundles, func(tenant string, req interface{}) ([]labels.Set, error) {
+		return bundleConsumerLabels(tenant, req.(*v1.ResourceBundleReadRequest).Id)
+	}},
+	"/v1.ResourceBundleService/Create": {VerbCreate, ResourceResourceBundles, func(tenant string, req interface{}) ([]labels.Set, error) {
+		return storedConsumerLabels(tenant, req.(*v1.ResourceBundleCreateRequest).ConsumerId)
+	}},
+	"/v1.ResourceBundleService/Update": {VerbUpdate, ResourceResourceBundles, func(tenant string, req interface{}) ([]labels.Set, error) {
+		return bundleConsumerLabels(tenant, req.(*v1.ResourceBundleUpdateRequest).Id)
+	}},
+	"/v1.ResourceBundleService/Delete": {VerbDelete, ResourceResourceBundles, func(tenant string, req interface{}) ([]labels.Set, error) {
+		return bundleConsumerLabels(tenant, req.(*v1.ResourceBundleDeleteRequest).Id)
+	}},
+
+	"/v1.TenantService/Read":   {VerbRead, ResourceTenants, noConsumer},
+	"/v1.TenantService/Create": {VerbCreate, ResourceTenants, noConsumer},
+	"/v1.TenantService/Update": {VerbUpdate, ResourceTenants, noConsumer},
+
+	"/v1.PolicyService/Read":   {VerbRead, ResourcePolicies, noConsumer},
+	"/v1.PolicyService/List":   {VerbRead, ResourcePolicies, noConsumer},
+	"/v1.PolicyService/Create": {VerbCreate, ResourcePolicies, noConsumer},
+	"/v1.PolicyService/Update": {VerbUpdate, ResourcePolicies, noConsumer},
+	"/v1.PolicyService/Delete": {VerbDelete, ResourcePolicies, noConsumer},
+
+	"/v1.PlacementService/Read":   {VerbRead, ResourcePlacements, noConsumer},
+	"/v1.PlacementService/Create": {VerbCreate, ResourcePlacements, noConsumer},
+	"/v1.PlacementService/Update": {VerbUpdate, ResourcePlacements, noConsumer},
+	"/v1.PlacementService/Resume": {VerbUpdate, ResourcePlacements, noConsumer},
+	"/v1.PlacementService/Delete": {VerbDelete, ResourcePlacements, noConsumer},
+
+	"/v1.AuditService/Query": {VerbRead, ResourceAuditEvents, noConsumer},
+
+	"/v1.SigningKeyService/List": {VerbRead, ResourceSigningKeys, noConsumer},
+
+	"/v1.DeadLetterService/Read":   {VerbRead, ResourceDeadLetters, noConsumer},
+	"/v1.DeadLetterService/List":   {VerbRead, ResourceDeadLetters, noConsumer},
+	"/v1.DeadLetterService/Replay": {VerbUpdate, ResourceDeadLetters, noConsumer},
+	"/v1.DeadLetterService/Delete": {VerbDelete, ResourceDeadLetters, noConsumer},
+}
+
+func noConsumer(string, interface{}) ([]labels.Set, error) {
+	return nil, nil
+}
+
+// consumerSelectors returns the selectors of the consumers the target of a
+// method places resources on, by method. Their consumers aren't known
+// ahead, so each selector must be restricted to the selector of the
+// binding. Nil when the target doesn't exist in tenant.
+var consumerSelectors = map[string]func(tenant string, req interface{}) ([]labels.Selector, error){
+	"/v1.PlacementService/Read": func(tenant string, req interface{}) ([]labels.Selector, error) {
+		return placementSelectors(tenant, req.(*v1.PlacementReadRequest).Id)
+	},
+	"/v1.PlacementService/Create": func(_ string, req interface{}) ([]labels.Selector, error) {
+		return []labels.Selector{parseSelector(req.(*v1.PlacementCreateRequest).ConsumerSelector)}, nil
+	},
+	"/v1.PlacementService/Update": func(tenant string, req interface{}) ([]labels.Selector, error) {
+		r := req.(*v1.PlacementUpdateRequest)
+		selectors, err := placementSelectors(tenant, r.Id)
+		if selectors == nil {
+			return nil, err
+		}
+		return append(selectors, parseSelector(r.ConsumerSelector)), nil
+	},
+	"/v1.PlacementService/Resume": func(tenant string, req interface{}) ([]labels.Selector, error) {
+		return placementSelectors(tenant, req.(*v1.PlacementResumeRequest).Id)
+	},
+	"/v1.PlacementService/Delete": func(tenant string, req interface{}) ([]labels.Selector, error) {
+		return placementSelectors(tenant, req.(*v1.PlacementDeleteRequest).Id)
+	},
+}
+
+// Authz authorizes the calls of the gRPC server against a policy.
+type Authz struct {
+	policy *Policy
+}
+
+// New loads the policy file AUTHZ_POLICY_FILE. Without policy, every call
+// is allowed.
+func New() (*Authz, error) {
+	path := os.Getenv(authzPolicyFile)
+	if path == "" {
+		return &Authz{}, nil
+	}
+
+	policy, err := LoadPolicy(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Authz{policy: policy}, nil
+}
+
+// UnaryServerInterceptor rejects the calls the policy doesn't allow with
+// ErrorPermissionDenied. It must run after authentication.
+func (a *Authz) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if a.policy == nil {
+			return handler(ctx, req)
+		}
+
+		name := audit.Principal(ctx)
+		var groups []string
+		if id, ok := authn.IdentityFrom(ctx); ok {
+			groups = id.Groups
+		}
+
+		c, ok := checks[info.FullMethod]
+		if !ok {
+			return nil, &ErrorPermissionDenied{Principal: name, Verb: "call", Resource: info.FullMethod}
+		}
+
+		tenant := tenantOf(req)
+		consumerLabels, err := c.consumerLabels(tenant, req)
+		if err != nil {
+			return nil, err
+		}
+		var selectors []labels.Selector
+		if selectorsOf, ok := consumerSelectors[info.FullMethod]; ok {
+			selectors, err = selectorsOf(tenant, req)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !a.policy.Allowed(name, groups, c.verb, c.resource, tenant, consumerLabels, selectors) {
+			return nil, &ErrorPermissionDenied{Principal: name, Verb: c.verb, Resource: c.resource}
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// tenantOf returns the tenant the request targets: the tenant of tenants
+// requests, the requested tenant or DefaultTenant for the objects of a
+// tenant, and the optional tenant filter of audit queries.
+func tenantOf(req interface{}) string {
+	switch r := req.(type) {
+	case *v1.TenantReadRequest, *v1.TenantCreateRequest, *v1.TenantUpdateRequest:
+		return r.(interface{ GetId() string }).GetId()
+	case *v1.AuditQueryRequest:
+		return r.TenantId
+	case interface{ GetTenantId() string }:
+		return db.TenantOrDefault(r.GetTenantId())
+	}
+	return ""
+}
+
+func storedConsumerLabels(tenant, consumerID string) ([]labels.Set, error) {
+	return withStoredConsumerLabels(tenant, consumerID)
+}
+
+// withStoredConsumerLabels returns the labels of the consumer followed by
+// sets, nil if the consumer doesn't exist.
+func withStoredConsumerLabels(tenant, consumerID string, sets ...labels.Set) ([]labels.Set, error) {
+	c, err := db.GetConsumer(tenant, consumerID)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+	return append([]labels.Set{labelSet(c.Labels)}, sets...), nil
+}
+
+func resourceConsumerLabels(tenant, resourceID string) ([]labels.Set, error) {
+	res, err := db.GetResource(tenant, resourceID)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+	return storedConsumerLabels(tenant, res.ConsumerId)
+}
+
+func bundleConsumerLabels(tenant, bundleID string) ([]labels.Set, error) {
+	b, err := db.GetResourceBundle(tenant, bundleID)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+	return storedConsumerLabels(tenant, b.ConsumerId)
+}
+
+// placementSelectors returns the selector of a stored placement, nil if
+// the placement doesn't exist.
+func placementSelectors(tenant, placementID string) ([]labels.Selector, error) {
+	p, err := db.GetPlacement(tenant, placementID)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+	return []labels.Selector{parseSelector(p.ConsumerSelector)}, nil
+}
+
+// parseSelector parses a consumer selector. Invalid selectors select
+// nothing: they're only allowed to the callers allowed on every consumer,
+// and rejected by the call.
+func parseSelector(selector string) labels.Selector {
+	s, err := labels.Parse(selector)
+	if err != nil {
+		return labels.Nothing()
+	}
+	return s
+}
+
+func labelSet(consumerLabels []*v1.ConsumerLabel) labels.Set {
+	set := labels.Set{}
+	for _, l := range consumerLabels {
+		set[l.Key] = l.Value
+	}
+	return set
+}
+
+// ignoreNotFound drops ErrorNotFound, unknown targets are only visible to
+// the callers allowed on every consumer.
+func ignoreNotFound(err error) error {
+	var notFound *db.ErrorNotFound
+	if errors.As(err, &notFound) {
+		return nil
+	}
+	return err
+}
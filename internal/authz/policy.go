@@ -0,0 +1,201 @@
+package authz
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/labels"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Verbs of the rules.
+const (
+	VerbCreate = "create"
+	VerbRead   = "read"
+	VerbUpdate = "update"
+	VerbDelete = "delete"
+)
+
+// Resources of the rules.
+const (
+	ResourceConsumers       = "consumers"
+	ResourceResources       = "resources"
+	ResourceResourceBundles = "resourcebundles"
+	ResourceAuditEvents     = "auditevents"
+	ResourceTenants         = "tenants"
+	ResourcePolicies        = "policies"
+	ResourcePlacements      = "placements"
+	ResourceSigningKeys     = "signingkeys"
+	ResourceDeadLetters     = "deadletters"
+)
+
+// wildcard matches every verb or resource.
+const wildcard = "*"
+
+// Policy binds subjects and groups to roles, optionally restricted to
+// tenants and to the consumers matching a label selector.
+type Policy struct {
+	Roles    []Role    `json:"roles"`
+	Bindings []Binding `json:"bindings"`
+}
+
+type Role struct {
+	Name  string `json:"name"`
+	Rules []Rule `json:"rules"`
+}
+
+// Rule allows verbs on resources, "*" matching any of them.
+type Rule struct {
+	Resources []string `json:"resources"`
+	Verbs     []string `json:"verbs"`
+}
+
+type Binding struct {
+	Role string `json:"role"`
+	// user names, "anonymous" for unauthenticated callers.
+	Subjects []string `json:"subjects,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+	// tenants the role applies to. Empty applies the role to every tenant
+	// and to the resources that don't belong to a tenant.
+	Tenants []string `json:"tenants,omitempty"`
+	// label selector of the consumers the role applies to, e.g. "team=a".
+	// Empty applies the role to every consumer and to the resources that
+	// don't belong to a consumer, e.g. audit events.
+	ConsumerSelector string `json:"consumerSelector,omitempty"`
+
+	role     *Role
+	selector labels.Selector
+}
+
+// LoadPolicy reads a YAML or JSON policy file.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Policy{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	if err := decoder.Decode(p); err != nil {
+		return nil, fmt.Errorf("failed to read policy %s: %w", path, err)
+	}
+
+	roles := make(map[string]*Role, len(p.Roles))
+	for i := range p.Roles {
+		roles[p.Roles[i].Name] = &p.Roles[i]
+	}
+	for i := range p.Bindings {
+		b := &p.Bindings[i]
+		b.role = roles[b.Role]
+		if b.role == nil {
+			return nil, fmt.Errorf("policy %s: bindings[%d]: unknown role %q", path, i, b.Role)
+		}
+		if b.ConsumerSelector != "" {
+			b.selector, err = labels.Parse(b.ConsumerSelector)
+			if err != nil {
+				return nil, fmt.Errorf("policy %s: bindings[%d]: invalid consumerSelector: %w", path, i, err)
+			}
+		}
+	}
+	return p, nil
+}
+
+// Allowed tells whether the caller, named name in groups, may apply verb
+// to resource in tenant, empty for resources that don't belong to a
+// tenant. consumerLabels holds the label sets the consumer of the target
+// must match, e.g. its labels before and after an update, and
+// consumerSelectors the selectors of the consumers a target placing
+// resources on many consumers selects, e.g. a placement. Both are nil for
+// resources that don't belong to a consumer.
+func (p *Policy) Allowed(name string, groups []string, verb, resource, tenant string, consumerLabels []labels.Set, consumerSelectors []labels.Selector) bool {
+	for i := range p.Bindings {
+		b := &p.Bindings[i]
+		if !b.binds(name, groups) || !b.role.allows(verb, resource) {
+			continue
+		}
+		if len(b.Tenants) > 0 && !contains(b.Tenants, tenant) {
+			continue
+		}
+		if b.selector == nil {
+			return true
+		}
+		if consumerLabels == nil && consumerSelectors == nil {
+			continue
+		}
+
+		matches := true
+		for _, set := range consumerLabels {
+			if !b.selector.Matches(set) {
+				matches = false
+				break
+			}
+		}
+		for _, selector := range consumerSelectors {
+			if !within(selector, b.selector) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// within tells whether the consumers matching selector all match scope: it
+// holds every requirement of scope. Selectors equivalent to scope in other
+// terms, e.g. "team in (a)" for "team=a", aren't recognized.
+func within(selector, scope labels.Selector) bool {
+	held, selectable := selector.Requirements()
+	if !selectable {
+		// invalid selectors, see parseSelector
+		return false
+	}
+	required, _ := scope.Requirements()
+	for _, r := range required {
+		found := false
+		for _, h := range held {
+			if h.Equal(r) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *Binding) binds(name string, groups []string) bool {
+	if contains(b.Subjects, name) {
+		return true
+	}
+	for _, g := range groups {
+		if contains(b.Groups, g) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Role) allows(verb, resource string) bool {
+	for _, rule := range r.Rules {
+		if (contains(rule.Resources, resource) || contains(rule.Resources, wildcard)) &&
+			(contains(rule.Verbs, verb) || contains(rule.Verbs, wildcard)) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package authz
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const testPolicy = `
+roles:
+  - name: admin
+    rules:
+      - resources: ["*"]
+        verbs: ["*"]
+  - name: operator
+    rules:
+      - resources: [resources, placements]
+        verbs: [create, read, update, delete]
+      - resources: [consumers]
+        verbs: [read]
+  - name: auditor
+    rules:
+      - resources: [auditevents]
+        verbs: [read]
+bindings:
+  - role: admin
+    subjects: [root]
+  - role: admin
+    groups: [admins]
+    tenants: [team-a]
+  - role: operator
+    groups: [team-a]
+    tenants: [team-a]
+    consumerSelector: team=a
+  - role: operator
+    subjects: [carol]
+    consumerSelector: "team=a,env!=prod"
+  - role: auditor
+    groups: [auditors]
+`
+
+func TestAllowed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(testPolicy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := LoadPolicy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	teamA := labels.Set{"team": "a"}
+	teamB := labels.Set{"team": "b"}
+
+	tests := []struct {
+		name      string
+		subject   string
+		groups    []string
+		verb      string
+		resource  string
+		tenant    string
+		labels    []labels.Set
+		selectors []string
+		want      bool
+	}{
+		{name: "unscoped binding", subject: "root", verb: VerbDelete, resource: ResourceTenants, want: true},
+		{name: "unscoped binding on a consumer", subject: "root", verb: VerbUpdate, resource: ResourceResources, tenant: "team-b", labels: []labels.Set{teamB}, want: true},
+		{name: "no binding", subject: "mallory", verb: VerbRead, resource: ResourceResources, tenant: "team-a", labels: []labels.Set{teamA}},
+		{name: "group of another role", subject: "dave", groups: []string{"auditors"}, verb: VerbRead, resource: ResourceResources, tenant: "team-a", labels: []labels.Set{teamA}},
+		{name: "group without tenants", subject: "dave", groups: []string{"auditors"}, verb: VerbRead, resource: ResourceAuditEvents, want: true},
+		{name: "verb not allowed", subject: "dave", groups: []string{"auditors"}, verb: VerbDelete, resource: ResourceAuditEvents},
+
+		{name: "tenant", subject: "alice", groups: []string{"admins"}, verb: VerbCreate, resource: ResourcePolicies, tenant: "team-a", want: true},
+		{name: "other tenant", subject: "alice", groups: []string{"admins"}, verb: VerbCreate, resource: ResourcePolicies, tenant: "team-b"},
+		{name: "no tenant", subject: "alice", groups: []string{"admins"}, verb: VerbRead, resource: ResourceAuditEvents},
+
+		{name: "matching consumer", subject: "bob", groups: []string{"team-a"}, verb: VerbUpdate, resource: ResourceResources, tenant: "team-a", labels: []labels.Set{teamA}, want: true},
+		{name: "other consumer", subject: "bob", groups: []string{"team-a"}, verb: VerbUpdate, resource: ResourceResources, tenant: "team-a", labels: []labels.Set{teamB}},
+		{name: "consumer of another tenant", subject: "bob", groups: []string{"team-a"}, verb: VerbUpdate, resource: ResourceResources, tenant: "team-b", labels: []labels.Set{teamA}},
+		{name: "consumer relabeled out of the selector", subject: "bob", groups: []string{"team-a"}, verb: VerbRead, resource: ResourceConsumers, tenant: "team-a", labels: []labels.Set{teamA, teamB}},
+		{name: "unknown consumer", subject: "bob", groups: []string{"team-a"}, verb: VerbRead, resource: ResourceResources, tenant: "team-a"},
+		{name: "several requirements", subject: "carol", verb: VerbRead, resource: ResourceResources, tenant: "team-b", labels: []labels.Set{{"team": "a", "env": "dev"}}, want: true},
+		{name: "several requirements, one unmet", subject: "carol", verb: VerbRead, resource: ResourceResources, tenant: "team-b", labels: []labels.Set{{"team": "a", "env": "prod"}}},
+
+		{name: "placement within the selector", subject: "bob", groups: []string{"team-a"}, verb: VerbCreate, resource: ResourcePlacements, tenant: "team-a", selectors: []string{"team=a"}, want: true},
+		{name: "placement narrower than the selector", subject: "bob", groups: []string{"team-a"}, verb: VerbCreate, resource: ResourcePlacements, tenant: "team-a", selectors: []string{"env=prod,team=a"}, want: true},
+		{name: "placement of every consumer", subject: "bob", groups: []string{"team-a"}, verb: VerbCreate, resource: ResourcePlacements, tenant: "team-a", selectors: []string{""}},
+		{name: "placement of other consumers", subject: "bob", groups: []string{"team-a"}, verb: VerbCreate, resource: ResourcePlacements, tenant: "team-a", selectors: []string{"team=b"}},
+		{name: "placement with another operator", subject: "bob", groups: []string{"team-a"}, verb: VerbCreate, resource: ResourcePlacements, tenant: "team-a", selectors: []string{"team!=b"}},
+		{name: "placement updated out of the selector", subject: "bob", groups: []string{"team-a"}, verb: VerbUpdate, resource: ResourcePlacements, tenant: "team-a", selectors: []string{"team=a", "env=prod"}},
+		{name: "placement with an invalid selector", subject: "bob", groups: []string{"team-a"}, verb: VerbCreate, resource: ResourcePlacements, tenant: "team-a", selectors: []string{"team in ("}},
+		{name: "placement missing a requirement", subject: "carol", verb: VerbCreate, resource: ResourcePlacements, tenant: "team-b", selectors: []string{"team=a"}},
+		{name: "placement with every requirement", subject: "carol", verb: VerbCreate, resource: ResourcePlacements, tenant: "team-b", selectors: []string{"env!=prod,region=eu,team=a"}, want: true},
+		{name: "unknown placement", subject: "bob", groups: []string{"team-a"}, verb: VerbRead, resource: ResourcePlacements, tenant: "team-a"},
+		{name: "placement of an unscoped binding", subject: "alice", groups: []string{"admins"}, verb: VerbCreate, resource: ResourcePlacements, tenant: "team-a", selectors: []string{""}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var selectors []labels.Selector
+			for _, s := range tt.selectors {
+				selectors = append(selectors, parseSelector(s))
+			}
+			got := p.Allowed(tt.subject, tt.groups, tt.verb, tt.resource, tt.tenant, tt.labels, selectors)
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPolicyInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		wantErr string
+	}{
+		{
+			name:    "unknown role",
+			policy:  `{"bindings": [{"role": "admin", "subjects": ["root"]}]}`,
+			wantErr: `unknown role "admin"`,
+		},
+		{
+			name:    "invalid selector",
+			policy:  `{"roles": [{"name": "admin"}], "bindings": [{"role": "admin", "subjects": ["root"], "consumerSelector": "team in ("}]}`,
+			wantErr: "invalid consumerSelector",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policy.json")
+			if err := os.WriteFile(path, []byte(tt.policy), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadPolicy(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
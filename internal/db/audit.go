@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const AuditEventTable = "AuditEvents"
+
+// Indexes of AuditEventTable, sorted by Timestamp.
+const (
+	auditTargetIndex    = "TargetIndex"
+	auditPrincipalIndex = "PrincipalIndex"
+)
+
+// AuditEvent records a mutating API call.
+type AuditEvent struct {
+	Id string `json:"id"`
+	// Unix timestamp in nanoseconds at which the call completed.
+	Timestamp     int64    `json:"timestamp"`
+	Principal     string   `json:"principal"`
+	Method        string   `json:"method"`
+	TargetKind    string   `json:"targetKind"`
+	TargetId      string   `json:"targetId,omitempty" dynamodbav:",omitempty"`
+	OldGeneration int64    `json:"oldGeneration,omitempty"`
+	NewGeneration int64    `json:"newGeneration,omitempty"`
+	Changes       []string `json:"changes,omitempty"`
+	Code          string   `json:"code"`
+	// Tenant of the target, empty for calls outside of tenants.
+	TenantId string `json:"tenantId,omitempty"`
+}
+
+// AuditFilter selects audit events. Either TargetId or Principal must be
+// set, empty fields match any value.
+type AuditFilter struct {
+	TenantId   string
+	TargetKind string
+	TargetId   string
+	Principal  string
+	Limit      int
+}
+
+func PutAuditEvent(e *AuditEvent) error {
+	item, err := attributevalue.MarshalMap(e)
+	if err != nil {
+		return err
+	}
+
+	_, err = dbClient.PutItem(context.TODO(), &dynamodb.PutItemInput{
+		TableName: aws.String(AuditEventTable),
+		Item:      item,
+	})
+	return storeError(err)
+}
+
+// QueryAuditEvents returns up to filter.Limit events matching filter,
+// newest first.
+func QueryAuditEvents(filter AuditFilter) ([]AuditEvent, error) {
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(AuditEventTable),
+		ExpressionAttributeValues: map[string]types.AttributeValue{},
+		ScanIndexForward:          aws.Bool(false),
+	}
+
+	var filters []string
+	match := func(attribute, value string) string {
+		placeholder := ":" + strings.ToLower(attribute)
+		input.ExpressionAttributeValues[placeholder] = &types.AttributeValueMemberS{Value: value}
+		return attribute + " = " + placeholder
+	}
+
+	if filter.TargetId != "" {
+		input.IndexName = aws.String(auditTargetIndex)
+		input.KeyConditionExpression = aws.String(match("TargetId", filter.TargetId))
+		if filter.Principal != "" {
+			filters = append(filters, match("Principal", filter.Principal))
+		}
+	} else {
+		input.IndexName = aws.String(auditPrincipalIndex)
+		input.KeyConditionExpression = aws.String(match("Principal", filter.Principal))
+	}
+	if filter.TargetKind != "" {
+		filters = append(filters, match("TargetKind", filter.TargetKind))
+	}
+	if filter.TenantId != "" {
+		filters = append(filters, match("TenantId", filter.TenantId))
+	}
+	if len(filters) > 0 {
+		input.FilterExpression = aws.String(strings.Join(filters, " AND "))
+	}
+
+	events := []AuditEvent{}
+	paginator := dynamodb.NewQueryPaginator(dbClient, input)
+	for paginator.HasMorePages() && len(events) < filter.Limit {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, storeError(err)
+		}
+
+		var pageEvents []AuditEvent
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &pageEvents); err != nil {
+			return nil, err
+		}
+		events = append(events, pageEvents...)
+	}
+
+	if len(events) > filter.Limit {
+		events = events[:filter.Limit]
+	}
+	return events, nil
+}
@@ -2,6 +2,10 @@ package db
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
@@ -11,41 +15,446 @@ import (
 
 const ConsumerTable = "Consumers"
 
-func PutConsumer(c *v1.Consumer) error {
-	jsonBytes, err := attributevalue.MarshalMap(c)
+// consumerTenantIndex of ConsumerTable lists the consumers of a tenant.
+const consumerTenantIndex = "TenantIndex"
+
+// ConsumerKind names consumers in errors.
+const ConsumerKind = "Consumer"
+
+// ReservedConsumerId can't be the Id of a consumer: the status topics of
+// its resources would match the legacy bundle status topics,
+// v1/<consumer>/bundles/<bundle>/status.
+const ReservedConsumerId = "bundles"
+
+// consumerKey returns the key of a consumer in ConsumerTable. The table is
+// keyed by Id, as before tenants: the consumers of DefaultTenant keep their
+// Id as key, those of other tenants are keyed by "<tenant>/<id>". Ids can't
+// hold a "/".
+func consumerKey(tenantID, consumerID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"Id": &types.AttributeValueMemberS{Value: consumerStoreId(tenantID, consumerID)},
+	}
+}
+
+func consumerStoreId(tenantID, consumerID string) string {
+	if TenantOrDefault(tenantID) == DefaultTenant {
+		return consumerID
+	}
+	return tenantID + "/" + consumerID
+}
+
+// unmarshalConsumer decodes a consumer item, restoring the Id of the
+// consumer from its key.
+func unmarshalConsumer(item map[string]types.AttributeValue) (*v1.Consumer, error) {
+	c := &v1.Consumer{}
+	if err := attributevalue.UnmarshalMap(item, c); err != nil {
+		return nil, err
+	}
+	// items stored before tenants have no TenantId until migrated
+	c.TenantId = TenantOrDefault(c.TenantId)
+	c.Id = strings.TrimPrefix(c.Id, c.TenantId+"/")
+	return c, nil
+}
+
+// CreateConsumer stores a new consumer in its tenant. It returns
+// ErrorAlreadyExists when the tenant already has a consumer with the same
+// Id, and ErrorResourceExhausted when the tenant reached its quota.
+func CreateConsumer(c *v1.Consumer) error {
+	item, err := attributevalue.MarshalMap(c)
+	if err != nil {
+		return err
+	}
+	item["Id"] = consumerKey(c.TenantId, c.Id)["Id"]
+
+	_, err = dbClient.TransactWriteItems(context.TODO(), &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Put: &types.Put{
+				TableName:           aws.String(ConsumerTable),
+				Item:                item,
+				ConditionExpression: aws.String("attribute_not_exists(Id)"),
+			}},
+			{Update: reserveQuota(c.TenantId, consumerQuota)},
+		},
+	})
+
+	var cancelled *types.TransactionCanceledException
+	if errors.As(err, &cancelled) && len(cancelled.CancellationReasons) > 0 &&
+		aws.ToString(cancelled.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+		return &ErrorAlreadyExists{Kind: ConsumerKind, Id: c.Id}
+	}
+	if quotaErr := quotaError(err, 1, c.TenantId, consumerQuota); quotaErr != nil {
+		return quotaErr
+	}
+	return storeError(err)
+}
+
+// UpdateConsumer replaces the labels, keys and compression of an existing
+// consumer, if its labels are still previous, so concurrent changes of the
+// labels aren't lost. It returns ErrorAborted when the consumer was removed
+// or its labels changed since they were read.
+func UpdateConsumer(c *v1.Consumer, previous []*v1.ConsumerLabel) error {
+	condition, values, err := labelsCondition(previous)
+	if err != nil {
+		return err
+	}
+	values[":labels"], err = attributevalue.Marshal(c.Labels)
+	if err != nil {
+		return err
+	}
+	values[":compression"], err = attributevalue.Marshal(c.Compression)
+	if err != nil {
+		return err
+	}
+	values[":publicKey"] = &types.AttributeValueMemberS{Value: c.PublicKey}
+	values[":signingKey"] = &types.AttributeValueMemberS{Value: c.SigningKey}
+
+	// the item also holds the resource counter of the consumer, it isn't
+	// replaced
+	_, err = dbClient.UpdateItem(context.TODO(), &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(ConsumerTable),
+		Key:                       consumerKey(c.TenantId, c.Id),
+		UpdateExpression:          aws.String("SET Labels = :labels, PublicKey = :publicKey, SigningKey = :signingKey, Compression = :compression"),
+		ConditionExpression:       aws.String(condition),
+		ExpressionAttributeValues: values,
+	})
+	return consumerChangedError(err, c.Id)
+}
+
+// UpdateConsumerLabels replaces the labels of an existing consumer, if
+// they're still previous, so concurrent changes of the labels aren't lost.
+// It returns ErrorAborted when the consumer was removed or its labels
+// changed since they were read.
+func UpdateConsumerLabels(c *v1.Consumer, previous []*v1.ConsumerLabel) error {
+	condition, values, err := labelsCondition(previous)
+	if err != nil {
+		return err
+	}
+	values[":labels"], err = attributevalue.Marshal(c.Labels)
 	if err != nil {
 		return err
 	}
 
-	_, err = dbClient.PutItem(
-		context.TODO(),
-		&dynamodb.PutItemInput{
-			TableName: aws.String(ConsumerTable),
-			Item:      jsonBytes,
-		})
+	_, err = dbClient.UpdateItem(context.TODO(), &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(ConsumerTable),
+		Key:                       consumerKey(c.TenantId, c.Id),
+		UpdateExpression:          aws.String("SET Labels = :labels"),
+		ConditionExpression:       aws.String(condition),
+		ExpressionAttributeValues: values,
+	})
+	return consumerChangedError(err, c.Id)
+}
 
-	return err
+// labelsCondition returns the condition and its values matching a stored
+// consumer whose labels are previous.
+func labelsCondition(previous []*v1.ConsumerLabel) (string, map[string]types.AttributeValue, error) {
+	if len(previous) == 0 {
+		// consumers without labels were stored with a null or empty list
+		return "attribute_exists(Id) AND (attribute_not_exists(Labels) OR attribute_type(Labels, :null) OR Labels = :previous)",
+			map[string]types.AttributeValue{
+				":null":     &types.AttributeValueMemberS{Value: "NULL"},
+				":previous": &types.AttributeValueMemberL{Value: []types.AttributeValue{}},
+			}, nil
+	}
+
+	labels, err := attributevalue.Marshal(previous)
+	if err != nil {
+		return "", nil, err
+	}
+	return "attribute_exists(Id) AND Labels = :previous", map[string]types.AttributeValue{":previous": labels}, nil
 }
 
-func GetConsumer(consumerID string) (*v1.Consumer, error) {
+// consumerChangedError returns ErrorAborted when the condition of an update
+// of the consumer failed.
+func consumerChangedError(err error, consumerID string) error {
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return &ErrorAborted{Kind: ConsumerKind, Id: consumerID}
+	}
+	return storeError(err)
+}
+
+func GetConsumer(tenantID, consumerID string) (*v1.Consumer, error) {
 	getItemInput := &dynamodb.GetItemInput{
-		Key: map[string]types.AttributeValue{
-			"Id": &types.AttributeValueMemberS{Value: consumerID},
-		},
+		Key:       consumerKey(tenantID, consumerID),
 		TableName: aws.String(ConsumerTable),
 	}
 
-	c := v1.Consumer{}
-
 	result, err := dbClient.GetItem(context.TODO(), getItemInput)
 	if err != nil {
-		return nil, err
+		return nil, storeError(err)
+	}
+
+	if result.Item == nil {
+		return nil, &ErrorNotFound{Kind: ConsumerKind, Id: consumerID}
+	}
+
+	return unmarshalConsumer(result.Item)
+}
+
+// ListConsumers returns the consumers of a tenant, ordered by Id.
+func ListConsumers(tenantID string) ([]*v1.Consumer, error) {
+	paginator := dynamodb.NewQueryPaginator(dbClient, &dynamodb.QueryInput{
+		TableName:              aws.String(ConsumerTable),
+		IndexName:              aws.String(consumerTenantIndex),
+		KeyConditionExpression: aws.String("TenantId = :tenantId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":tenantId": &types.AttributeValueMemberS{Value: tenantID},
+		},
+	})
+
+	var consumers []*v1.Consumer
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, storeError(err)
+		}
+
+		for _, item := range page.Items {
+			c, err := unmarshalConsumer(item)
+			if err != nil {
+				return nil, err
+			}
+			consumers = append(consumers, c)
+		}
+	}
+	return consumers, nil
+}
+
+// MigrateConsumers sets the TenantId of the consumers stored before
+// tenants to DefaultTenant, so they're listed with its consumers. It's
+// idempotent, and cheap once every consumer is migrated.
+func MigrateConsumers() error {
+	paginator := dynamodb.NewScanPaginator(dbClient, &dynamodb.ScanInput{
+		TableName:            aws.String(ConsumerTable),
+		FilterExpression:     aws.String("attribute_not_exists(TenantId)"),
+		ProjectionExpression: aws.String("Id"),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return storeError(err)
+		}
+
+		for _, item := range page.Items {
+			_, err := dbClient.UpdateItem(context.TODO(), &dynamodb.UpdateItemInput{
+				TableName:           aws.String(ConsumerTable),
+				Key:                 map[string]types.AttributeValue{"Id": item["Id"]},
+				UpdateExpression:    aws.String("SET TenantId = :tenantId"),
+				ConditionExpression: aws.String("attribute_exists(Id) AND attribute_not_exists(TenantId)"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":tenantId": &types.AttributeValueMemberS{Value: DefaultTenant},
+				},
+			})
+			var conditionErr *types.ConditionalCheckFailedException
+			if err != nil && !errors.As(err, &conditionErr) {
+				return storeError(err)
+			}
+		}
+	}
+	return nil
+}
+
+// CountConsumerResources sets the ResourceCount of every consumer to the
+// number of its resources and bundles, for those stored before consumers
+// counted them: the counter of each consumer not recounted yet is reset,
+// then every resource and bundle not counted yet is counted and marked as
+// ConsumerCounted. It's idempotent, and cheap once every consumer is
+// recounted.
+func CountConsumerResources() error {
+	paginator := dynamodb.NewScanPaginator(dbClient, &dynamodb.ScanInput{
+		TableName:            aws.String(ConsumerTable),
+		FilterExpression:     aws.String("attribute_not_exists(ResourcesCounted)"),
+		ProjectionExpression: aws.String("Id"),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return storeError(err)
+		}
+
+		for _, item := range page.Items {
+			_, err := dbClient.UpdateItem(context.TODO(), &dynamodb.UpdateItemInput{
+				TableName:           aws.String(ConsumerTable),
+				Key:                 map[string]types.AttributeValue{"Id": item["Id"]},
+				UpdateExpression:    aws.String("SET ResourceCount = :zero, ResourcesCounted = :true"),
+				ConditionExpression: aws.String("attribute_exists(Id) AND attribute_not_exists(ResourcesCounted)"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":zero": &types.AttributeValueMemberN{Value: "0"},
+					":true": &types.AttributeValueMemberBOOL{Value: true},
+				},
+			})
+			var conditionErr *types.ConditionalCheckFailedException
+			if err != nil && !errors.As(err, &conditionErr) {
+				return storeError(err)
+			}
+		}
+	}
+
+	for _, table := range []string{ResourceTable, ResourceBundleTable} {
+		if err := countConsumerResources(table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// countConsumerResources counts the items of table, resources or bundles,
+// not counted yet in the ResourceCount of their consumer.
+func countConsumerResources(table string) error {
+	paginator := dynamodb.NewScanPaginator(dbClient, &dynamodb.ScanInput{
+		TableName:            aws.String(table),
+		FilterExpression:     aws.String("attribute_not_exists(ConsumerCounted)"),
+		ProjectionExpression: aws.String("Id, TenantId, ConsumerId"),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return storeError(err)
+		}
+
+		for _, item := range page.Items {
+			owner := struct{ TenantId, ConsumerId string }{}
+			if err := attributevalue.UnmarshalMap(item, &owner); err != nil {
+				return err
+			}
+
+			_, err := dbClient.TransactWriteItems(context.TODO(), &dynamodb.TransactWriteItemsInput{
+				TransactItems: []types.TransactWriteItem{
+					{Update: &types.Update{
+						TableName:           aws.String(table),
+						Key:                 map[string]types.AttributeValue{"Id": item["Id"]},
+						UpdateExpression:    aws.String("SET ConsumerCounted = :true"),
+						ConditionExpression: aws.String("attribute_exists(Id) AND attribute_not_exists(ConsumerCounted)"),
+						ExpressionAttributeValues: map[string]types.AttributeValue{
+							":true": &types.AttributeValueMemberBOOL{Value: true},
+						},
+					}},
+					{Update: reserveConsumerResource(owner.TenantId, owner.ConsumerId, 0)},
+				},
+			})
+			// removed or counted meanwhile, or its consumer doesn't exist
+			var cancelled *types.TransactionCanceledException
+			if err != nil && !errors.As(err, &cancelled) {
+				return storeError(err)
+			}
+		}
+	}
+	return nil
+}
+
+// RequireConsumer returns the consumer, or ErrorFailedPrecondition when it
+// doesn't exist, for calls creating objects for it.
+func RequireConsumer(tenantID, consumerID string) (*v1.Consumer, error) {
+	c, err := GetConsumer(tenantID, consumerID)
+
+	var notFound *ErrorNotFound
+	if errors.As(err, &notFound) {
+		return nil, missingConsumerError(consumerID)
+	}
+	return c, err
+}
+
+func missingConsumerError(consumerID string) error {
+	return &ErrorFailedPrecondition{
+		Type:        "CONSUMER",
+		Subject:     consumerID,
+		Description: fmt.Sprintf("consumer %q does not exist, create it first", consumerID),
+	}
+}
+
+// foreignStatusError rejects the status of a resource or bundle reported
+// by the agent of consumerID, when it's placed on another consumer: the
+// agent only signs as its own consumer.
+func foreignStatusError(kind, id, consumerID string) error {
+	return &ErrorFailedPrecondition{
+		Type:        "CONSUMER",
+		Subject:     id,
+		Description: fmt.Sprintf("%s %q isn't placed on consumer %q", kind, id, consumerID),
+	}
+}
+
+// GetConsumerResourceCount returns the number of resources and bundles of
+// a consumer.
+func GetConsumerResourceCount(tenantID, consumerID string) (int64, error) {
+	result, err := dbClient.GetItem(context.TODO(), &dynamodb.GetItemInput{
+		TableName:            aws.String(ConsumerTable),
+		Key:                  consumerKey(tenantID, consumerID),
+		ProjectionExpression: aws.String("ResourceCount"),
+	})
+	if err != nil {
+		return 0, storeError(err)
 	}
 
 	if result.Item == nil {
-		return nil, &ErrorNotFound{}
+		return 0, &ErrorNotFound{Kind: ConsumerKind, Id: consumerID}
+	}
+
+	counter := struct{ ResourceCount int64 }{}
+	err = attributevalue.UnmarshalMap(result.Item, &counter)
+	return counter.ResourceCount, err
+}
+
+// reserveConsumerResource increments the resource counter of a consumer in
+// a transaction, failing when the consumer doesn't exist or, with a
+// maxResources above 0, when the counter reached it. See
+// consumerQuotaError.
+func reserveConsumerResource(tenantID, consumerID string, maxResources int64) *types.Update {
+	condition := "attribute_exists(Id)"
+	values := map[string]types.AttributeValue{
+		":zero": &types.AttributeValueMemberN{Value: "0"},
+		":one":  &types.AttributeValueMemberN{Value: "1"},
+	}
+	if maxResources > 0 {
+		condition += " AND (attribute_not_exists(ResourceCount) OR ResourceCount < :max)"
+		values[":max"] = &types.AttributeValueMemberN{Value: fmt.Sprint(maxResources)}
 	}
 
-	err = attributevalue.UnmarshalMap(result.Item, &c)
-	return &c, err
+	return &types.Update{
+		TableName:                           aws.String(ConsumerTable),
+		Key:                                 consumerKey(tenantID, consumerID),
+		UpdateExpression:                    aws.String("SET ResourceCount = if_not_exists(ResourceCount, :zero) + :one"),
+		ConditionExpression:                 aws.String(condition),
+		ExpressionAttributeValues:           values,
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}
+}
+
+// releaseConsumerResource decrements the resource counter of a consumer,
+// in a transaction. Its condition fails when the counter is already zero.
+func releaseConsumerResource(tenantID, consumerID string) *types.Update {
+	return &types.Update{
+		TableName:           aws.String(ConsumerTable),
+		Key:                 consumerKey(tenantID, consumerID),
+		UpdateExpression:    aws.String("SET ResourceCount = ResourceCount - :one"),
+		ConditionExpression: aws.String("ResourceCount > :zero"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":zero": &types.AttributeValueMemberN{Value: "0"},
+			":one":  &types.AttributeValueMemberN{Value: "1"},
+		},
+	}
+}
+
+// consumerQuotaError turns a cancelled transaction whose
+// reserveConsumerResource update, at index i, failed its condition into
+// ErrorFailedPrecondition when the consumer doesn't exist, or
+// ErrorResourceExhausted. It returns nil for any other error.
+func consumerQuotaError(err error, i int, consumerID string, maxResources int64) error {
+	var cancelled *types.TransactionCanceledException
+	if !errors.As(err, &cancelled) || len(cancelled.CancellationReasons) <= i {
+		return nil
+	}
+
+	reason := cancelled.CancellationReasons[i]
+	if aws.ToString(reason.Code) != "ConditionalCheckFailed" {
+		return nil
+	}
+
+	if reason.Item == nil {
+		return missingConsumerError(consumerID)
+	}
+	return &ErrorResourceExhausted{
+		Subject:     "consumer:" + consumerID,
+		Description: fmt.Sprintf("consumer %q reached its limit of %d resources", consumerID, maxResources),
+	}
 }
@@ -0,0 +1,289 @@
+package db
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCreateResourceCount(t *testing.T) {
+	tests := []struct {
+		name         string
+		count        int64
+		maxResources int64
+		noConsumer   bool
+		tenantFull   bool
+		keyTaken     bool
+		wantErr      interface{}
+		wantCount    int64
+	}{
+		{name: "under the limit", count: 1, maxResources: 2, wantCount: 2},
+		{name: "no limit", count: 5, wantCount: 6},
+		{name: "first resource", wantCount: 1},
+		{name: "limit reached", count: 2, maxResources: 2, wantErr: &ErrorResourceExhausted{}, wantCount: 2},
+		{name: "missing consumer", noConsumer: true, wantErr: &ErrorFailedPrecondition{}},
+		{name: "tenant quota reached", count: 1, tenantFull: true, wantErr: &ErrorResourceExhausted{}, wantCount: 1},
+		{name: "target object taken", count: 1, keyTaken: true, wantErr: &ErrorAlreadyExists{}, wantCount: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := useFakeStore(t)
+			if !tt.noConsumer {
+				putConsumer(s, "cluster1", tt.count)
+			}
+			if tt.tenantFull {
+				s.put(TenantTable, mustMarshal(t, &Tenant{Id: DefaultTenant, MaxResources: 1, ResourceCount: 1}))
+			}
+			r := newTestResource("resource1", "cluster1")
+			if tt.keyTaken {
+				s.put(ResourceKeyTable, mustMarshal(t, resourceKey{Key: TargetKeyOf(r).String(), ResourceId: "resource2"}))
+			}
+
+			err := CreateResource(r, tt.maxResources)
+			checkError(t, err, tt.wantErr)
+			if tt.noConsumer {
+				return
+			}
+			if got := consumerCount(t, "cluster1"); got != tt.wantCount {
+				t.Errorf("got count %d, want %d", got, tt.wantCount)
+			}
+			// the resource is stored with its count, or not at all
+			stored := s.get(ResourceTable, item{"Id": &types.AttributeValueMemberS{Value: r.Id}})
+			if (stored != nil) != (tt.wantErr == nil) {
+				t.Errorf("got resource stored %v, want %v", stored != nil, tt.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestRemoveResourceCount(t *testing.T) {
+	tests := []struct {
+		name        string
+		count       int64
+		uncounted   bool
+		notDeleting bool
+		keyTaken    bool
+		wantCount   int64
+		wantRemoved bool
+	}{
+		{name: "counted", count: 2, wantCount: 1, wantRemoved: true},
+		{name: "stored before counting", count: 2, uncounted: true, wantCount: 2, wantRemoved: true},
+		{name: "counter already zero", wantCount: 0, wantRemoved: true},
+		{name: "key of another resource", count: 2, keyTaken: true, wantCount: 1, wantRemoved: true},
+		{name: "not being deleted", count: 2, notDeleting: true, wantCount: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := useFakeStore(t)
+			putConsumer(s, "cluster1", tt.count)
+			r := newTestResource("resource1", "cluster1")
+			r.ConsumerCounted = !tt.uncounted
+			r.Deleting = !tt.notDeleting
+			s.put(ResourceTable, mustMarshal(t, r))
+			owner := r.Id
+			if tt.keyTaken {
+				owner = "resource2"
+			}
+			s.put(ResourceKeyTable, mustMarshal(t, resourceKey{Key: TargetKeyOf(r).String(), ResourceId: owner}))
+
+			if err := removeResource(r); err != nil {
+				t.Fatal(err)
+			}
+			if got := consumerCount(t, "cluster1"); got != tt.wantCount {
+				t.Errorf("got count %d, want %d", got, tt.wantCount)
+			}
+			stored := s.get(ResourceTable, item{"Id": &types.AttributeValueMemberS{Value: r.Id}})
+			if (stored == nil) != tt.wantRemoved {
+				t.Errorf("got resource removed %v, want %v", stored == nil, tt.wantRemoved)
+			}
+			key := s.get(ResourceKeyTable, item{"Key": &types.AttributeValueMemberS{Value: TargetKeyOf(r).String()}})
+			if wantKey := tt.keyTaken || !tt.wantRemoved; (key != nil) != wantKey {
+				t.Errorf("got key kept %v, want %v", key != nil, wantKey)
+			}
+
+			// a status removing the resource again changes nothing
+			if err := removeResource(r); err != nil {
+				t.Fatal(err)
+			}
+			if got := consumerCount(t, "cluster1"); got != tt.wantCount {
+				t.Errorf("got count %d after removing again, want %d", got, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestResourceBundleCount(t *testing.T) {
+	s := useFakeStore(t)
+	putConsumer(s, "cluster1", 1)
+
+	b := &ResourceBundle{
+		Id:         "bundle1",
+		TenantId:   DefaultTenant,
+		ConsumerId: "cluster1",
+		Manifests:  []unstructured.Unstructured{*newTestObject("a"), *newTestObject("b")},
+	}
+	if err := CreateResourceBundle(b, 2); err != nil {
+		t.Fatal(err)
+	}
+	if got := consumerCount(t, "cluster1"); got != 2 {
+		t.Fatalf("got count %d after creating the bundle, want 2", got)
+	}
+
+	full := &ResourceBundle{Id: "bundle2", TenantId: DefaultTenant, ConsumerId: "cluster1", Manifests: []unstructured.Unstructured{*newTestObject("c")}}
+	checkError(t, CreateResourceBundle(full, 2), &ErrorResourceExhausted{})
+
+	// a manifest of another bundle fails the whole transaction
+	taken := &ResourceBundle{Id: "bundle3", TenantId: DefaultTenant, ConsumerId: "cluster1", Manifests: []unstructured.Unstructured{*newTestObject("d"), *newTestObject("a")}}
+	checkError(t, CreateResourceBundle(taken, 0), &ErrorAlreadyExists{})
+	if got := consumerCount(t, "cluster1"); got != 2 {
+		t.Fatalf("got count %d after failed creations, want 2", got)
+	}
+
+	b.Deleting = true
+	s.put(ResourceBundleTable, mustMarshal(t, b))
+	removed, err := removeResourceBundle(b)
+	if err != nil || !removed {
+		t.Fatalf("got removed %v and error %v, want removed", removed, err)
+	}
+	if got := consumerCount(t, "cluster1"); got != 1 {
+		t.Errorf("got count %d after removing the bundle, want 1", got)
+	}
+	if len(s.tables[ResourceKeyTable]) != 0 {
+		t.Errorf("got keys %v left, want none", s.tables[ResourceKeyTable])
+	}
+
+	removed, err = removeResourceBundle(b)
+	if err != nil || !removed {
+		t.Fatalf("got removed %v and error %v removing again, want removed", removed, err)
+	}
+	if got := consumerCount(t, "cluster1"); got != 1 {
+		t.Errorf("got count %d after removing again, want 1", got)
+	}
+}
+
+func TestUpdateConsumerLabelsChanged(t *testing.T) {
+	read := []*v1.ConsumerLabel{{Key: "env", Value: "prod"}}
+
+	tests := []struct {
+		name    string
+		stored  []*v1.ConsumerLabel
+		read    []*v1.ConsumerLabel
+		noItem  bool
+		wantErr interface{}
+	}{
+		{name: "labels as read", stored: read, read: read},
+		{name: "without labels", read: nil},
+		{name: "labels changed", stored: []*v1.ConsumerLabel{{Key: "env", Value: "dev"}}, read: read, wantErr: &ErrorAborted{}},
+		{name: "labels added", stored: read, read: nil, wantErr: &ErrorAborted{}},
+		{name: "consumer removed", read: read, noItem: true, wantErr: &ErrorAborted{}},
+	}
+	for _, tt := range tests {
+		for _, update := range []struct {
+			name string
+			call func(*v1.Consumer, []*v1.ConsumerLabel) error
+		}{
+			{"UpdateConsumer", UpdateConsumer},
+			{"UpdateConsumerLabels", UpdateConsumerLabels},
+		} {
+			t.Run(update.name+"/"+tt.name, func(t *testing.T) {
+				s := useFakeStore(t)
+				if !tt.noItem {
+					putConsumer(s, "cluster1", 3)
+					if tt.stored != nil {
+						stored := s.get(ConsumerTable, consumerKey(DefaultTenant, "cluster1"))
+						stored["Labels"] = mustMarshalValue(t, tt.stored)
+					}
+				}
+
+				labels := []*v1.ConsumerLabel{{Key: "env", Value: "staging"}}
+				err := update.call(&v1.Consumer{Id: "cluster1", TenantId: DefaultTenant, Labels: labels}, tt.read)
+				checkError(t, err, tt.wantErr)
+				if tt.wantErr != nil {
+					return
+				}
+
+				c, err := GetConsumer(DefaultTenant, "cluster1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(c.Labels) != 1 || c.Labels[0].Value != "staging" {
+					t.Errorf("got labels %v, want env=staging", c.Labels)
+				}
+				if got := consumerCount(t, "cluster1"); got != 3 {
+					t.Errorf("got count %d, want the count kept", got)
+				}
+			})
+		}
+	}
+}
+
+func putConsumer(s *fakeStore, consumerID string, count int64) {
+	s.put(ConsumerTable, item{
+		"Id":            &types.AttributeValueMemberS{Value: consumerID},
+		"ResourceCount": &types.AttributeValueMemberN{Value: strconv.FormatInt(count, 10)},
+	})
+}
+
+func consumerCount(t *testing.T, consumerID string) int64 {
+	t.Helper()
+	count, err := GetConsumerResourceCount(DefaultTenant, consumerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func newTestResource(id, consumerID string) *Resource {
+	return &Resource{Id: id, TenantId: DefaultTenant, ConsumerId: consumerID, Object: *newTestObject("config")}
+}
+
+func newTestObject(name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"}}
+	obj.SetNamespace("default")
+	obj.SetName(name)
+	return obj
+}
+
+func mustMarshalValue(t *testing.T, v interface{}) types.AttributeValue {
+	t.Helper()
+	av, err := attributevalue.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return av
+}
+
+// checkError fails when err isn't of the type of want, or isn't nil when
+// want is nil.
+func checkError(t *testing.T, err error, want interface{}) {
+	t.Helper()
+	switch want := want.(type) {
+	case nil:
+		if err != nil {
+			t.Fatalf("got error %v, want none", err)
+		}
+	case *ErrorResourceExhausted:
+		if !errors.As(err, &want) {
+			t.Fatalf("got error %v, want ErrorResourceExhausted", err)
+		}
+	case *ErrorFailedPrecondition:
+		if !errors.As(err, &want) {
+			t.Fatalf("got error %v, want ErrorFailedPrecondition", err)
+		}
+	case *ErrorAlreadyExists:
+		if !errors.As(err, &want) {
+			t.Fatalf("got error %v, want ErrorAlreadyExists", err)
+		}
+	case *ErrorAborted:
+		if !errors.As(err, &want) {
+			t.Fatalf("got error %v, want ErrorAborted", err)
+		}
+	default:
+		t.Fatalf("unexpected error type %T", want)
+	}
+}
@@ -19,12 +19,6 @@ const (
 	awsSecretAccessKey = "AWS_SECRET_ACCESS_KEY"
 )
 
-type ErrorNotFound struct{}
-
-func (e *ErrorNotFound) Error() string {
-	return fmt.Sprintf("Resource not found")
-}
-
 func init() {
 	dbClient, _ = newClient()
 }
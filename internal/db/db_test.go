@@ -0,0 +1,517 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
+)
+
+type item = map[string]types.AttributeValue
+
+// fakeStore is an in-memory DynamoDB serving the calls of dbClient in
+// tests, with the condition and update expressions of this package.
+type fakeStore struct {
+	tables map[string]map[string]item
+	// calls served so far, by operation.
+	calls map[string]int
+}
+
+// keySchemas lists the key attributes of the tables not keyed by Id.
+var keySchemas = map[string][]string{
+	ResourceKeyTable:           {"Key"},
+	ResourceStatusHistoryTable: {"ResourceId", "Timestamp"},
+}
+
+// useFakeStore points dbClient to a new fakeStore for the duration of the
+// test.
+func useFakeStore(t *testing.T) *fakeStore {
+	t.Helper()
+	s := &fakeStore{tables: map[string]map[string]item{}, calls: map[string]int{}}
+
+	serve := middleware.InitializeMiddlewareFunc("fakeStore", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+		result, err := s.serve(in.Parameters)
+		return middleware.InitializeOutput{Result: result}, middleware.Metadata{}, err
+	})
+	previous := dbClient
+	dbClient = dynamodb.New(dynamodb.Options{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("test", "test", ""),
+		APIOptions: []func(*middleware.Stack) error{func(stack *middleware.Stack) error {
+			return stack.Initialize.Add(serve, middleware.Before)
+		}},
+	})
+	t.Cleanup(func() { dbClient = previous })
+	return s
+}
+
+// put stores it in table as it is.
+func (s *fakeStore) put(table string, it item) {
+	if s.tables[table] == nil {
+		s.tables[table] = map[string]item{}
+	}
+	s.tables[table][keyOf(table, it)] = it
+}
+
+// get returns the item of table with key, nil when there's none.
+func (s *fakeStore) get(table string, key item) item {
+	return s.tables[table][keyOf(table, key)]
+}
+
+func keyOf(table string, it item) string {
+	names, ok := keySchemas[table]
+	if !ok {
+		names = []string{"Id"}
+	}
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprint(scalar(it[name]))
+	}
+	return strings.Join(parts, "/")
+}
+
+// mustMarshal returns the item of v.
+func mustMarshal(t *testing.T, v interface{}) item {
+	t.Helper()
+	it, err := attributevalue.MarshalMap(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return it
+}
+
+func (s *fakeStore) serve(params interface{}) (interface{}, error) {
+	switch in := params.(type) {
+	case *dynamodb.GetItemInput:
+		s.calls["GetItem"]++
+		return &dynamodb.GetItemOutput{Item: s.get(aws.ToString(in.TableName), in.Key)}, nil
+	case *dynamodb.PutItemInput:
+		s.calls["PutItem"]++
+		s.put(aws.ToString(in.TableName), in.Item)
+		return &dynamodb.PutItemOutput{}, nil
+	case *dynamodb.QueryInput:
+		s.calls["Query"]++
+		return s.query(in)
+	case *dynamodb.BatchWriteItemInput:
+		s.calls["BatchWriteItem"]++
+		for table, requests := range in.RequestItems {
+			for _, r := range requests {
+				if r.DeleteRequest != nil {
+					delete(s.tables[table], keyOf(table, r.DeleteRequest.Key))
+				}
+			}
+		}
+		return &dynamodb.BatchWriteItemOutput{}, nil
+	case *dynamodb.UpdateItemInput:
+		s.calls["UpdateItem"]++
+		// a transaction of a single update, failing the same way
+		err := s.transact([]types.TransactWriteItem{{Update: &types.Update{
+			TableName:                 in.TableName,
+			Key:                       in.Key,
+			UpdateExpression:          in.UpdateExpression,
+			ConditionExpression:       in.ConditionExpression,
+			ExpressionAttributeNames:  in.ExpressionAttributeNames,
+			ExpressionAttributeValues: in.ExpressionAttributeValues,
+		}}})
+		var cancelled *types.TransactionCanceledException
+		if errors.As(err, &cancelled) {
+			return nil, &types.ConditionalCheckFailedException{Message: aws.String("condition failed")}
+		}
+		return &dynamodb.UpdateItemOutput{}, err
+	case *dynamodb.TransactWriteItemsInput:
+		s.calls["TransactWriteItems"]++
+		return &dynamodb.TransactWriteItemsOutput{}, s.transact(in.TransactItems)
+	default:
+		return nil, fmt.Errorf("fake store: unsupported call %T", params)
+	}
+}
+
+// query returns the items of the table matching the key condition, sorted
+// by the numeric sort key of the table.
+func (s *fakeStore) query(in *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	table := aws.ToString(in.TableName)
+	var items []item
+	for _, it := range s.tables[table] {
+		ok, err := evaluate(aws.ToString(in.KeyConditionExpression), it, in.ExpressionAttributeNames, in.ExpressionAttributeValues)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			items = append(items, it)
+		}
+	}
+
+	sortKey := keySchemas[table][len(keySchemas[table])-1]
+	forward := in.ScanIndexForward == nil || *in.ScanIndexForward
+	sort.Slice(items, func(i, j int) bool {
+		less := number(items[i][sortKey]) < number(items[j][sortKey])
+		if forward {
+			return less
+		}
+		return !less
+	})
+	if in.Limit != nil && len(items) > int(*in.Limit) {
+		items = items[:*in.Limit]
+	}
+	return &dynamodb.QueryOutput{Items: items, Count: int32(len(items))}, nil
+}
+
+// transact applies items when all their conditions hold, or cancels the
+// transaction with the reason of each item.
+func (s *fakeStore) transact(items []types.TransactWriteItem) error {
+	reasons := make([]types.CancellationReason, len(items))
+	cancelled := false
+	for i, ti := range items {
+		table, key, condition, names, values, returnOld := writeOf(ti)
+		existing := s.get(table, key)
+		ok := true
+		if condition != "" {
+			var err error
+			ok, err = evaluate(condition, existing, names, values)
+			if err != nil {
+				return err
+			}
+		}
+		reasons[i] = types.CancellationReason{Code: aws.String("None")}
+		if !ok {
+			cancelled = true
+			reasons[i].Code = aws.String("ConditionalCheckFailed")
+			if returnOld {
+				reasons[i].Item = existing
+			}
+		}
+	}
+	if cancelled {
+		return &types.TransactionCanceledException{Message: aws.String("transaction cancelled"), CancellationReasons: reasons}
+	}
+
+	for _, ti := range items {
+		switch {
+		case ti.Put != nil:
+			s.put(aws.ToString(ti.Put.TableName), ti.Put.Item)
+		case ti.Delete != nil:
+			delete(s.tables[aws.ToString(ti.Delete.TableName)], keyOf(aws.ToString(ti.Delete.TableName), ti.Delete.Key))
+		case ti.Update != nil:
+			table := aws.ToString(ti.Update.TableName)
+			updated := item{}
+			for name, value := range s.get(table, ti.Update.Key) {
+				updated[name] = value
+			}
+			for name, value := range ti.Update.Key {
+				updated[name] = value
+			}
+			err := update(aws.ToString(ti.Update.UpdateExpression), updated, ti.Update.ExpressionAttributeNames, ti.Update.ExpressionAttributeValues)
+			if err != nil {
+				return err
+			}
+			s.put(table, updated)
+		}
+	}
+	return nil
+}
+
+func writeOf(ti types.TransactWriteItem) (table string, key item, condition string, names map[string]string, values item, returnOld bool) {
+	switch {
+	case ti.Put != nil:
+		table = aws.ToString(ti.Put.TableName)
+		return table, ti.Put.Item, aws.ToString(ti.Put.ConditionExpression), ti.Put.ExpressionAttributeNames, ti.Put.ExpressionAttributeValues,
+			ti.Put.ReturnValuesOnConditionCheckFailure == types.ReturnValuesOnConditionCheckFailureAllOld
+	case ti.Delete != nil:
+		return aws.ToString(ti.Delete.TableName), ti.Delete.Key, aws.ToString(ti.Delete.ConditionExpression), ti.Delete.ExpressionAttributeNames, ti.Delete.ExpressionAttributeValues,
+			ti.Delete.ReturnValuesOnConditionCheckFailure == types.ReturnValuesOnConditionCheckFailureAllOld
+	case ti.Update != nil:
+		return aws.ToString(ti.Update.TableName), ti.Update.Key, aws.ToString(ti.Update.ConditionExpression), ti.Update.ExpressionAttributeNames, ti.Update.ExpressionAttributeValues,
+			ti.Update.ReturnValuesOnConditionCheckFailure == types.ReturnValuesOnConditionCheckFailureAllOld
+	default:
+		return aws.ToString(ti.ConditionCheck.TableName), ti.ConditionCheck.Key, aws.ToString(ti.ConditionCheck.ConditionExpression), ti.ConditionCheck.ExpressionAttributeNames, ti.ConditionCheck.ExpressionAttributeValues,
+			ti.ConditionCheck.ReturnValuesOnConditionCheckFailure == types.ReturnValuesOnConditionCheckFailureAllOld
+	}
+}
+
+// expression holds the tokens of a condition or update expression being
+// parsed.
+type expression struct {
+	tokens []string
+	it     item
+	names  map[string]string
+	values item
+}
+
+func tokenize(s string) []string {
+	var tokens []string
+	current := ""
+	flush := func() {
+		if current != "" {
+			tokens = append(tokens, current)
+			current = ""
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ' ':
+			flush()
+		case strings.ContainsRune("(),+-", rune(c)):
+			flush()
+			tokens = append(tokens, string(c))
+		case strings.ContainsRune("=<>", rune(c)):
+			flush()
+			if i+1 < len(s) && strings.ContainsRune("=>", rune(s[i+1])) {
+				tokens = append(tokens, s[i:i+2])
+				i++
+			} else {
+				tokens = append(tokens, string(c))
+			}
+		default:
+			current += string(c)
+		}
+	}
+	flush()
+	return tokens
+}
+
+func (e *expression) next() string {
+	if len(e.tokens) == 0 {
+		return ""
+	}
+	token := e.tokens[0]
+	e.tokens = e.tokens[1:]
+	return token
+}
+
+func (e *expression) peek() string {
+	if len(e.tokens) == 0 {
+		return ""
+	}
+	return e.tokens[0]
+}
+
+func (e *expression) expect(token string) error {
+	if got := e.next(); got != token {
+		return fmt.Errorf("fake store: got %q, want %q", got, token)
+	}
+	return nil
+}
+
+// evaluate evaluates condition on it, nil when the item doesn't exist.
+func evaluate(condition string, it item, names map[string]string, values item) (bool, error) {
+	e := &expression{tokens: tokenize(condition), it: it, names: names, values: values}
+	ok, err := e.or()
+	if err == nil && len(e.tokens) > 0 {
+		err = fmt.Errorf("fake store: unexpected %q in %q", e.tokens[0], condition)
+	}
+	return ok, err
+}
+
+func (e *expression) or() (bool, error) {
+	ok, err := e.and()
+	for err == nil && e.peek() == "OR" {
+		e.next()
+		var right bool
+		right, err = e.and()
+		ok = ok || right
+	}
+	return ok, err
+}
+
+func (e *expression) and() (bool, error) {
+	ok, err := e.unary()
+	for err == nil && e.peek() == "AND" {
+		e.next()
+		var right bool
+		right, err = e.unary()
+		ok = ok && right
+	}
+	return ok, err
+}
+
+func (e *expression) unary() (bool, error) {
+	token := e.next()
+	switch token {
+	case "(":
+		ok, err := e.or()
+		if err != nil {
+			return false, err
+		}
+		return ok, e.expect(")")
+	case "attribute_exists", "attribute_not_exists", "attribute_type":
+		if err := e.expect("("); err != nil {
+			return false, err
+		}
+		value := e.operand(e.next())
+		var want types.AttributeValue
+		if token == "attribute_type" {
+			if err := e.expect(","); err != nil {
+				return false, err
+			}
+			want = e.operand(e.next())
+		}
+		if err := e.expect(")"); err != nil {
+			return false, err
+		}
+		switch token {
+		case "attribute_exists":
+			return value != nil, nil
+		case "attribute_not_exists":
+			return value == nil, nil
+		default:
+			return value != nil && attributeType(value) == scalar(want), nil
+		}
+	}
+
+	left := e.operand(token)
+	op := e.next()
+	right := e.operand(e.next())
+	if left == nil || right == nil {
+		return op == "<>", nil
+	}
+	switch op {
+	case "=":
+		return equal(left, right), nil
+	case "<>":
+		return !equal(left, right), nil
+	case "<":
+		return number(left) < number(right), nil
+	case "<=":
+		return number(left) <= number(right), nil
+	case ">":
+		return number(left) > number(right), nil
+	case ">=":
+		return number(left) >= number(right), nil
+	default:
+		return false, fmt.Errorf("fake store: unsupported operator %q", op)
+	}
+}
+
+// operand returns the value of a placeholder or attribute, nil when the
+// attribute doesn't exist.
+func (e *expression) operand(token string) types.AttributeValue {
+	if strings.HasPrefix(token, ":") {
+		return e.values[token]
+	}
+	if name, ok := e.names[token]; ok {
+		token = name
+	}
+	return e.it[token]
+}
+
+// update applies the SET actions of an update expression to it.
+func update(expr string, it item, names map[string]string, values item) error {
+	e := &expression{tokens: tokenize(expr), it: it, names: names, values: values}
+	if err := e.expect("SET"); err != nil {
+		return err
+	}
+	for {
+		name := e.next()
+		if n, ok := names[name]; ok {
+			name = n
+		}
+		if err := e.expect("="); err != nil {
+			return err
+		}
+		value, err := e.term()
+		if err != nil {
+			return err
+		}
+		if op := e.peek(); op == "+" || op == "-" {
+			e.next()
+			right, err := e.term()
+			if err != nil {
+				return err
+			}
+			sum := number(value) + number(right)
+			if op == "-" {
+				sum = number(value) - number(right)
+			}
+			value = &types.AttributeValueMemberN{Value: strconv.FormatFloat(sum, 'f', -1, 64)}
+		}
+		it[name] = value
+
+		if e.peek() == "" {
+			return nil
+		}
+		if err := e.expect(","); err != nil {
+			return err
+		}
+	}
+}
+
+func (e *expression) term() (types.AttributeValue, error) {
+	token := e.next()
+	if token != "if_not_exists" {
+		return e.operand(token), nil
+	}
+	if err := e.expect("("); err != nil {
+		return nil, err
+	}
+	value := e.operand(e.next())
+	if err := e.expect(","); err != nil {
+		return nil, err
+	}
+	fallback := e.operand(e.next())
+	if value == nil {
+		value = fallback
+	}
+	return value, e.expect(")")
+}
+
+func equal(a, b types.AttributeValue) bool {
+	_, aNumber := a.(*types.AttributeValueMemberN)
+	_, bNumber := b.(*types.AttributeValueMemberN)
+	if aNumber && bNumber {
+		return number(a) == number(b)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func scalar(v types.AttributeValue) interface{} {
+	switch v := v.(type) {
+	case *types.AttributeValueMemberS:
+		return v.Value
+	case *types.AttributeValueMemberN:
+		return v.Value
+	case *types.AttributeValueMemberBOOL:
+		return v.Value
+	default:
+		return v
+	}
+}
+
+func number(v types.AttributeValue) float64 {
+	n, ok := v.(*types.AttributeValueMemberN)
+	if !ok {
+		return 0
+	}
+	f, _ := strconv.ParseFloat(n.Value, 64)
+	return f
+}
+
+func attributeType(v types.AttributeValue) string {
+	switch v.(type) {
+	case *types.AttributeValueMemberS:
+		return "S"
+	case *types.AttributeValueMemberN:
+		return "N"
+	case *types.AttributeValueMemberBOOL:
+		return "BOOL"
+	case *types.AttributeValueMemberNULL:
+		return "NULL"
+	case *types.AttributeValueMemberL:
+		return "L"
+	case *types.AttributeValueMemberM:
+		return "M"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,170 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+const DeadLetterTable = "DeadLetters"
+
+// DeadLetterKind names dead letters in errors.
+const DeadLetterKind = "DeadLetter"
+
+// maxDeadLetterPayloadBytes keeps dead letters below the item size limit
+// of DynamoDB.
+const maxDeadLetterPayloadBytes = 256 << 10
+
+// DeadLetter is a message of an agent that failed to be processed.
+type DeadLetter struct {
+	TenantId string
+	// Id sorts the dead letters of a tenant by arrival.
+	Id      string
+	Topic   string
+	Payload []byte
+	// Truncated is set when Payload is only the beginning of the payload.
+	Truncated bool
+	Error     string
+	// Unix Timestamp (UTC) in nanoseconds at which the message was
+	// received.
+	Timestamp int64
+}
+
+// PutDeadLetter stores a failed message of tenantID and counts it in the
+// DeadLetterCount of the tenant.
+func PutDeadLetter(tenantID, topic string, payload []byte, cause error) (*DeadLetter, error) {
+	now := time.Now().UTC()
+	d := &DeadLetter{
+		TenantId:  TenantOrDefault(tenantID),
+		Id:        fmt.Sprintf("%019d-%s", now.UnixNano(), uuid.NewString()),
+		Topic:     topic,
+		Payload:   payload,
+		Error:     cause.Error(),
+		Timestamp: now.UnixNano(),
+	}
+	if len(d.Payload) > maxDeadLetterPayloadBytes {
+		d.Payload = d.Payload[:maxDeadLetterPayloadBytes]
+		d.Truncated = true
+	}
+
+	item, err := attributevalue.MarshalMap(d)
+	if err != nil {
+		return nil, err
+	}
+	_, err = dbClient.PutItem(context.TODO(), &dynamodb.PutItemInput{
+		TableName: aws.String(DeadLetterTable),
+		Item:      item,
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(TenantTable),
+		Key: map[string]types.AttributeValue{
+			"Id": &types.AttributeValueMemberS{Value: d.TenantId},
+		},
+		UpdateExpression: aws.String("ADD DeadLetterCount :one"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one": &types.AttributeValueMemberN{Value: "1"},
+		},
+	}
+	// the default tenant is stored the first time it's updated, unknown
+	// tenants aren't
+	if d.TenantId != DefaultTenant {
+		input.ConditionExpression = aws.String("attribute_exists(Id)")
+	}
+	_, err = dbClient.UpdateItem(context.TODO(), input)
+
+	var conditionErr *types.ConditionalCheckFailedException
+	if err != nil && !errors.As(err, &conditionErr) {
+		return nil, storeError(err)
+	}
+	return d, nil
+}
+
+func GetDeadLetter(tenantID, id string) (*DeadLetter, error) {
+	result, err := dbClient.GetItem(context.TODO(), &dynamodb.GetItemInput{
+		TableName: aws.String(DeadLetterTable),
+		Key:       deadLetterKey(tenantID, id),
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	if result.Item == nil {
+		return nil, &ErrorNotFound{Kind: DeadLetterKind, Id: id}
+	}
+
+	d := &DeadLetter{}
+	err = attributevalue.UnmarshalMap(result.Item, d)
+	return d, err
+}
+
+// ListDeadLetters returns up to limit dead letters of a tenant, newest
+// first.
+func ListDeadLetters(tenantID string, limit int) ([]DeadLetter, error) {
+	paginator := dynamodb.NewQueryPaginator(dbClient, &dynamodb.QueryInput{
+		TableName:              aws.String(DeadLetterTable),
+		KeyConditionExpression: aws.String("TenantId = :tenantId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":tenantId": &types.AttributeValueMemberS{Value: tenantID},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int32(int32(limit)),
+	})
+
+	deadLetters := []DeadLetter{}
+	for paginator.HasMorePages() && len(deadLetters) < limit {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, storeError(err)
+		}
+
+		var items []DeadLetter
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &items); err != nil {
+			return nil, err
+		}
+		deadLetters = append(deadLetters, items...)
+	}
+
+	if len(deadLetters) > limit {
+		deadLetters = deadLetters[:limit]
+	}
+	return deadLetters, nil
+}
+
+// DeleteDeadLetter deletes a dead letter and returns it. It returns
+// ErrorNotFound when the tenant has no dead letter with this Id.
+func DeleteDeadLetter(tenantID, id string) (*DeadLetter, error) {
+	result, err := dbClient.DeleteItem(context.TODO(), &dynamodb.DeleteItemInput{
+		TableName:    aws.String(DeadLetterTable),
+		Key:          deadLetterKey(tenantID, id),
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	if result.Attributes == nil {
+		return nil, &ErrorNotFound{Kind: DeadLetterKind, Id: id}
+	}
+
+	d := &DeadLetter{}
+	err = attributevalue.UnmarshalMap(result.Attributes, d)
+	return d, err
+}
+
+func deadLetterKey(tenantID, id string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"TenantId": &types.AttributeValueMemberS{Value: tenantID},
+		"Id":       &types.AttributeValueMemberS{Value: id},
+	}
+}
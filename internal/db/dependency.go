@@ -0,0 +1,187 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// ResourceDependentTable maps every resource to the resources depending on
+// it, so they are published once it's ready.
+const ResourceDependentTable = "ResourceDependents"
+
+// Dependency holds the publishing of a resource until another resource of
+// its consumer is ready.
+type Dependency struct {
+	ResourceId string
+	// Condition that must be True, StatusMessageReconciled if empty.
+	Condition string
+}
+
+type resourceDependent struct {
+	DependencyId string
+	DependentId  string
+}
+
+// DependenciesReady tells whether every dependency of r reported its
+// condition set to True for its current generation. Missing, held and
+// deleted dependencies aren't ready.
+func DependenciesReady(r *Resource) (bool, error) {
+	for _, d := range r.DependsOn {
+		dependency, err := GetResource(r.TenantId, d.ResourceId)
+		var notFound *ErrorNotFound
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		if dependency.Held || dependency.Deleting ||
+			dependency.Status.ResourceGenerationID < dependency.ResourceGenerationID {
+			return false, nil
+		}
+		condition := d.Condition
+		if condition == "" {
+			condition = StatusMessageReconciled
+		}
+		if !meta.IsStatusConditionTrue(dependency.Status.ReconcileStatus.Conditions, condition) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// SetResourceDependencies replaces the dependencies of an existing
+// resource, previous being the dependencies it's stored with.
+func SetResourceDependencies(r *Resource, previous []Dependency) error {
+	dependsOn, err := attributevalue.Marshal(r.DependsOn)
+	if err != nil {
+		return err
+	}
+
+	_, err = dbClient.UpdateItem(context.TODO(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(ResourceTable),
+		Key: map[string]types.AttributeValue{
+			"Id": &types.AttributeValueMemberS{Value: r.Id},
+		},
+		UpdateExpression:    aws.String("SET DependsOn = :dependsOn"),
+		ConditionExpression: aws.String("attribute_exists(Id)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":dependsOn": dependsOn,
+		},
+	})
+
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return &ErrorNotFound{Kind: ResourceKind, Id: r.Id}
+	}
+	if err != nil {
+		return storeError(err)
+	}
+
+	current := map[string]bool{}
+	for _, d := range r.DependsOn {
+		current[d.ResourceId] = true
+	}
+	for _, d := range previous {
+		if current[d.ResourceId] {
+			continue
+		}
+		_, err := dbClient.DeleteItem(context.TODO(), &dynamodb.DeleteItemInput{
+			TableName: aws.String(ResourceDependentTable),
+			Key: map[string]types.AttributeValue{
+				"DependencyId": &types.AttributeValueMemberS{Value: d.ResourceId},
+				"DependentId":  &types.AttributeValueMemberS{Value: r.Id},
+			},
+		})
+		if err != nil {
+			return storeError(err)
+		}
+	}
+	return PutResourceDependents(r)
+}
+
+// PutResourceDependents records r as a dependent of its dependencies.
+func PutResourceDependents(r *Resource) error {
+	for _, d := range r.DependsOn {
+		item, err := attributevalue.MarshalMap(resourceDependent{DependencyId: d.ResourceId, DependentId: r.Id})
+		if err != nil {
+			return err
+		}
+
+		_, err = dbClient.PutItem(context.TODO(), &dynamodb.PutItemInput{
+			TableName: aws.String(ResourceDependentTable),
+			Item:      item,
+		})
+		if err != nil {
+			return storeError(err)
+		}
+	}
+	return nil
+}
+
+// ListResourceDependents returns the Ids of the resources depending on a
+// resource. Some may have been removed since.
+func ListResourceDependents(resourceID string) ([]string, error) {
+	paginator := dynamodb.NewQueryPaginator(dbClient, &dynamodb.QueryInput{
+		TableName:              aws.String(ResourceDependentTable),
+		KeyConditionExpression: aws.String("DependencyId = :id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":id": &types.AttributeValueMemberS{Value: resourceID},
+		},
+	})
+
+	var ids []string
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, storeError(err)
+		}
+
+		var items []resourceDependent
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &items); err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			ids = append(ids, item.DependentId)
+		}
+	}
+	return ids, nil
+}
+
+// ReleaseResource clears the Held flag of a resource, so its current
+// generation can be published. It returns false when the resource isn't
+// held at this generation anymore, e.g. after it was released by another
+// status report.
+func ReleaseResource(r *Resource) (bool, error) {
+	_, err := dbClient.UpdateItem(context.TODO(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(ResourceTable),
+		Key: map[string]types.AttributeValue{
+			"Id": &types.AttributeValueMemberS{Value: r.Id},
+		},
+		UpdateExpression:    aws.String("SET Held = :false"),
+		ConditionExpression: aws.String("Held = :true AND ResourceGenerationID = :generation"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":false":      &types.AttributeValueMemberBOOL{Value: false},
+			":true":       &types.AttributeValueMemberBOOL{Value: true},
+			":generation": &types.AttributeValueMemberN{Value: fmt.Sprint(r.ResourceGenerationID)},
+		},
+	})
+
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return false, nil
+	}
+	if err != nil {
+		return false, storeError(err)
+	}
+	r.Held = false
+	return true, nil
+}
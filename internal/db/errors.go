@@ -0,0 +1,210 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+)
+
+// The errors below are returned by the store and the API services. Each of
+// them implements GRPCStatus, so they can be returned from gRPC handlers as
+// they are: the gRPC server and the gateway pick up the code and details.
+
+// ErrorNotFound is returned when the Kind with the given Id isn't stored.
+type ErrorNotFound struct {
+	Kind string
+	Id   string
+}
+
+func (e *ErrorNotFound) Error() string {
+	return fmt.Sprintf("%s %q not found", kindOrDefault(e.Kind), e.Id)
+}
+
+func (e *ErrorNotFound) GRPCStatus() *status.Status {
+	return withDetails(status.New(codes.NotFound, e.Error()), &errdetails.ResourceInfo{
+		ResourceType: e.Kind,
+		ResourceName: e.Id,
+	})
+}
+
+// ErrorAlreadyExists is returned when creating a Kind whose Id is taken,
+// or when the Kind with the given Id conflicts with the one being created.
+type ErrorAlreadyExists struct {
+	Kind string
+	Id   string
+	// Optional, why the existing Kind conflicts.
+	Description string
+}
+
+func (e *ErrorAlreadyExists) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("%s %q already exists: %s", kindOrDefault(e.Kind), e.Id, e.Description)
+	}
+	return fmt.Sprintf("%s %q already exists", kindOrDefault(e.Kind), e.Id)
+}
+
+func (e *ErrorAlreadyExists) GRPCStatus() *status.Status {
+	return withDetails(status.New(codes.AlreadyExists, e.Error()), &errdetails.ResourceInfo{
+		ResourceType: e.Kind,
+		ResourceName: e.Id,
+		Description:  e.Description,
+	})
+}
+
+// ErrorFailedPrecondition is returned when the system isn't in the state
+// required by the call, e.g. a resource created for an unknown consumer.
+type ErrorFailedPrecondition struct {
+	// Type of the precondition, e.g. "CONSUMER".
+	Type string
+	// Subject the precondition was checked against, e.g. the consumer id.
+	Subject     string
+	Description string
+}
+
+func (e *ErrorFailedPrecondition) Error() string {
+	return e.Description
+}
+
+func (e *ErrorFailedPrecondition) GRPCStatus() *status.Status {
+	return withDetails(status.New(codes.FailedPrecondition, e.Error()), &errdetails.PreconditionFailure{
+		Violations: []*errdetails.PreconditionFailure_Violation{{
+			Type:        e.Type,
+			Subject:     e.Subject,
+			Description: e.Description,
+		}},
+	})
+}
+
+// ErrorAborted is returned when the Kind with the given Id changed since it
+// was read, so a read-modify-write would lose the concurrent change. The
+// call may be retried.
+type ErrorAborted struct {
+	Kind string
+	Id   string
+}
+
+func (e *ErrorAborted) Error() string {
+	return fmt.Sprintf("%s %q changed meanwhile, read it and try again", kindOrDefault(e.Kind), e.Id)
+}
+
+func (e *ErrorAborted) GRPCStatus() *status.Status {
+	return withDetails(status.New(codes.Aborted, e.Error()), &errdetails.ResourceInfo{
+		ResourceType: e.Kind,
+		ResourceName: e.Id,
+	})
+}
+
+// ErrorResourceExhausted is returned when a quota doesn't allow the call.
+type ErrorResourceExhausted struct {
+	// Subject of the quota, e.g. "tenant:team-a".
+	Subject     string
+	Description string
+}
+
+func (e *ErrorResourceExhausted) Error() string {
+	return e.Description
+}
+
+func (e *ErrorResourceExhausted) GRPCStatus() *status.Status {
+	return withDetails(status.New(codes.ResourceExhausted, e.Error()), &errdetails.QuotaFailure{
+		Violations: []*errdetails.QuotaFailure_Violation{{
+			Subject:     e.Subject,
+			Description: e.Description,
+		}},
+	})
+}
+
+// FieldViolation describes a single invalid field of a request.
+type FieldViolation struct {
+	// Path to the field, e.g. "object.metadata.name".
+	Field       string
+	Description string
+}
+
+// ErrorInvalidArgument is returned when a request fails validation.
+type ErrorInvalidArgument struct {
+	Violations []FieldViolation
+}
+
+func (e *ErrorInvalidArgument) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", v.Field, v.Description))
+	}
+	return fmt.Sprintf("invalid argument: %s", strings.Join(msgs, "; "))
+}
+
+func (e *ErrorInvalidArgument) GRPCStatus() *status.Status {
+	badRequest := &errdetails.BadRequest{}
+	for _, v := range e.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+	return withDetails(status.New(codes.InvalidArgument, e.Error()), badRequest)
+}
+
+// ErrorUnavailable is returned when the store can't be reached, or is
+// throttling requests. The call may be retried.
+type ErrorUnavailable struct {
+	Err error
+}
+
+func (e *ErrorUnavailable) Error() string {
+	return fmt.Sprintf("store unavailable: %v", e.Err)
+}
+
+func (e *ErrorUnavailable) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrorUnavailable) GRPCStatus() *status.Status {
+	return status.New(codes.Unavailable, e.Error())
+}
+
+// storeError classifies errors returned by the DynamoDB client. Errors that
+// never reached the service, and throttling errors, are retryable and
+// reported as ErrorUnavailable. Everything else is returned unchanged.
+func storeError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var throughputErr *types.ProvisionedThroughputExceededException
+	var limitErr *types.RequestLimitExceeded
+	if errors.As(err, &throughputErr) || errors.As(err, &limitErr) {
+		return &ErrorUnavailable{Err: err}
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return err
+	}
+
+	return &ErrorUnavailable{Err: err}
+}
+
+// withDetails attaches details to s. The status is returned without
+// details if they can't be encoded.
+func withDetails(s *status.Status, details ...protoiface.MessageV1) *status.Status {
+	detailed, err := s.WithDetails(details...)
+	if err != nil {
+		return s
+	}
+	return detailed
+}
+
+func kindOrDefault(kind string) string {
+	if kind == "" {
+		return "Resource"
+	}
+	return kind
+}
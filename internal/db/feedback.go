@@ -0,0 +1,16 @@
+package db
+
+const (
+	// FeedbackRuleWellKnownStatus selects the well-known status fields of
+	// the kind, see manifest.WellKnownStatusPaths.
+	FeedbackRuleWellKnownStatus = "WellKnownStatus"
+	// FeedbackRuleJSONPaths selects the JsonPaths of the rule.
+	FeedbackRuleJSONPaths = "JSONPaths"
+)
+
+// FeedbackRule selects the fields of the applied object reported back by
+// the agent, instead of its whole status.
+type FeedbackRule struct {
+	Type      string
+	JsonPaths []JsonPath
+}
@@ -0,0 +1,272 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const ResourceStatusHistoryTable = "ResourceStatusHistory"
+
+const (
+	statusHistoryMaxEntries = "STATUS_HISTORY_MAX_ENTRIES"
+	statusHistoryMaxAge     = "STATUS_HISTORY_MAX_AGE"
+)
+
+// HistoryRetention bounds the status history kept per resource, zero
+// values keep everything.
+type HistoryRetention struct {
+	MaxEntries int
+	MaxAge     time.Duration
+}
+
+// historyRetention is the retention of the status history, see
+// SetHistoryRetention.
+var historyRetention = HistoryRetention{
+	MaxEntries: 100,
+	MaxAge:     30 * 24 * time.Hour,
+}
+
+// pruneBatch bounds the entries dropped when an entry is appended: above
+// one, so histories beyond MaxEntries, e.g. after it was lowered, shrink
+// back to it.
+const pruneBatch = 10
+
+// NewHistoryRetention reads the retention of the status history from
+// STATUS_HISTORY_MAX_ENTRIES and STATUS_HISTORY_MAX_AGE, which default to
+// 100 entries and 30 days.
+func NewHistoryRetention() (HistoryRetention, error) {
+	retention := historyRetention
+	if v := os.Getenv(statusHistoryMaxEntries); v != "" {
+		maxEntries, err := strconv.Atoi(v)
+		if err != nil || maxEntries < 0 {
+			return HistoryRetention{}, fmt.Errorf("%s must be a non-negative integer, got %q", statusHistoryMaxEntries, v)
+		}
+		retention.MaxEntries = maxEntries
+	}
+	if v := os.Getenv(statusHistoryMaxAge); v != "" {
+		maxAge, err := time.ParseDuration(v)
+		if err != nil || maxAge < 0 {
+			return HistoryRetention{}, fmt.Errorf("%s must be a non-negative duration, got %q", statusHistoryMaxAge, v)
+		}
+		retention.MaxAge = maxAge
+	}
+	return retention, nil
+}
+
+// SetHistoryRetention sets the retention of the status history, 100
+// entries and 30 days until set.
+func SetHistoryRetention(retention HistoryRetention) {
+	historyRetention = retention
+}
+
+// StatusHistoryEntry records a status report that changed the generation
+// or the conditions of a resource.
+type StatusHistoryEntry struct {
+	ResourceId string
+	// Unix timestamp in nanoseconds at which the status was received.
+	Timestamp            int64
+	ResourceGenerationID int64
+	ObservedGeneration   int64
+	Transitions          []ConditionTransition
+	// Unix timestamp in seconds after which the entry is beyond the
+	// retention age, removed by the TTL of ResourceStatusHistoryTable.
+	ExpiresAt int64 `dynamodbav:",omitempty"`
+}
+
+// ConditionTransition is a change of the status or reason of a condition.
+type ConditionTransition struct {
+	Type string
+	// empty when the condition is new.
+	PreviousStatus string
+	// empty when the condition was removed.
+	Status             string
+	Reason             string
+	Message            string
+	LastTransitionTime string
+}
+
+// statusTransition returns the history entry for moving from the previous
+// to the current status, nil if nothing worth recording changed.
+func statusTransition(resourceID string, previous, current StatusMessage, at time.Time) *StatusHistoryEntry {
+	transitions := conditionTransitions(previous.ReconcileStatus.Conditions, current.ReconcileStatus.Conditions)
+	if len(transitions) == 0 && previous.ResourceGenerationID == current.ResourceGenerationID {
+		return nil
+	}
+
+	return &StatusHistoryEntry{
+		ResourceId:           resourceID,
+		Timestamp:            at.UnixNano(),
+		ResourceGenerationID: current.ResourceGenerationID,
+		ObservedGeneration:   current.ReconcileStatus.ObservedGeneration,
+		Transitions:          transitions,
+	}
+}
+
+func conditionTransitions(previous, current []metav1.Condition) []ConditionTransition {
+	var transitions []ConditionTransition
+	for _, c := range current {
+		p := findCondition(previous, c.Type)
+		if p != nil && p.Status == c.Status && p.Reason == c.Reason {
+			continue
+		}
+
+		t := ConditionTransition{
+			Type:    c.Type,
+			Status:  string(c.Status),
+			Reason:  c.Reason,
+			Message: c.Message,
+		}
+		if !c.LastTransitionTime.IsZero() {
+			t.LastTransitionTime = c.LastTransitionTime.UTC().Format(time.RFC3339)
+		}
+		if p != nil {
+			t.PreviousStatus = string(p.Status)
+		}
+		transitions = append(transitions, t)
+	}
+	for _, p := range previous {
+		if findCondition(current, p.Type) == nil {
+			transitions = append(transitions, ConditionTransition{
+				Type:           p.Type,
+				PreviousStatus: string(p.Status),
+			})
+		}
+	}
+	return transitions
+}
+
+func findCondition(conditions []metav1.Condition, conditionType string) *metav1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// AppendStatusHistory stores entry, then drops the oldest entries of the
+// resource beyond MaxEntries. Entries beyond MaxAge expire.
+func AppendStatusHistory(entry *StatusHistoryEntry) error {
+	if historyRetention.MaxAge > 0 {
+		entry.ExpiresAt = time.Unix(0, entry.Timestamp).Add(historyRetention.MaxAge).Unix()
+	}
+	item, err := attributevalue.MarshalMap(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = dbClient.PutItem(context.TODO(), &dynamodb.PutItemInput{
+		TableName: aws.String(ResourceStatusHistoryTable),
+		Item:      item,
+	})
+	if err != nil {
+		return storeError(err)
+	}
+
+	return pruneStatusHistory(entry.ResourceId)
+}
+
+// pruneStatusHistory drops up to pruneBatch of the oldest entries of a
+// resource beyond MaxEntries, reading no more than that many keys: the
+// newest MaxEntries set the cutoff, the oldest entries before it go.
+func pruneStatusHistory(resourceID string) error {
+	if historyRetention.MaxEntries == 0 {
+		return nil
+	}
+
+	newest, err := dbClient.Query(context.TODO(), &dynamodb.QueryInput{
+		TableName:                aws.String(ResourceStatusHistoryTable),
+		KeyConditionExpression:   aws.String("ResourceId = :id"),
+		ProjectionExpression:     aws.String("ResourceId, #ts"),
+		ExpressionAttributeNames: map[string]string{"#ts": "Timestamp"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":id": &types.AttributeValueMemberS{Value: resourceID},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int32(int32(historyRetention.MaxEntries)),
+	})
+	if err != nil {
+		return storeError(err)
+	}
+	if len(newest.Items) < historyRetention.MaxEntries {
+		return nil
+	}
+
+	oldest, err := dbClient.Query(context.TODO(), &dynamodb.QueryInput{
+		TableName:                aws.String(ResourceStatusHistoryTable),
+		KeyConditionExpression:   aws.String("ResourceId = :id AND #ts < :cutoff"),
+		ProjectionExpression:     aws.String("ResourceId, #ts"),
+		ExpressionAttributeNames: map[string]string{"#ts": "Timestamp"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":id":     &types.AttributeValueMemberS{Value: resourceID},
+			":cutoff": newest.Items[len(newest.Items)-1]["Timestamp"],
+		},
+		ScanIndexForward: aws.Bool(true),
+		Limit:            aws.Int32(pruneBatch),
+	})
+	if err != nil {
+		return storeError(err)
+	}
+	if len(oldest.Items) == 0 {
+		return nil
+	}
+
+	var deletes []types.WriteRequest
+	for _, item := range oldest.Items {
+		deletes = append(deletes, types.WriteRequest{DeleteRequest: &types.DeleteRequest{
+			Key: map[string]types.AttributeValue{
+				"ResourceId": item["ResourceId"],
+				"Timestamp":  item["Timestamp"],
+			},
+		}})
+	}
+	// the unprocessed entries are dropped with the next entry
+	_, err = dbClient.BatchWriteItem(context.TODO(), &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]types.WriteRequest{ResourceStatusHistoryTable: deletes},
+	})
+	return storeError(err)
+}
+
+// GetStatusHistory returns the status history of a resource within the
+// retention age, oldest first.
+func GetStatusHistory(resourceID string) ([]StatusHistoryEntry, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(ResourceStatusHistoryTable),
+		KeyConditionExpression: aws.String("ResourceId = :id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":id": &types.AttributeValueMemberS{Value: resourceID},
+		},
+	}
+	if historyRetention.MaxAge > 0 {
+		cutoff := time.Now().Add(-historyRetention.MaxAge).UnixNano()
+		input.KeyConditionExpression = aws.String("ResourceId = :id AND #ts >= :cutoff")
+		input.ExpressionAttributeNames = map[string]string{"#ts": "Timestamp"}
+		input.ExpressionAttributeValues[":cutoff"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(cutoff, 10)}
+	}
+
+	entries := []StatusHistoryEntry{}
+	paginator := dynamodb.NewQueryPaginator(dbClient, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, storeError(err)
+		}
+
+		var pageEntries []StatusHistoryEntry
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &pageEntries); err != nil {
+			return nil, err
+		}
+		entries = append(entries, pageEntries...)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,216 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewHistoryRetention(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxEntries string
+		maxAge     string
+		want       HistoryRetention
+		wantErr    bool
+	}{
+		{name: "defaults", want: HistoryRetention{MaxEntries: 100, MaxAge: 30 * 24 * time.Hour}},
+		{name: "set", maxEntries: "10", maxAge: "1h", want: HistoryRetention{MaxEntries: 10, MaxAge: time.Hour}},
+		{name: "unbounded", maxEntries: "0", maxAge: "0s", want: HistoryRetention{}},
+		{name: "negative entries", maxEntries: "-1", wantErr: true},
+		{name: "invalid entries", maxEntries: "ten", wantErr: true},
+		{name: "negative age", maxAge: "-1h", wantErr: true},
+		{name: "invalid age", maxAge: "30d", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(statusHistoryMaxEntries, tt.maxEntries)
+			t.Setenv(statusHistoryMaxAge, tt.maxAge)
+
+			got, err := NewHistoryRetention()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditionTransitions(t *testing.T) {
+	at := metav1.NewTime(time.Date(2023, 7, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*3600)))
+	applied := metav1.Condition{Type: "Applied", Status: metav1.ConditionTrue, Reason: "Applied", LastTransitionTime: at}
+	available := metav1.Condition{Type: "Available", Status: metav1.ConditionFalse, Reason: "Progressing", Message: "0/1 ready"}
+
+	tests := []struct {
+		name     string
+		previous []metav1.Condition
+		current  []metav1.Condition
+		want     []ConditionTransition
+	}{
+		{name: "none"},
+		{name: "unchanged", previous: []metav1.Condition{applied}, current: []metav1.Condition{applied}},
+		{
+			name:     "only the message changed",
+			previous: []metav1.Condition{available},
+			current:  []metav1.Condition{withMessage(available, "1/2 ready")},
+		},
+		{
+			name:    "new condition",
+			current: []metav1.Condition{applied},
+			want:    []ConditionTransition{{Type: "Applied", Status: "True", Reason: "Applied", LastTransitionTime: "2023-07-01T10:00:00Z"}},
+		},
+		{
+			name:     "status changed",
+			previous: []metav1.Condition{available},
+			current:  []metav1.Condition{{Type: "Available", Status: metav1.ConditionTrue, Reason: "Ready"}},
+			want:     []ConditionTransition{{Type: "Available", PreviousStatus: "False", Status: "True", Reason: "Ready"}},
+		},
+		{
+			name:     "reason changed",
+			previous: []metav1.Condition{available},
+			current:  []metav1.Condition{{Type: "Available", Status: metav1.ConditionFalse, Reason: "Failed", Message: "crash loop"}},
+			want:     []ConditionTransition{{Type: "Available", PreviousStatus: "False", Status: "False", Reason: "Failed", Message: "crash loop"}},
+		},
+		{
+			name:     "removed condition",
+			previous: []metav1.Condition{applied, available},
+			current:  []metav1.Condition{applied},
+			want:     []ConditionTransition{{Type: "Available", PreviousStatus: "False"}},
+		},
+		{
+			name:     "changed, new and removed",
+			previous: []metav1.Condition{available},
+			current:  []metav1.Condition{applied, {Type: "Available", Status: metav1.ConditionTrue, Reason: "Ready"}},
+			want: []ConditionTransition{
+				{Type: "Applied", Status: "True", Reason: "Applied", LastTransitionTime: "2023-07-01T10:00:00Z"},
+				{Type: "Available", PreviousStatus: "False", Status: "True", Reason: "Ready"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := conditionTransitions(tt.previous, tt.current)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusTransition(t *testing.T) {
+	at := time.Unix(1688212800, 5)
+	applied := []metav1.Condition{{Type: "Applied", Status: metav1.ConditionTrue, Reason: "Applied"}}
+
+	tests := []struct {
+		name     string
+		previous StatusMessage
+		current  StatusMessage
+		want     *StatusHistoryEntry
+	}{
+		{
+			name:     "nothing changed",
+			previous: statusMessage(1, 3, applied),
+			current:  statusMessage(1, 3, applied),
+		},
+		{
+			name:     "only the observed generation changed",
+			previous: statusMessage(1, 3, applied),
+			current:  statusMessage(1, 4, applied),
+		},
+		{
+			name:     "generation changed",
+			previous: statusMessage(1, 3, applied),
+			current:  statusMessage(2, 4, applied),
+			want:     &StatusHistoryEntry{ResourceId: "resource1", Timestamp: at.UnixNano(), ResourceGenerationID: 2, ObservedGeneration: 4},
+		},
+		{
+			name:    "conditions changed",
+			current: statusMessage(0, 1, applied),
+			want: &StatusHistoryEntry{
+				ResourceId:         "resource1",
+				Timestamp:          at.UnixNano(),
+				ObservedGeneration: 1,
+				Transitions:        []ConditionTransition{{Type: "Applied", Status: "True", Reason: "Applied"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := statusTransition("resource1", tt.previous, tt.current, at)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPruneStatusHistory(t *testing.T) {
+	tests := []struct {
+		name      string
+		retention HistoryRetention
+		stored    int
+		want      int
+	}{
+		{name: "within the retention", retention: HistoryRetention{MaxEntries: 5}, stored: 4, want: 5},
+		{name: "one beyond", retention: HistoryRetention{MaxEntries: 5}, stored: 5, want: 5},
+		{name: "a batch beyond", retention: HistoryRetention{MaxEntries: 5}, stored: 4 + pruneBatch, want: 5},
+		// the history shrinks back a batch per entry, e.g. after MaxEntries
+		// was lowered
+		{name: "more than a batch beyond", retention: HistoryRetention{MaxEntries: 5}, stored: 5 + 3*pruneBatch, want: 6 + 2*pruneBatch},
+		{name: "unbounded", stored: 50, want: 51},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := useFakeStore(t)
+			previous := historyRetention
+			SetHistoryRetention(tt.retention)
+			t.Cleanup(func() { SetHistoryRetention(previous) })
+
+			for i := 0; i < tt.stored; i++ {
+				s.put(ResourceStatusHistoryTable, mustMarshal(t, &StatusHistoryEntry{ResourceId: "resource1", Timestamp: int64(i + 1)}))
+			}
+			// entries of other resources are kept
+			s.put(ResourceStatusHistoryTable, mustMarshal(t, &StatusHistoryEntry{ResourceId: "resource2", Timestamp: 1}))
+
+			err := AppendStatusHistory(&StatusHistoryEntry{ResourceId: "resource1", Timestamp: int64(tt.stored + 1)})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			entries, err := GetStatusHistory("resource1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != tt.want {
+				t.Fatalf("got %d entries, want %d", len(entries), tt.want)
+			}
+			// the newest entries are kept
+			if last := entries[len(entries)-1].Timestamp; last != int64(tt.stored+1) {
+				t.Errorf("got newest entry at %d, want %d", last, tt.stored+1)
+			}
+			if first := entries[0].Timestamp; first != int64(tt.stored+2-tt.want) {
+				t.Errorf("got oldest entry at %d, want %d", first, tt.stored+2-tt.want)
+			}
+			if other, _ := GetStatusHistory("resource2"); len(other) != 1 {
+				t.Errorf("got %d entries of another resource, want 1", len(other))
+			}
+		})
+	}
+}
+
+func statusMessage(generation, observedGeneration int64, conditions []metav1.Condition) StatusMessage {
+	status := StatusMessage{}
+	status.ResourceGenerationID = generation
+	status.ReconcileStatus.ObservedGeneration = observedGeneration
+	status.ReconcileStatus.Conditions = conditions
+	return status
+}
+
+func withMessage(c metav1.Condition, message string) metav1.Condition {
+	c.Message = message
+	return c
+}
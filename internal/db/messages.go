@@ -1,6 +1,7 @@
 package db
 
 import (
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
@@ -9,10 +10,62 @@ type ResourceMessage struct {
 	MessageMeta `json:",inline"`
 
 	Id         string `json:"-"`
+	TenantId   string `json:"-"`
 	ConsumerId string `json:"-"`
+	// Compression supported by the consumer, the payload is published
+	// compressed with it.
+	Compression v1.Compression `json:"-"`
 
 	// Kubernetes Manifest to apply on the target.
 	Content *unstructured.Unstructured `json:"content"`
+
+	// JSONPaths to evaluate against the applied object.
+	// Their values MUST be reported in statusFeedback.
+	FeedbackRules []JsonPath `json:"feedbackRules,omitempty"`
+
+	// Encryption is set when string values of the content are encrypted,
+	// they're then prefixed with "enc:v2:", whose additional data binds
+	// them to the resource, object and field, or "enc:v1:", without
+	// additional data. The agent MUST decrypt them before applying the
+	// content.
+	Encryption *ContentEncryption `json:"encryption,omitempty"`
+
+	// Delete is set once the resource is deleted.
+	// The content MUST then be removed from the target,
+	// reporting the Deleted condition in the reconcileStatus.
+	Delete bool `json:"delete,omitempty"`
+}
+
+type ContentEncryption struct {
+	// "AES-256-GCM": an encrypted value is the base64 encoding of the
+	// 12 bytes nonce followed by the ciphertext of the JSON encoded value.
+	Algorithm string `json:"algorithm"`
+	// Key encrypting the values, encrypted with RSA-OAEP SHA-256 to the
+	// public key of the consumer.
+	WrappedKey []byte `json:"wrappedKey"`
+}
+
+type ResourceBundleMessage struct {
+	MessageMeta `json:",inline"`
+
+	Id         string `json:"-"`
+	TenantId   string `json:"-"`
+	ConsumerId string `json:"-"`
+	// Compression supported by the consumer, the payload is published
+	// compressed with it.
+	Compression v1.Compression `json:"-"`
+
+	// Kubernetes Manifests to apply on the target, in order.
+	Manifests []*unstructured.Unstructured `json:"manifests"`
+
+	// Encryption is set when string values of the manifests are
+	// encrypted, as for the content of resources.
+	Encryption *ContentEncryption `json:"encryption,omitempty"`
+
+	// Delete is set once the bundle is deleted.
+	// Every manifest MUST then be removed from the target,
+	// reporting the Deleted condition in the bundle reconcileStatus.
+	Delete bool `json:"delete,omitempty"`
 }
 
 type StatusMessage struct {
@@ -21,6 +74,25 @@ type StatusMessage struct {
 	ReconcileStatus ReconcileStatus `json:"reconcileStatus"`
 	// content status as observed on the target.
 	ContentStatus map[string]interface{} `json:"contentStatus"`
+	// values of the feedbackRules as observed on the target.
+	StatusFeedback []FeedbackValue `json:"statusFeedback,omitempty"`
+}
+
+type ResourceBundleStatusMessage struct {
+	MessageMeta `json:",inline"`
+	// agent status information about the bundle as a whole.
+	ReconcileStatus ReconcileStatus `json:"reconcileStatus"`
+	// status of each manifest of the bundle.
+	ManifestStatuses []ManifestStatus `json:"manifestStatuses"`
+}
+
+type ManifestStatus struct {
+	// Position of the manifest in the bundle manifests.
+	Ordinal int `json:"ordinal"`
+	// agent status information.
+	ReconcileStatus ReconcileStatus `json:"reconcileStatus"`
+	// content status as observed on the target.
+	ContentStatus map[string]interface{} `json:"contentStatus,omitempty"`
 }
 
 const (
@@ -34,6 +106,35 @@ const (
 	StatusMessageDeleted = "Deleted"
 )
 
+type JsonPath struct {
+	Name string `json:"name"`
+	// Kubernetes JSONPath, e.g. ".status.readyReplicas".
+	Path string `json:"path"`
+}
+
+type FeedbackValue struct {
+	Name  string     `json:"name"`
+	Value FieldValue `json:"fieldValue"`
+}
+
+const (
+	FieldValueInteger = "Integer"
+	FieldValueString  = "String"
+	FieldValueBoolean = "Boolean"
+	FieldValueJsonRaw = "JsonRaw"
+)
+
+type FieldValue struct {
+	// One of Integer, String, Boolean or JsonRaw,
+	// telling which of the other fields is set.
+	Type    string  `json:"type"`
+	Integer *int64  `json:"integer,omitempty"`
+	String  *string `json:"string,omitempty"`
+	Boolean *bool   `json:"boolean,omitempty"`
+	// JSON encoding of objects, arrays and other values.
+	JsonRaw *string `json:"jsonRaw,omitempty"`
+}
+
 type ReconcileStatus struct {
 	// MAY when object exists/
 	// Object generation as observed on the target.
@@ -51,9 +152,16 @@ type ReconcileStatus struct {
 type MessageMeta struct {
 	// Unix Timestamp (UTC) at which time
 	// the message was sent to the broker.
+	// Set by the sender when the message is published.
 	SentTimestamp int64 `json:"sentTimestamp"`
 
-	// Server-side opaque corelation ID.
+	// Generation of the content, as stored and returned by the API.
+	// It increases with every change of the resource.
 	// MUST be passed back in status responses unchanged.
 	ResourceGenerationID int64 `json:"resourceGenerationID"`
+
+	// Hash of the normalized content, "sha256:<hex>", or "hmac-sha256:<hex>"
+	// keyed by the KMS when encryption is configured.
+	// Agents MAY skip applying content whose hash they already applied.
+	ContentHash string `json:"contentHash,omitempty"`
 }
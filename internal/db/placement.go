@@ -0,0 +1,315 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const PlacementTable = "Placements"
+
+// PlacementKind names placements in errors.
+const PlacementKind = "Placement"
+
+const (
+	// RolloutAllAtOnce updates every target at once.
+	RolloutAllAtOnce = "AllAtOnce"
+	// RolloutRolling updates MaxConcurrency targets at a time.
+	RolloutRolling = "Rolling"
+	// RolloutCanary updates the targets matching CanarySelector first.
+	RolloutCanary = "Canary"
+)
+
+const (
+	// RolloutOnFailurePause stops updating targets.
+	RolloutOnFailurePause = "Pause"
+	// RolloutOnFailureRollback restores PreviousObject on every target.
+	RolloutOnFailureRollback = "Rollback"
+)
+
+const (
+	RolloutProgressing = "Progressing"
+	RolloutComplete    = "Complete"
+	RolloutPaused      = "Paused"
+	RolloutRolledBack  = "RolledBack"
+)
+
+// Placement creates a resource of Object for every consumer of its tenant
+// matching ConsumerSelector.
+type Placement struct {
+	TenantId             string
+	Id                   string
+	ConsumerSelector     string
+	Object               unstructured.Unstructured
+	ResourceGenerationID int64
+	// Targets are the matching consumers by Id.
+	Targets map[string]PlacementTarget
+	Rollout RolloutStrategy
+	// RolloutPhase of ResourceGenerationID, and why it paused or rolled
+	// back.
+	RolloutPhase   string
+	RolloutMessage string
+	// PreviousObject is the Object of the previous generation, restored
+	// when the rollout is rolled back. Nil for the first generation.
+	PreviousObject *unstructured.Unstructured
+	// Encryption is the data key of the encrypted values of Object and
+	// PreviousObject, nil when none is encrypted.
+	Encryption *Encryption
+	// ResumedFailures are the consumers whose failure no longer counts
+	// towards Rollout.MaxFailures, after the rollout was resumed.
+	ResumedFailures []string
+	// Template is set when Object is a template, rendered for every
+	// consumer with its labels overridden by its ConsumerValues.
+	Template       bool
+	ConsumerValues map[string]map[string]string
+	// Deleting is set once the placement is deleted, until the resources
+	// of its targets are deleted.
+	Deleting bool
+}
+
+// RolloutStrategy publishes the generations of a placement to its targets
+// in batches.
+type RolloutStrategy struct {
+	Type           string
+	MaxConcurrency int32
+	CanarySelector string
+	MaxFailures    int32
+	OnFailure      string
+}
+
+// PlacementTarget is the resource of a placement on a consumer.
+type PlacementTarget struct {
+	// empty when the resource couldn't be created.
+	ResourceId string
+	// why the resource couldn't be created or updated.
+	Error string
+	// Generation of the placement last rolled out to the target.
+	Generation int64
+}
+
+// CreatePlacement stores a new placement in its tenant.
+func CreatePlacement(p *Placement) error {
+	item, err := attributevalue.MarshalMap(p)
+	if err != nil {
+		return err
+	}
+
+	_, err = dbClient.PutItem(context.TODO(), &dynamodb.PutItemInput{
+		TableName:           aws.String(PlacementTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(Id)"),
+	})
+
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return &ErrorAlreadyExists{Kind: PlacementKind, Id: p.Id}
+	}
+	return storeError(err)
+}
+
+// UpdatePlacement replaces the selector, objects, template values, rollout
+// strategy and rollout state of an existing placement, keeping its targets. It returns
+// ErrorNotFound when its tenant has no placement with the same Id, and
+// ErrorFailedPrecondition when the placement is being deleted.
+func UpdatePlacement(p *Placement) error {
+	item, err := attributevalue.MarshalMap(p)
+	if err != nil {
+		return err
+	}
+
+	_, err = dbClient.UpdateItem(context.TODO(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(PlacementTable),
+		Key:       placementKey(p.TenantId, p.Id),
+		UpdateExpression: aws.String("SET ConsumerSelector = :selector, #object = :object, ResourceGenerationID = :generation, " +
+			"Rollout = :rollout, RolloutPhase = :phase, RolloutMessage = :message, PreviousObject = :previous, ResumedFailures = :resumed, " +
+			"#template = :template, ConsumerValues = :values, Encryption = :encryption"),
+		ConditionExpression: aws.String("attribute_exists(Id) AND NOT Deleting = :true"),
+		ExpressionAttributeNames: map[string]string{
+			"#object":   "Object",
+			"#template": "Template",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":selector":   item["ConsumerSelector"],
+			":object":     item["Object"],
+			":generation": item["ResourceGenerationID"],
+			":rollout":    item["Rollout"],
+			":phase":      item["RolloutPhase"],
+			":message":    item["RolloutMessage"],
+			":previous":   item["PreviousObject"],
+			":resumed":    item["ResumedFailures"],
+			":template":   item["Template"],
+			":values":     item["ConsumerValues"],
+			":encryption": item["Encryption"],
+			":true":       &types.AttributeValueMemberBOOL{Value: true},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		if conditionErr.Item == nil {
+			return &ErrorNotFound{Kind: PlacementKind, Id: p.Id}
+		}
+		return PlacementDeletingError(p.Id)
+	}
+	return storeError(err)
+}
+
+// PlacementDeletingError is returned by the calls changing a placement
+// being deleted.
+func PlacementDeletingError(placementID string) error {
+	return &ErrorFailedPrecondition{
+		Type:        "DELETING",
+		Subject:     placementID,
+		Description: fmt.Sprintf("placement %q is being deleted", placementID),
+	}
+}
+
+// SetPlacementTargets replaces the targets and the rollout state of a
+// placement, after a reconciliation of generation. It returns
+// ErrorFailedPrecondition when the placement was updated to another
+// generation in the meantime, and ErrorNotFound when it was deleted.
+func SetPlacementTargets(p *Placement, generation int64) error {
+	item, err := attributevalue.MarshalMap(p)
+	if err != nil {
+		return err
+	}
+
+	// a rollback stores the restored object as a new generation, it's
+	// encrypted with the same data key as the object
+	_, err = dbClient.UpdateItem(context.TODO(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(PlacementTable),
+		Key:       placementKey(p.TenantId, p.Id),
+		UpdateExpression: aws.String("SET Targets = :targets, #object = :object, ResourceGenerationID = :generation, " +
+			"RolloutPhase = :phase, RolloutMessage = :message"),
+		ConditionExpression: aws.String("attribute_exists(Id) AND ResourceGenerationID = :reconciled"),
+		ExpressionAttributeNames: map[string]string{
+			"#object": "Object",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":targets":    item["Targets"],
+			":object":     item["Object"],
+			":generation": item["ResourceGenerationID"],
+			":phase":      item["RolloutPhase"],
+			":message":    item["RolloutMessage"],
+			":reconciled": &types.AttributeValueMemberN{Value: fmt.Sprint(generation)},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		if conditionErr.Item == nil {
+			return &ErrorNotFound{Kind: PlacementKind, Id: p.Id}
+		}
+		return &ErrorFailedPrecondition{
+			Type:        "GENERATION",
+			Subject:     p.Id,
+			Description: fmt.Sprintf("placement %q was updated during its reconciliation", p.Id),
+		}
+	}
+	return storeError(err)
+}
+
+func GetPlacement(tenantID, placementID string) (*Placement, error) {
+	result, err := dbClient.GetItem(context.TODO(), &dynamodb.GetItemInput{
+		TableName: aws.String(PlacementTable),
+		Key:       placementKey(tenantID, placementID),
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	if result.Item == nil {
+		return nil, &ErrorNotFound{Kind: PlacementKind, Id: placementID}
+	}
+
+	p := &Placement{}
+	err = attributevalue.UnmarshalMap(result.Item, p)
+	return p, err
+}
+
+// ListPlacements returns the placements of a tenant, ordered by Id.
+func ListPlacements(tenantID string) ([]*Placement, error) {
+	paginator := dynamodb.NewQueryPaginator(dbClient, &dynamodb.QueryInput{
+		TableName:              aws.String(PlacementTable),
+		KeyConditionExpression: aws.String("TenantId = :tenantId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":tenantId": &types.AttributeValueMemberS{Value: tenantID},
+		},
+	})
+
+	var placements []*Placement
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, storeError(err)
+		}
+
+		var items []*Placement
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &items); err != nil {
+			return nil, err
+		}
+		placements = append(placements, items...)
+	}
+	return placements, nil
+}
+
+// SetPlacementDeleting marks a placement as being deleted and returns it.
+// It returns ErrorNotFound when the tenant has no placement with this Id.
+func SetPlacementDeleting(tenantID, placementID string) (*Placement, error) {
+	result, err := dbClient.UpdateItem(context.TODO(), &dynamodb.UpdateItemInput{
+		TableName:           aws.String(PlacementTable),
+		Key:                 placementKey(tenantID, placementID),
+		UpdateExpression:    aws.String("SET Deleting = :true"),
+		ConditionExpression: aws.String("attribute_exists(Id)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":true": &types.AttributeValueMemberBOOL{Value: true},
+		},
+		ReturnValues: types.ReturnValueAllNew,
+	})
+
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return nil, &ErrorNotFound{Kind: PlacementKind, Id: placementID}
+	}
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	p := &Placement{}
+	err = attributevalue.UnmarshalMap(result.Attributes, p)
+	return p, err
+}
+
+// DeletePlacement removes a placement being deleted, once the resources of
+// its targets are deleted. Placements already removed are ignored.
+func DeletePlacement(tenantID, placementID string) error {
+	_, err := dbClient.DeleteItem(context.TODO(), &dynamodb.DeleteItemInput{
+		TableName:           aws.String(PlacementTable),
+		Key:                 placementKey(tenantID, placementID),
+		ConditionExpression: aws.String("Deleting = :true"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":true": &types.AttributeValueMemberBOOL{Value: true},
+		},
+	})
+
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return nil
+	}
+	return storeError(err)
+}
+
+func placementKey(tenantID, placementID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"TenantId": &types.AttributeValueMemberS{Value: tenantID},
+		"Id":       &types.AttributeValueMemberS{Value: placementID},
+	}
+}
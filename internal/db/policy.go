@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const PolicyTable = "Policies"
+
+// PolicyKind names policies in errors.
+const PolicyKind = "Policy"
+
+// Policy is a CEL expression the objects of the resources of a tenant must
+// satisfy.
+type Policy struct {
+	TenantId   string
+	Id         string
+	Expression string
+	Message    string
+}
+
+// CreatePolicy stores a new policy in its tenant. It returns
+// ErrorAlreadyExists when the tenant already has a policy with the same Id.
+func CreatePolicy(p *Policy) error {
+	return putPolicy(p, "attribute_not_exists(Id)", &ErrorAlreadyExists{Kind: PolicyKind, Id: p.Id})
+}
+
+// UpdatePolicy replaces an existing policy. It returns ErrorNotFound when
+// its tenant has no policy with the same Id.
+func UpdatePolicy(p *Policy) error {
+	return putPolicy(p, "attribute_exists(Id)", &ErrorNotFound{Kind: PolicyKind, Id: p.Id})
+}
+
+// putPolicy stores p when condition holds, returning conditionErr
+// otherwise.
+func putPolicy(p *Policy, condition string, conditionErr error) error {
+	item, err := attributevalue.MarshalMap(p)
+	if err != nil {
+		return err
+	}
+
+	_, err = dbClient.PutItem(context.TODO(), &dynamodb.PutItemInput{
+		TableName:           aws.String(PolicyTable),
+		Item:                item,
+		ConditionExpression: aws.String(condition),
+	})
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return conditionErr
+	}
+	return storeError(err)
+}
+
+func GetPolicy(tenantID, policyID string) (*Policy, error) {
+	result, err := dbClient.GetItem(context.TODO(), &dynamodb.GetItemInput{
+		TableName: aws.String(PolicyTable),
+		Key:       policyKey(tenantID, policyID),
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	if result.Item == nil {
+		return nil, &ErrorNotFound{Kind: PolicyKind, Id: policyID}
+	}
+
+	p := &Policy{}
+	err = attributevalue.UnmarshalMap(result.Item, p)
+	return p, err
+}
+
+// ListPolicies returns the policies of a tenant, ordered by Id.
+func ListPolicies(tenantID string) ([]Policy, error) {
+	paginator := dynamodb.NewQueryPaginator(dbClient, &dynamodb.QueryInput{
+		TableName:              aws.String(PolicyTable),
+		KeyConditionExpression: aws.String("TenantId = :tenantId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":tenantId": &types.AttributeValueMemberS{Value: tenantID},
+		},
+	})
+
+	var policies []Policy
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, storeError(err)
+		}
+
+		var items []Policy
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &items); err != nil {
+			return nil, err
+		}
+		policies = append(policies, items...)
+	}
+	return policies, nil
+}
+
+// DeletePolicy deletes a policy and returns it. It returns ErrorNotFound
+// when the tenant has no policy with this Id.
+func DeletePolicy(tenantID, policyID string) (*Policy, error) {
+	result, err := dbClient.DeleteItem(context.TODO(), &dynamodb.DeleteItemInput{
+		TableName:    aws.String(PolicyTable),
+		Key:          policyKey(tenantID, policyID),
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	if result.Attributes == nil {
+		return nil, &ErrorNotFound{Kind: PolicyKind, Id: policyID}
+	}
+
+	p := &Policy{}
+	err = attributevalue.UnmarshalMap(result.Attributes, p)
+	return p, err
+}
+
+func policyKey(tenantID, policyID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"TenantId": &types.AttributeValueMemberS{Value: tenantID},
+		"Id":       &types.AttributeValueMemberS{Value: policyID},
+	}
+}
@@ -2,42 +2,365 @@ package db
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 const ResourceTable = "Resources"
 
+// ResourceKeyTable maps the TargetKey of every resource, and of every
+// manifest of the resource bundles, to its Id, so a target object is
+// managed by a single resource or bundle.
+const ResourceKeyTable = "ResourceKeys"
+
+// ResourceKind names resources in errors.
+const ResourceKind = "Resource"
+
 type Resource struct {
-	Id                   string
-	ConsumerId           string
+	Id string
+	// Tenant of the resource and its consumer, empty for resources stored
+	// before tenants, which belong to DefaultTenant.
+	TenantId   string
+	ConsumerId string
+	// ResourceGenerationID is assigned by the store: 1 on creation, then
+	// incremented by every update. It is published as is to the agent,
+	// which reports it back in Status.
 	ResourceGenerationID int64
 	Object               unstructured.Unstructured
 	Status               StatusMessage
+	// Hash of the normalized Object, see manifest.ContentHash, keyed by the
+	// KMS when encryption is configured.
+	ContentHash   string
+	FeedbackRules []FeedbackRule
+	// Placement managing the resource, and the generation of the placement
+	// its Object was last set from. Empty for resources created directly.
+	PlacementId         string
+	PlacementGeneration int64
+	// Deleting is set once the resource is deleted, until the agent
+	// reports its object is removed from the target.
+	Deleting bool
+	// Template Object was rendered from, nil for objects submitted as they
+	// are.
+	Template *ResourceTemplate
+	// TemplateConsumer keys templated resources in TemplateIndex by tenant
+	// and consumer, see ListTemplatedResources. It's set by the store.
+	TemplateConsumer string `dynamodbav:",omitempty"`
+	// DependsOn are the resources of the consumer that must be ready before
+	// Object is published. Held is set while the current generation isn't
+	// published for that reason.
+	DependsOn []Dependency
+	Held      bool
+	// Envelope of the data key encrypting the sensitive values of Object
+	// and Template, nil when none is encrypted.
+	Encryption *Encryption
+	// ConsumerCounted is set once the resource counts in the ResourceCount
+	// of its consumer, see CountConsumerResources.
+	ConsumerCounted bool `dynamodbav:",omitempty"`
+}
+
+// Encryption holds the data key encrypting values, wrapped by the key
+// KeyId of the KMS.
+type Encryption struct {
+	KeyId      string
+	WrappedKey []byte
+}
+
+// ResourceTemplate is rendered into the Object of a resource with the
+// labels of its consumer, overridden by Values. See manifest.Render.
+type ResourceTemplate struct {
+	Object unstructured.Unstructured
+	Values map[string]string
+}
+
+// TargetKey identifies the object a resource manages on its consumer.
+// It doesn't depend on the version, so a resource can move to another
+// version of its kind.
+type TargetKey struct {
+	TenantId   string
+	ConsumerId string
+	Group      string
+	Kind       string
+	Namespace  string
+	Name       string
+}
+
+// TargetKeyOf returns the TargetKey of the object managed by r.
+func TargetKeyOf(r *Resource) TargetKey {
+	return targetKeyOf(TenantOrDefault(r.TenantId), r.ConsumerId, &r.Object)
+}
+
+func targetKeyOf(tenantID, consumerID string, obj *unstructured.Unstructured) TargetKey {
+	gv, _ := schema.ParseGroupVersion(obj.GetAPIVersion())
+	return TargetKey{
+		TenantId:   tenantID,
+		ConsumerId: consumerID,
+		Group:      gv.Group,
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+}
+
+// clusterScoped tells whether the objects of a kind have no namespace, see
+// SetClusterScoped.
+var clusterScoped = func(schema.GroupKind) bool { return false }
+
+// SetClusterScoped sets how the kinds without namespace are told apart.
+// The objects of the other kinds without namespace are applied in the
+// default namespace, so their TargetKey is the one of that namespace.
+// Every kind is namespaced until it's set.
+func SetClusterScoped(f func(gk schema.GroupKind) bool) {
+	clusterScoped = f
+}
+
+// String returns the key of the target in ResourceKeyTable. The keys of
+// DefaultTenant have no tenant, as the keys stored before tenants.
+func (k TargetKey) String() string {
+	namespace := k.Namespace
+	if namespace == "" && !clusterScoped(schema.GroupKind{Group: k.Group, Kind: k.Kind}) {
+		namespace = metav1.NamespaceDefault
+	}
+	if k.TenantId == DefaultTenant {
+		return strings.Join([]string{k.ConsumerId, k.Group, k.Kind, namespace, k.Name}, "/")
+	}
+	return strings.Join([]string{k.TenantId, k.ConsumerId, k.Group, k.Kind, namespace, k.Name}, "/")
+}
+
+// resourceKey is an item of ResourceKeyTable, owned either by a resource or
+// by a resource bundle.
+type resourceKey struct {
+	Key        string
+	ResourceId string `dynamodbav:",omitempty"`
+	BundleId   string `dynamodbav:",omitempty"`
 }
 
-func PutResource(r *Resource) error {
-	jsonBytes, err := attributevalue.MarshalMap(r)
+// CreateResource stores a new resource at generation 1. It returns
+// ErrorAlreadyExists, naming the owning resource, when another resource of
+// the same consumer already manages the target object, and
+// ErrorResourceExhausted when the tenant reached its quota or the consumer
+// its maxResources, 0 meaning no limit.
+func CreateResource(r *Resource, maxResources int64) error {
+	r.ResourceGenerationID = 1
+	r.TemplateConsumer = templateConsumerOf(r)
+	r.ConsumerCounted = true
+	resourceItem, err := attributevalue.MarshalMap(r)
 	if err != nil {
 		return err
 	}
 
-	_, err = dbClient.PutItem(
-		context.TODO(),
-		&dynamodb.PutItemInput{
-			TableName: aws.String(ResourceTable),
-			Item:      jsonBytes,
-		})
+	keyItem, err := attributevalue.MarshalMap(resourceKey{Key: TargetKeyOf(r).String(), ResourceId: r.Id})
+	if err != nil {
+		return err
+	}
+
+	_, err = dbClient.TransactWriteItems(context.TODO(), &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Put: putKey(keyItem)},
+			{Put: &types.Put{
+				TableName:           aws.String(ResourceTable),
+				Item:                resourceItem,
+				ConditionExpression: aws.String("attribute_not_exists(Id)"),
+			}},
+			{Update: reserveQuota(r.TenantId, resourceQuota)},
+			{Update: reserveConsumerResource(r.TenantId, r.ConsumerId, maxResources)},
+		},
+	})
+
+	if quotaErr := quotaError(err, 2, r.TenantId, resourceQuota); quotaErr != nil {
+		return quotaErr
+	}
+	if quotaErr := consumerQuotaError(err, 3, r.ConsumerId, maxResources); quotaErr != nil {
+		return quotaErr
+	}
+	return keyConflictError(err, r)
+}
+
+// UpdateResource replaces an existing resource with its next generation,
+// moving its TargetKey from previous to the key of its new object when they
+// differ. r must be at the stored generation, its ResourceGenerationID is
+// incremented once stored. It returns ErrorFailedPrecondition when the
+// resource was updated in the meantime, and ErrorNotFound when it was
+// removed.
+func UpdateResource(r *Resource, previous TargetKey) error {
+	next := *r
+	next.ResourceGenerationID++
+	next.TemplateConsumer = templateConsumerOf(r)
+	resourceItem, err := attributevalue.MarshalMap(&next)
+	if err != nil {
+		return err
+	}
+
+	condition := aws.String("ResourceGenerationID = :generation")
+	values := map[string]types.AttributeValue{
+		":generation": &types.AttributeValueMemberN{Value: fmt.Sprint(r.ResourceGenerationID)},
+	}
+
+	key := TargetKeyOf(r)
+	if key == previous {
+		_, err = dbClient.PutItem(
+			context.TODO(),
+			&dynamodb.PutItemInput{
+				TableName:                           aws.String(ResourceTable),
+				Item:                                resourceItem,
+				ConditionExpression:                 condition,
+				ExpressionAttributeValues:           values,
+				ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+			})
+
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return generationError(r, conditionErr.Item != nil)
+		}
+		if err != nil {
+			return storeError(err)
+		}
+		r.ResourceGenerationID = next.ResourceGenerationID
+		return nil
+	}
+
+	keyItem, err := attributevalue.MarshalMap(resourceKey{Key: key.String(), ResourceId: r.Id})
+	if err != nil {
+		return err
+	}
+
+	_, err = dbClient.TransactWriteItems(context.TODO(), &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Put: putKey(keyItem)},
+			{Put: &types.Put{
+				TableName:                           aws.String(ResourceTable),
+				Item:                                resourceItem,
+				ConditionExpression:                 condition,
+				ExpressionAttributeValues:           values,
+				ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+			}},
+			{Delete: &types.Delete{
+				TableName: aws.String(ResourceKeyTable),
+				Key: map[string]types.AttributeValue{
+					"Key": &types.AttributeValueMemberS{Value: previous.String()},
+				},
+				// resources stored before keys were tracked have no key yet
+				ConditionExpression: aws.String("attribute_not_exists(#key) OR ResourceId = :id"),
+				ExpressionAttributeNames: map[string]string{
+					"#key": "Key",
+				},
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":id": &types.AttributeValueMemberS{Value: r.Id},
+				},
+			}},
+		},
+	})
+
+	var cancelled *types.TransactionCanceledException
+	if errors.As(err, &cancelled) && len(cancelled.CancellationReasons) > 1 &&
+		aws.ToString(cancelled.CancellationReasons[1].Code) == "ConditionalCheckFailed" {
+		return generationError(r, cancelled.CancellationReasons[1].Item != nil)
+	}
+	if err != nil {
+		return keyConflictError(err, r)
+	}
+	r.ResourceGenerationID = next.ResourceGenerationID
+	return nil
+}
+
+// generationError is the error of an update of r whose generation
+// condition failed, exists telling whether the resource is still stored.
+func generationError(r *Resource, exists bool) error {
+	if !exists {
+		return &ErrorNotFound{Kind: ResourceKind, Id: r.Id}
+	}
+	return &ErrorFailedPrecondition{
+		Type:        "GENERATION",
+		Subject:     r.Id,
+		Description: fmt.Sprintf("resource %q was updated after generation %d", r.Id, r.ResourceGenerationID),
+	}
+}
+
+// SetResourcePlacementGeneration records that the object of a resource is
+// the object of its placement at PlacementGeneration, when updating it to
+// that generation didn't change the object.
+func SetResourcePlacementGeneration(r *Resource) error {
+	_, err := dbClient.UpdateItem(context.TODO(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(ResourceTable),
+		Key: map[string]types.AttributeValue{
+			"Id": &types.AttributeValueMemberS{Value: r.Id},
+		},
+		UpdateExpression:    aws.String("SET PlacementGeneration = :generation"),
+		ConditionExpression: aws.String("attribute_exists(Id)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":generation": &types.AttributeValueMemberN{Value: fmt.Sprint(r.PlacementGeneration)},
+		},
+	})
+
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return &ErrorNotFound{Kind: ResourceKind, Id: r.Id}
+	}
+	return storeError(err)
+}
+
+func putKey(keyItem map[string]types.AttributeValue) *types.Put {
+	return &types.Put{
+		TableName:                           aws.String(ResourceKeyTable),
+		Item:                                keyItem,
+		ConditionExpression:                 aws.String("attribute_not_exists(#key)"),
+		ExpressionAttributeNames:            map[string]string{"#key": "Key"},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}
+}
 
-	return err
+// keyConflictError turns a cancelled transaction whose key Put, always the
+// first item, failed its condition into ErrorAlreadyExists.
+func keyConflictError(err error, r *Resource) error {
+	var cancelled *types.TransactionCanceledException
+	if !errors.As(err, &cancelled) || len(cancelled.CancellationReasons) == 0 {
+		return storeError(err)
+	}
+
+	reason := cancelled.CancellationReasons[0]
+	if aws.ToString(reason.Code) != "ConditionalCheckFailed" {
+		return storeError(err)
+	}
+
+	return keyOwnerError(reason, TargetKeyOf(r))
 }
 
-func GetResource(resourceID string) (*Resource, error) {
+// keyOwnerError returns ErrorAlreadyExists naming the resource or bundle
+// owning key, the item of a failed key Put.
+func keyOwnerError(reason types.CancellationReason, key TargetKey) error {
+	owner := resourceKey{}
+	if err := attributevalue.UnmarshalMap(reason.Item, &owner); err != nil {
+		return err
+	}
+
+	if owner.BundleId != "" {
+		return &ErrorAlreadyExists{
+			Kind:        ResourceBundleKind,
+			Id:          owner.BundleId,
+			Description: "it manages the same object " + key.String(),
+		}
+	}
+	return &ErrorAlreadyExists{
+		Kind:        ResourceKind,
+		Id:          owner.ResourceId,
+		Description: "it manages the same object " + key.String(),
+	}
+}
+
+// GetResource returns a resource of the tenant, ErrorNotFound if it
+// belongs to another tenant.
+func GetResource(tenantID, resourceID string) (*Resource, error) {
 	getItemInput := &dynamodb.GetItemInput{
 		Key: map[string]types.AttributeValue{
 			"Id": &types.AttributeValueMemberS{Value: resourceID},
@@ -49,24 +372,159 @@ func GetResource(resourceID string) (*Resource, error) {
 
 	result, err := dbClient.GetItem(context.TODO(), getItemInput)
 	if err != nil {
-		return nil, err
+		return nil, storeError(err)
 	}
 
 	if result.Item == nil {
-		return nil, &ErrorNotFound{}
+		return nil, &ErrorNotFound{Kind: ResourceKind, Id: resourceID}
 	}
 
 	err = attributevalue.UnmarshalMap(result.Item, &r)
-	return &r, err
+	if err != nil {
+		return nil, err
+	}
+
+	if TenantOrDefault(r.TenantId) != tenantID {
+		return nil, &ErrorNotFound{Kind: ResourceKind, Id: resourceID}
+	}
+	// items stored before tenants get their tenant on the next update
+	r.TenantId = tenantID
+	return &r, nil
 }
 
-func SetStatusResource(resourceID string, statusData []byte) error {
-	var status map[string]interface{}
-	if err := json.Unmarshal(statusData, &status); err != nil {
-		return err
+// GetResourceByTargetKey returns the resource managing the object
+// identified by key.
+func GetResourceByTargetKey(key TargetKey) (*Resource, error) {
+	getItemInput := &dynamodb.GetItemInput{
+		Key: map[string]types.AttributeValue{
+			"Key": &types.AttributeValueMemberS{Value: key.String()},
+		},
+		TableName: aws.String(ResourceKeyTable),
+	}
+
+	result, err := dbClient.GetItem(context.TODO(), getItemInput)
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	if result.Item == nil {
+		return nil, &ErrorNotFound{Kind: ResourceKind, Id: key.String()}
+	}
+
+	k := resourceKey{}
+	err = attributevalue.UnmarshalMap(result.Item, &k)
+	if err != nil {
+		return nil, err
+	}
+	if k.BundleId != "" {
+		return nil, &ErrorFailedPrecondition{
+			Type:        "BUNDLE",
+			Subject:     key.String(),
+			Description: fmt.Sprintf("object %s is managed by resource bundle %q", key, k.BundleId),
+		}
+	}
+
+	return GetResource(key.TenantId, k.ResourceId)
+}
+
+// MigrateResourceKeys moves the keys of the objects of namespaced kinds
+// stored without namespace to the keys of the default namespace, see
+// SetClusterScoped. Keys whose new key is already taken are kept and
+// logged. It's idempotent.
+func MigrateResourceKeys() error {
+	paginator := dynamodb.NewScanPaginator(dbClient, &dynamodb.ScanInput{
+		TableName: aws.String(ResourceKeyTable),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return storeError(err)
+		}
+
+		for _, item := range page.Items {
+			k := resourceKey{}
+			if err := attributevalue.UnmarshalMap(item, &k); err != nil {
+				return err
+			}
+			key, ok := parseTargetKey(k.Key)
+			if !ok || key.String() == k.Key {
+				continue
+			}
+
+			moved := k
+			moved.Key = key.String()
+			movedItem, err := attributevalue.MarshalMap(moved)
+			if err != nil {
+				return err
+			}
+			_, err = dbClient.TransactWriteItems(context.TODO(), &dynamodb.TransactWriteItemsInput{
+				TransactItems: []types.TransactWriteItem{
+					{Put: putKey(movedItem)},
+					{Delete: &types.Delete{
+						TableName:           aws.String(ResourceKeyTable),
+						Key:                 map[string]types.AttributeValue{"Key": item["Key"]},
+						ConditionExpression: aws.String("attribute_exists(#key)"),
+						ExpressionAttributeNames: map[string]string{
+							"#key": "Key",
+						},
+					}},
+				},
+			})
+			var cancelled *types.TransactionCanceledException
+			if errors.As(err, &cancelled) {
+				log.Printf("Keeping resource key %q, as %q is taken", k.Key, moved.Key)
+				continue
+			}
+			if err != nil {
+				return storeError(err)
+			}
+		}
 	}
+	return nil
+}
 
-	statusAV, err := attributevalue.MarshalMap(status)
+// parseTargetKey parses the key of a target in ResourceKeyTable.
+func parseTargetKey(s string) (TargetKey, bool) {
+	parts := strings.Split(s, "/")
+	switch len(parts) {
+	case 5:
+		parts = append([]string{DefaultTenant}, parts...)
+	case 6:
+	default:
+		return TargetKey{}, false
+	}
+	return TargetKey{
+		TenantId:   parts[0],
+		ConsumerId: parts[1],
+		Group:      parts[2],
+		Kind:       parts[3],
+		Namespace:  parts[4],
+		Name:       parts[5],
+	}, true
+}
+
+// SetStatusResource stores the status reported by the agent of consumerID
+// for res, as read before the report, which must be placed on the consumer.
+// Resources being deleted are removed once the
+// agent reports the Deleted condition.
+// Reports of a generation older than the stored status are ignored, so the
+// observed generation never goes back, and reports of a generation that
+// wasn't published yet are rejected.
+func SetStatusResource(consumerID string, res *Resource, status StatusMessage) error {
+	resourceID := res.Id
+	if res.ConsumerId != consumerID {
+		return foreignStatusError("resource", resourceID, consumerID)
+	}
+	if status.ResourceGenerationID > res.ResourceGenerationID {
+		return &ErrorFailedPrecondition{
+			Type:        "GENERATION",
+			Subject:     resourceID,
+			Description: fmt.Sprintf("resource %q has no generation %d yet", resourceID, status.ResourceGenerationID),
+		}
+	}
+
+	statusAV, err := attributevalue.Marshal(status)
 	if err != nil {
 		return err
 	}
@@ -77,16 +535,80 @@ func SetStatusResource(resourceID string, statusData []byte) error {
 			"Id": &types.AttributeValueMemberS{Value: resourceID},
 		},
 		UpdateExpression: aws.String("SET #statusField = :statusValue"),
+		ConditionExpression: aws.String("attribute_exists(Id) AND " +
+			"(attribute_not_exists(#statusField.ResourceGenerationID) OR #statusField.ResourceGenerationID <= :generation)"),
 		ExpressionAttributeNames: map[string]string{
 			"#statusField": "Status",
 		},
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":statusValue": &types.AttributeValueMemberM{
-				Value: statusAV,
-			},
+			":statusValue": statusAV,
+			":generation":  &types.AttributeValueMemberN{Value: fmt.Sprint(status.ResourceGenerationID)},
 		},
 	}
 
 	_, err = dbClient.UpdateItem(context.TODO(), input)
-	return err
+	// removed in the meantime, or a newer generation was reported
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return nil
+	}
+	if err != nil {
+		return storeError(err)
+	}
+
+	if res.Deleting && meta.IsStatusConditionTrue(status.ReconcileStatus.Conditions, StatusMessageDeleted) {
+		return removeResource(res)
+	}
+
+	entry := statusTransition(resourceID, res.Status, status, time.Now())
+	if entry == nil {
+		return nil
+	}
+	return AppendStatusHistory(entry)
+}
+
+// removeResource deletes a resource being deleted and its TargetKey, and
+// releases its place in the quotas of its tenant and consumer.
+func removeResource(r *Resource) error {
+	items := []types.TransactWriteItem{
+		{Delete: &types.Delete{
+			TableName: aws.String(ResourceTable),
+			Key: map[string]types.AttributeValue{
+				"Id": &types.AttributeValueMemberS{Value: r.Id},
+			},
+			ConditionExpression: aws.String("Deleting = :deleting"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":deleting": &types.AttributeValueMemberBOOL{Value: true},
+			},
+		}},
+		// left out when the key is another's, or the quotas have nothing to
+		// release, e.g. for resources stored before they were counted
+		{Delete: &types.Delete{
+			TableName: aws.String(ResourceKeyTable),
+			Key: map[string]types.AttributeValue{
+				"Key": &types.AttributeValueMemberS{Value: TargetKeyOf(r).String()},
+			},
+			ConditionExpression: aws.String("attribute_not_exists(#key) OR ResourceId = :id"),
+			ExpressionAttributeNames: map[string]string{
+				"#key": "Key",
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":id": &types.AttributeValueMemberS{Value: r.Id},
+			},
+		}},
+		{Update: releaseQuotaUpdate(r.TenantId, resourceQuota)},
+	}
+	if r.ConsumerCounted {
+		items = append(items, types.TransactWriteItem{Update: releaseConsumerResource(r.TenantId, r.ConsumerId)})
+	}
+
+	err := transactOptional(items, 1)
+
+	// removed by a previous status
+	var cancelled *types.TransactionCanceledException
+	if errors.As(err, &cancelled) && len(cancelled.CancellationReasons) > 0 &&
+		aws.ToString(cancelled.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+		return nil
+	}
+	return storeError(err)
 }
@@ -0,0 +1,420 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const ResourceBundleTable = "ResourceBundles"
+
+// ResourceBundleKind names resource bundles in errors.
+const ResourceBundleKind = "ResourceBundle"
+
+type ResourceBundle struct {
+	Id string
+	// Tenant of the bundle and its consumer, empty for bundles stored
+	// before tenants, which belong to DefaultTenant.
+	TenantId             string
+	ConsumerId           string
+	ResourceGenerationID int64
+	Manifests            []unstructured.Unstructured
+	// Encryption is the data key of the encrypted values of Manifests, nil
+	// when none is encrypted.
+	Encryption *Encryption
+	Status     ResourceBundleStatusMessage
+	// Hash of the normalized Manifests, see manifest.ListContentHash, keyed
+	// by the KMS when encryption is configured.
+	ContentHash string
+	// Deleting is set once the bundle is deleted, until the agent reports
+	// its manifests are removed from the target.
+	Deleting bool
+	// ConsumerCounted is set once the bundle counts in the ResourceCount of
+	// its consumer, see CountConsumerResources.
+	ConsumerCounted bool `dynamodbav:",omitempty"`
+}
+
+// MaxBundleManifests bounds the manifests of a bundle, so that the keys of
+// both its previous and current manifests fit in the transaction of an
+// update.
+const MaxBundleManifests = 49
+
+// TargetKeysOf returns the TargetKeys of the objects managed by b, in the
+// order of its manifests.
+func TargetKeysOf(b *ResourceBundle) []TargetKey {
+	keys := make([]TargetKey, len(b.Manifests))
+	for i := range b.Manifests {
+		keys[i] = targetKeyOf(TenantOrDefault(b.TenantId), b.ConsumerId, &b.Manifests[i])
+	}
+	return keys
+}
+
+// CreateResourceBundle stores a new resource bundle at generation 1, along
+// with the TargetKeys of its manifests. Bundles count as resources in the
+// quota of their tenant and the maxResources of their consumer, 0 meaning
+// no limit: it returns ErrorResourceExhausted when either is reached, and
+// ErrorAlreadyExists, naming the owner, when another resource or bundle
+// already manages one of the objects of the bundle.
+func CreateResourceBundle(b *ResourceBundle, maxResources int64) error {
+	b.ResourceGenerationID = 1
+	b.ConsumerCounted = true
+	item, err := attributevalue.MarshalMap(b)
+	if err != nil {
+		return err
+	}
+
+	items := []types.TransactWriteItem{
+		{Put: &types.Put{
+			TableName:           aws.String(ResourceBundleTable),
+			Item:                item,
+			ConditionExpression: aws.String("attribute_not_exists(Id)"),
+		}},
+		{Update: reserveQuota(b.TenantId, resourceQuota)},
+		{Update: reserveConsumerResource(b.TenantId, b.ConsumerId, maxResources)},
+	}
+	keys := TargetKeysOf(b)
+	for _, key := range keys {
+		put, err := putBundleKey(key, b.Id)
+		if err != nil {
+			return err
+		}
+		items = append(items, types.TransactWriteItem{Put: put})
+	}
+
+	_, err = dbClient.TransactWriteItems(context.TODO(), &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	})
+
+	var cancelled *types.TransactionCanceledException
+	if errors.As(err, &cancelled) && len(cancelled.CancellationReasons) > 0 &&
+		aws.ToString(cancelled.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+		return &ErrorAlreadyExists{Kind: ResourceBundleKind, Id: b.Id}
+	}
+	if quotaErr := quotaError(err, 1, b.TenantId, resourceQuota); quotaErr != nil {
+		return quotaErr
+	}
+	if quotaErr := consumerQuotaError(err, 2, b.ConsumerId, maxResources); quotaErr != nil {
+		return quotaErr
+	}
+	return bundleKeyConflictError(err, 3, keys)
+}
+
+// UpdateResourceBundle replaces an existing resource bundle with its next
+// generation, which it sets on b, moving the TargetKeys of its manifests
+// from previous to those of its current manifests. It returns
+// ErrorFailedPrecondition when the bundle was updated after the generation
+// of b, and ErrorAlreadyExists when another resource or bundle already
+// manages one of the objects of its manifests.
+func UpdateResourceBundle(b *ResourceBundle, previous []TargetKey) error {
+	next := *b
+	next.ResourceGenerationID++
+	item, err := attributevalue.MarshalMap(&next)
+	if err != nil {
+		return err
+	}
+
+	items := []types.TransactWriteItem{
+		{Put: &types.Put{
+			TableName:           aws.String(ResourceBundleTable),
+			Item:                item,
+			ConditionExpression: aws.String("ResourceGenerationID = :generation"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":generation": &types.AttributeValueMemberN{Value: fmt.Sprint(b.ResourceGenerationID)},
+			},
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+		}},
+	}
+	// the keys are put again, bundles stored before keys were tracked get
+	// them on their next update
+	keys := TargetKeysOf(b)
+	current := map[string]bool{}
+	for _, key := range keys {
+		put, err := putBundleKey(key, b.Id)
+		if err != nil {
+			return err
+		}
+		items = append(items, types.TransactWriteItem{Put: put})
+		current[key.String()] = true
+	}
+	required := len(items)
+	for _, key := range previous {
+		if !current[key.String()] {
+			items = append(items, types.TransactWriteItem{Delete: deleteBundleKey(key, b.Id)})
+		}
+	}
+
+	err = transactOptional(items, required)
+
+	var cancelled *types.TransactionCanceledException
+	if errors.As(err, &cancelled) && len(cancelled.CancellationReasons) > 0 &&
+		aws.ToString(cancelled.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+		if cancelled.CancellationReasons[0].Item == nil {
+			return &ErrorNotFound{Kind: ResourceBundleKind, Id: b.Id}
+		}
+		return &ErrorFailedPrecondition{
+			Type:        "GENERATION",
+			Subject:     b.Id,
+			Description: fmt.Sprintf("resource bundle %q was updated after generation %d", b.Id, b.ResourceGenerationID),
+		}
+	}
+	if err != nil {
+		return bundleKeyConflictError(err, 1, keys)
+	}
+	b.ResourceGenerationID = next.ResourceGenerationID
+	return nil
+}
+
+// putBundleKey puts the key of a manifest of a bundle, unless another
+// resource or bundle owns it.
+func putBundleKey(key TargetKey, bundleID string) (*types.Put, error) {
+	keyItem, err := attributevalue.MarshalMap(resourceKey{Key: key.String(), BundleId: bundleID})
+	if err != nil {
+		return nil, err
+	}
+	return &types.Put{
+		TableName:           aws.String(ResourceKeyTable),
+		Item:                keyItem,
+		ConditionExpression: aws.String("attribute_not_exists(#key) OR BundleId = :id"),
+		ExpressionAttributeNames: map[string]string{
+			"#key": "Key",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":id": &types.AttributeValueMemberS{Value: bundleID},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}, nil
+}
+
+// deleteBundleKey deletes the key of a manifest of a bundle. Its condition
+// fails when another resource or bundle owns the key, e.g. for bundles
+// stored before keys were tracked.
+func deleteBundleKey(key TargetKey, bundleID string) *types.Delete {
+	return &types.Delete{
+		TableName: aws.String(ResourceKeyTable),
+		Key: map[string]types.AttributeValue{
+			"Key": &types.AttributeValueMemberS{Value: key.String()},
+		},
+		ConditionExpression: aws.String("attribute_not_exists(#key) OR BundleId = :id"),
+		ExpressionAttributeNames: map[string]string{
+			"#key": "Key",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":id": &types.AttributeValueMemberS{Value: bundleID},
+		},
+	}
+}
+
+// bundleKeyConflictError turns a cancelled transaction whose key Puts,
+// starting at index first, failed their condition into ErrorAlreadyExists.
+func bundleKeyConflictError(err error, first int, keys []TargetKey) error {
+	var cancelled *types.TransactionCanceledException
+	if !errors.As(err, &cancelled) {
+		return storeError(err)
+	}
+
+	for i, key := range keys {
+		if first+i >= len(cancelled.CancellationReasons) {
+			break
+		}
+		reason := cancelled.CancellationReasons[first+i]
+		if aws.ToString(reason.Code) == "ConditionalCheckFailed" {
+			return keyOwnerError(reason, key)
+		}
+	}
+	return storeError(err)
+}
+
+// transactOptional runs a transaction of items, of which those from index
+// optional on are left out when their condition fails, e.g. releasing a
+// quota already at zero: the transaction is then tried again without
+// them. It returns the error of the last try.
+func transactOptional(items []types.TransactWriteItem, optional int) error {
+	_, err := dbClient.TransactWriteItems(context.TODO(), &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	})
+
+	var cancelled *types.TransactionCanceledException
+	if !errors.As(err, &cancelled) || len(cancelled.CancellationReasons) != len(items) {
+		return err
+	}
+
+	kept := items[:optional:optional]
+	for i, reason := range cancelled.CancellationReasons {
+		code := aws.ToString(reason.Code)
+		switch {
+		case i < optional && code != "" && code != "None":
+			// a required item failed
+			return err
+		case i >= optional && code != "ConditionalCheckFailed":
+			kept = append(kept, items[i])
+		}
+	}
+	if len(kept) == len(items) {
+		return err
+	}
+
+	_, err = dbClient.TransactWriteItems(context.TODO(), &dynamodb.TransactWriteItemsInput{
+		TransactItems: kept,
+	})
+	return err
+}
+
+// GetResourceBundle returns a bundle of the tenant, ErrorNotFound if it
+// belongs to another tenant.
+func GetResourceBundle(tenantID, bundleID string) (*ResourceBundle, error) {
+	getItemInput := &dynamodb.GetItemInput{
+		Key: map[string]types.AttributeValue{
+			"Id": &types.AttributeValueMemberS{Value: bundleID},
+		},
+		TableName: aws.String(ResourceBundleTable),
+	}
+
+	b := ResourceBundle{}
+
+	result, err := dbClient.GetItem(context.TODO(), getItemInput)
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	if result.Item == nil {
+		return nil, &ErrorNotFound{Kind: ResourceBundleKind, Id: bundleID}
+	}
+
+	err = attributevalue.UnmarshalMap(result.Item, &b)
+	if err != nil {
+		return nil, err
+	}
+
+	if TenantOrDefault(b.TenantId) != tenantID {
+		return nil, &ErrorNotFound{Kind: ResourceBundleKind, Id: bundleID}
+	}
+	// items stored before tenants get their tenant on the next update
+	b.TenantId = tenantID
+	return &b, nil
+}
+
+// SetStatusResourceBundle stores the status reported by the agent of a
+// consumer of a tenant, which must be the consumer of the bundle. Bundles
+// being deleted are removed once the agent reports the Deleted condition,
+// later reports of the condition are ignored.
+func SetStatusResourceBundle(tenantID, consumerID, bundleID string, statusData []byte) error {
+	status := ResourceBundleStatusMessage{}
+	if err := json.Unmarshal(statusData, &status); err != nil {
+		return err
+	}
+	deleted := meta.IsStatusConditionTrue(status.ReconcileStatus.Conditions, StatusMessageDeleted)
+
+	// agents only report the status of the bundles of their tenant
+	b, err := GetResourceBundle(tenantID, bundleID)
+	var notFound *ErrorNotFound
+	if deleted && errors.As(err, &notFound) {
+		// duplicate or late report of a removed bundle
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if b.ConsumerId != consumerID {
+		return foreignStatusError("resource bundle", bundleID, consumerID)
+	}
+	if status.ResourceGenerationID > b.ResourceGenerationID {
+		return &ErrorFailedPrecondition{
+			Type:        "GENERATION",
+			Subject:     bundleID,
+			Description: fmt.Sprintf("resource bundle %q has no generation %d yet", bundleID, status.ResourceGenerationID),
+		}
+	}
+
+	if deleted {
+		removed, err := removeResourceBundle(b)
+		if err != nil || removed {
+			return err
+		}
+		// a bundle that isn't being deleted only records the status
+	}
+
+	statusAV, err := attributevalue.Marshal(status)
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(ResourceBundleTable),
+		Key: map[string]types.AttributeValue{
+			"Id": &types.AttributeValueMemberS{Value: bundleID},
+		},
+		UpdateExpression: aws.String("SET #statusField = :statusValue"),
+		// don't recreate bundles removed in the meantime, nor record the
+		// status of an older generation than the stored one
+		ConditionExpression: aws.String("attribute_exists(Id) AND " +
+			"(attribute_not_exists(#statusField.ResourceGenerationID) OR #statusField.ResourceGenerationID <= :generation)"),
+		ExpressionAttributeNames: map[string]string{
+			"#statusField": "Status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":statusValue": statusAV,
+			":generation":  &types.AttributeValueMemberN{Value: fmt.Sprint(status.ResourceGenerationID)},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}
+
+	_, err = dbClient.UpdateItem(context.TODO(), input)
+
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		if conditionErr.Item == nil && !deleted {
+			return &ErrorNotFound{Kind: ResourceBundleKind, Id: bundleID}
+		}
+		// a newer generation was reported, or the bundle was removed
+		return nil
+	}
+	return storeError(err)
+}
+
+// removeResourceBundle deletes a bundle being deleted and the TargetKeys
+// of its manifests, and releases its place in the quota of its tenant, in
+// a single transaction. It tells whether the bundle is removed, also when a
+// previous status removed it, and returns false when the bundle isn't
+// being deleted.
+func removeResourceBundle(b *ResourceBundle) (bool, error) {
+	items := []types.TransactWriteItem{
+		{Delete: &types.Delete{
+			TableName: aws.String(ResourceBundleTable),
+			Key: map[string]types.AttributeValue{
+				"Id": &types.AttributeValueMemberS{Value: b.Id},
+			},
+			ConditionExpression: aws.String("Deleting = :deleting"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":deleting": &types.AttributeValueMemberBOOL{Value: true},
+			},
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+		}},
+		// left out when the quota has nothing to release, e.g. for bundles
+		// stored before quotas were counted
+		{Update: releaseQuotaUpdate(b.TenantId, resourceQuota)},
+	}
+	if b.ConsumerCounted {
+		items = append(items, types.TransactWriteItem{Update: releaseConsumerResource(b.TenantId, b.ConsumerId)})
+	}
+	for _, key := range TargetKeysOf(b) {
+		items = append(items, types.TransactWriteItem{Delete: deleteBundleKey(key, b.Id)})
+	}
+
+	err := transactOptional(items, 1)
+
+	var cancelled *types.TransactionCanceledException
+	if errors.As(err, &cancelled) && len(cancelled.CancellationReasons) > 0 &&
+		aws.ToString(cancelled.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+		// removed by a previous status when it no longer exists
+		return cancelled.CancellationReasons[0].Item == nil, nil
+	}
+	return err == nil, storeError(err)
+}
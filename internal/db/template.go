@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// templateIndex is the global secondary index of ResourceTable listing the
+// templated resources of a consumer by their TemplateConsumer. Resources
+// without template aren't in the index.
+const templateIndex = "TemplateIndex"
+
+// templateConsumerOf returns the TemplateConsumer of r, empty when r isn't
+// templated.
+func templateConsumerOf(r *Resource) string {
+	if r.Template == nil {
+		return ""
+	}
+	return TenantOrDefault(r.TenantId) + "/" + r.ConsumerId
+}
+
+// ListTemplatedResources returns the resources of a consumer rendered from
+// a template, ordered by Id.
+func ListTemplatedResources(tenantID, consumerID string) ([]*Resource, error) {
+	paginator := dynamodb.NewQueryPaginator(dbClient, &dynamodb.QueryInput{
+		TableName:              aws.String(ResourceTable),
+		IndexName:              aws.String(templateIndex),
+		KeyConditionExpression: aws.String("TemplateConsumer = :consumer"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":consumer": &types.AttributeValueMemberS{Value: tenantID + "/" + consumerID},
+		},
+	})
+
+	var resources []*Resource
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, storeError(err)
+		}
+
+		for _, item := range page.Items {
+			r := &Resource{}
+			if err := attributevalue.UnmarshalMap(item, r); err != nil {
+				return nil, err
+			}
+			r.TenantId = tenantID
+			resources = append(resources, r)
+		}
+	}
+	return resources, nil
+}
+
+// MigrateTemplatedResources sets the TemplateConsumer of the templated
+// resources stored before TemplateIndex, so they're listed by
+// ListTemplatedResources. It's idempotent, and cheap once every resource
+// is migrated.
+func MigrateTemplatedResources() error {
+	paginator := dynamodb.NewScanPaginator(dbClient, &dynamodb.ScanInput{
+		TableName:            aws.String(ResourceTable),
+		FilterExpression:     aws.String("attribute_type(#template, :map) AND attribute_not_exists(TemplateConsumer)"),
+		ProjectionExpression: aws.String("Id, TenantId, ConsumerId"),
+		ExpressionAttributeNames: map[string]string{
+			"#template": "Template",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":map": &types.AttributeValueMemberS{Value: "M"},
+		},
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return storeError(err)
+		}
+
+		for _, item := range page.Items {
+			r := &Resource{}
+			if err := attributevalue.UnmarshalMap(item, r); err != nil {
+				return err
+			}
+			// only the templated resources are scanned
+			r.Template = &ResourceTemplate{}
+
+			_, err = dbClient.UpdateItem(context.TODO(), &dynamodb.UpdateItemInput{
+				TableName:           aws.String(ResourceTable),
+				Key:                 map[string]types.AttributeValue{"Id": item["Id"]},
+				UpdateExpression:    aws.String("SET TemplateConsumer = :consumer"),
+				ConditionExpression: aws.String("attribute_exists(Id)"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":consumer": &types.AttributeValueMemberS{Value: templateConsumerOf(r)},
+				},
+			})
+			var conditionErr *types.ConditionalCheckFailedException
+			if err != nil && !errors.As(err, &conditionErr) {
+				return storeError(err)
+			}
+		}
+	}
+	return nil
+}
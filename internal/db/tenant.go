@@ -0,0 +1,239 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const TenantTable = "Tenants"
+
+// TenantKind names tenants in errors.
+const TenantKind = "Tenant"
+
+// DefaultTenant owns the consumers and resources of requests without
+// tenant. It exists without being created, with no quota until one is set.
+const DefaultTenant = "default"
+
+// Tenant isolates the consumers and resources of a team, and caps their
+// number. A zero Max means no limit.
+type Tenant struct {
+	Id            string
+	MaxConsumers  int64
+	MaxResources  int64
+	ConsumerCount int64
+	ResourceCount int64
+	// messages of the tenant dead-lettered so far.
+	DeadLetterCount int64
+}
+
+// TenantOrDefault returns tenantID, or DefaultTenant when it's empty.
+func TenantOrDefault(tenantID string) string {
+	if tenantID == "" {
+		return DefaultTenant
+	}
+	return tenantID
+}
+
+// tenantQuota is a counter of a tenant and its limit.
+type tenantQuota struct {
+	count string
+	limit string
+	// names what is counted in errors.
+	kind string
+}
+
+var (
+	consumerQuota = tenantQuota{count: "ConsumerCount", limit: "MaxConsumers", kind: "consumers"}
+	resourceQuota = tenantQuota{count: "ResourceCount", limit: "MaxResources", kind: "resources"}
+)
+
+// CreateTenant stores a new tenant. It returns ErrorAlreadyExists when a
+// tenant with the same Id is already stored.
+func CreateTenant(t *Tenant) error {
+	item, err := attributevalue.MarshalMap(t)
+	if err != nil {
+		return err
+	}
+
+	_, err = dbClient.PutItem(context.TODO(), &dynamodb.PutItemInput{
+		TableName:           aws.String(TenantTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(Id)"),
+	})
+
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return &ErrorAlreadyExists{Kind: TenantKind, Id: t.Id}
+	}
+	return storeError(err)
+}
+
+// UpdateTenantQuotas sets the limits of a tenant, keeping its counters,
+// and returns the updated tenant.
+func UpdateTenantQuotas(tenantID string, maxConsumers, maxResources int64) (*Tenant, error) {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(TenantTable),
+		Key: map[string]types.AttributeValue{
+			"Id": &types.AttributeValueMemberS{Value: tenantID},
+		},
+		UpdateExpression: aws.String("SET MaxConsumers = :maxConsumers, MaxResources = :maxResources"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":maxConsumers": &types.AttributeValueMemberN{Value: fmt.Sprint(maxConsumers)},
+			":maxResources": &types.AttributeValueMemberN{Value: fmt.Sprint(maxResources)},
+		},
+		ReturnValues: types.ReturnValueAllNew,
+	}
+	// the default tenant is stored the first time it's updated
+	if tenantID != DefaultTenant {
+		input.ConditionExpression = aws.String("attribute_exists(Id)")
+	}
+
+	result, err := dbClient.UpdateItem(context.TODO(), input)
+
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return nil, &ErrorNotFound{Kind: TenantKind, Id: tenantID}
+	}
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	t := &Tenant{}
+	err = attributevalue.UnmarshalMap(result.Attributes, t)
+	return t, err
+}
+
+func GetTenant(tenantID string) (*Tenant, error) {
+	result, err := dbClient.GetItem(context.TODO(), &dynamodb.GetItemInput{
+		TableName: aws.String(TenantTable),
+		Key: map[string]types.AttributeValue{
+			"Id": &types.AttributeValueMemberS{Value: tenantID},
+		},
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	if result.Item == nil {
+		if tenantID == DefaultTenant {
+			return &Tenant{Id: DefaultTenant}, nil
+		}
+		return nil, &ErrorNotFound{Kind: TenantKind, Id: tenantID}
+	}
+
+	t := &Tenant{}
+	err = attributevalue.UnmarshalMap(result.Item, t)
+	return t, err
+}
+
+// reserveQuota increments the counter q of a tenant in a transaction,
+// failing when the tenant doesn't exist or the counter reached its limit.
+// See quotaError.
+func reserveQuota(tenantID string, q tenantQuota) *types.Update {
+	condition := "(attribute_not_exists(#limit) OR #limit = :zero OR attribute_not_exists(#count) OR #count < #limit)"
+	if tenantID != DefaultTenant {
+		condition = "attribute_exists(Id) AND " + condition
+	}
+
+	return &types.Update{
+		TableName: aws.String(TenantTable),
+		Key: map[string]types.AttributeValue{
+			"Id": &types.AttributeValueMemberS{Value: tenantID},
+		},
+		UpdateExpression:    aws.String("SET #count = if_not_exists(#count, :zero) + :one"),
+		ConditionExpression: aws.String(condition),
+		ExpressionAttributeNames: map[string]string{
+			"#count": q.count,
+			"#limit": q.limit,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":zero": &types.AttributeValueMemberN{Value: "0"},
+			":one":  &types.AttributeValueMemberN{Value: "1"},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}
+}
+
+// quotaError turns a cancelled transaction whose reserveQuota update, at
+// index i, failed its condition into ErrorFailedPrecondition when the
+// tenant doesn't exist, or ErrorResourceExhausted. It returns nil for any
+// other error.
+func quotaError(err error, i int, tenantID string, q tenantQuota) error {
+	var cancelled *types.TransactionCanceledException
+	if !errors.As(err, &cancelled) || len(cancelled.CancellationReasons) <= i {
+		return nil
+	}
+
+	reason := cancelled.CancellationReasons[i]
+	if aws.ToString(reason.Code) != "ConditionalCheckFailed" {
+		return nil
+	}
+
+	if reason.Item == nil {
+		return &ErrorFailedPrecondition{
+			Type:        "TENANT",
+			Subject:     tenantID,
+			Description: fmt.Sprintf("tenant %q does not exist, create it first", tenantID),
+		}
+	}
+
+	t := &Tenant{}
+	if err := attributevalue.UnmarshalMap(reason.Item, t); err != nil {
+		return err
+	}
+	limit := t.MaxConsumers
+	if q == resourceQuota {
+		limit = t.MaxResources
+	}
+	return &ErrorResourceExhausted{
+		Subject:     "tenant:" + tenantID,
+		Description: fmt.Sprintf("tenant %q reached its quota of %d %s", tenantID, limit, q.kind),
+	}
+}
+
+// releaseQuota decrements the counter q of a tenant. Counters that are
+// already zero, e.g. for objects stored before quotas were counted, are
+// left unchanged.
+func releaseQuota(tenantID string, q tenantQuota) error {
+	update := releaseQuotaUpdate(tenantID, q)
+	_, err := dbClient.UpdateItem(context.TODO(), &dynamodb.UpdateItemInput{
+		TableName:                 update.TableName,
+		Key:                       update.Key,
+		UpdateExpression:          update.UpdateExpression,
+		ConditionExpression:       update.ConditionExpression,
+		ExpressionAttributeNames:  update.ExpressionAttributeNames,
+		ExpressionAttributeValues: update.ExpressionAttributeValues,
+	})
+
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return nil
+	}
+	return storeError(err)
+}
+
+// releaseQuotaUpdate decrements the counter q of a tenant, in a
+// transaction. Its condition fails when the counter is already zero.
+func releaseQuotaUpdate(tenantID string, q tenantQuota) *types.Update {
+	return &types.Update{
+		TableName: aws.String(TenantTable),
+		Key: map[string]types.AttributeValue{
+			"Id": &types.AttributeValueMemberS{Value: tenantID},
+		},
+		UpdateExpression:    aws.String("SET #count = #count - :one"),
+		ConditionExpression: aws.String("#count > :zero"),
+		ExpressionAttributeNames: map[string]string{
+			"#count": q.count,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":zero": &types.AttributeValueMemberN{Value: "0"},
+			":one":  &types.AttributeValueMemberN{Value: "1"},
+		},
+	}
+}
@@ -0,0 +1,403 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kube-orchestra/maestro/internal/db"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const encryptionConfigFile = "ENCRYPTION_CONFIG_FILE"
+
+// valuePrefix starts the encrypted values, followed by the base64 encoded
+// nonce and ciphertext of the JSON encoding of the value. The value is bound
+// to its place by the additional data of the ciphertext, see valueAAD.
+const valuePrefix = "enc:v2:"
+
+// legacyValuePrefix starts the values encrypted without additional data,
+// they're still decrypted.
+const legacyValuePrefix = "enc:v1:"
+
+// hashPrefix starts the content hashes keyed by the KMS.
+const hashPrefix = "hmac-sha256:"
+
+// Algorithm encrypts the values with their data key.
+const Algorithm = "AES-256-GCM"
+
+type Config struct {
+	KMS KMSConfig `json:"kms"`
+	// sensitive fields of the objects, by kind. The data and stringData of
+	// Secrets when empty.
+	Resources []Resource `json:"resources,omitempty"`
+}
+
+type KMSConfig struct {
+	// provider of the KMS wrapping the data keys, only "local" for now.
+	Provider string `json:"provider"`
+	// key file of the local provider, see LocalKeys.
+	KeyFile string `json:"keyFile,omitempty"`
+}
+
+// Resource lists the sensitive fields of the objects of a kind.
+type Resource struct {
+	// group of the kind, empty for the core group.
+	Group string `json:"group,omitempty"`
+	Kind  string `json:"kind"`
+	// dot-separated paths of the fields, e.g. ".data" or
+	// ".spec.credentials.password". The values of the fields holding an
+	// object are encrypted one by one, other fields as a whole.
+	Paths []string `json:"paths"`
+}
+
+var defaultResources = []Resource{
+	{Kind: "Secret", Paths: []string{".data", ".stringData"}},
+}
+
+// Encryptor encrypts the sensitive fields of objects with a data key per
+// resource generation, wrapped by a KMS.
+type Encryptor struct {
+	kms     KMS
+	hashKey []byte
+	paths   map[schema.GroupKind][][]string
+}
+
+// New loads the encryption configuration ENCRYPTION_CONFIG_FILE. Without
+// file, objects are stored and published as they are.
+func New() (*Encryptor, error) {
+	path := os.Getenv(encryptionConfigFile)
+	if path == "" {
+		return &Encryptor{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("failed to read encryption configuration %s: %w", path, err)
+	}
+
+	e := &Encryptor{paths: map[schema.GroupKind][][]string{}}
+	switch config.KMS.Provider {
+	case "local":
+		e.kms, err = newLocalKMS(config.KMS.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("encryption configuration %s: kms: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("encryption configuration %s: kms: unknown provider %q", path, config.KMS.Provider)
+	}
+	e.hashKey, err = e.kms.HashKey()
+	if err != nil {
+		return nil, fmt.Errorf("encryption configuration %s: kms: %w", path, err)
+	}
+
+	resources := config.Resources
+	if len(resources) == 0 {
+		resources = defaultResources
+	}
+	for i, r := range resources {
+		if r.Kind == "" {
+			return nil, fmt.Errorf("encryption configuration %s: resources[%d]: kind is required", path, i)
+		}
+		gk := schema.GroupKind{Group: r.Group, Kind: r.Kind}
+		for j, p := range r.Paths {
+			fields := strings.Split(strings.TrimPrefix(p, "."), ".")
+			for _, f := range fields {
+				if f == "" {
+					return nil, fmt.Errorf("encryption configuration %s: resources[%d].paths[%d]: invalid path %q", path, i, j, p)
+				}
+			}
+			e.paths[gk] = append(e.paths[gk], fields)
+		}
+	}
+	return e, nil
+}
+
+// Encrypt encrypts the sensitive fields of objects in place with a new data
+// key, binding each value to id, its object and its field, and returns the
+// envelope of the key. It returns nil when no value was encrypted.
+func (e *Encryptor) Encrypt(id string, objects ...*unstructured.Unstructured) (*db.Encryption, error) {
+	if e.kms == nil {
+		return nil, nil
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted := 0
+	for _, obj := range objects {
+		encrypt := func(path []string, value interface{}) (interface{}, error) {
+			plaintext, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			aad, err := valueAAD(id, obj, path)
+			if err != nil {
+				return nil, err
+			}
+			sealed, err := seal(aead, plaintext, aad)
+			if err != nil {
+				return nil, err
+			}
+			encrypted++
+			return valuePrefix + base64.StdEncoding.EncodeToString(sealed), nil
+		}
+		for _, fields := range e.paths[obj.GroupVersionKind().GroupKind()] {
+			if err := transformField(obj.Object, nil, fields, encrypt); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if encrypted == 0 {
+		return nil, nil
+	}
+
+	wrapped, keyID, err := e.kms.WrapKey(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &db.Encryption{KeyId: keyID, WrappedKey: wrapped}, nil
+}
+
+// Decrypt decrypts in place the values of objects encrypted with the data
+// key of enc for id. Objects are left untouched when enc is nil.
+func (e *Encryptor) Decrypt(enc *db.Encryption, id string, objects ...*unstructured.Unstructured) error {
+	if enc == nil {
+		return nil
+	}
+
+	dataKey, err := e.unwrapKey(enc)
+	if err != nil {
+		return err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objects {
+		// every encrypted value is decrypted, even those of fields that are
+		// no longer configured as sensitive
+		decrypt := func(path []string, value string) (interface{}, error) {
+			var aad []byte
+			var err error
+			if strings.HasPrefix(value, valuePrefix) {
+				aad, err = valueAAD(id, obj, path)
+				if err != nil {
+					return nil, err
+				}
+			}
+			sealed, err := base64.StdEncoding.DecodeString(value[len(valuePrefix):])
+			if err != nil {
+				return nil, err
+			}
+			plaintext, err := open(aead, sealed, aad)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", strings.Join(path, "."), err)
+			}
+			// numbers are decoded as int64 when they're integers, as in
+			// unstructured objects
+			var decrypted interface{}
+			err = utiljson.Unmarshal(plaintext, &decrypted)
+			return decrypted, err
+		}
+		decrypted, err := decryptValues(obj.Object, nil, decrypt)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt object: %w", err)
+		}
+		obj.Object = decrypted.(map[string]interface{})
+	}
+	return nil
+}
+
+// Seal returns objects as published to a consumer: with their encrypted
+// values and the data key of enc encrypted to the PEM encoded RSA public key
+// of the consumer, or decrypted when the consumer has no key.
+func (e *Encryptor) Seal(enc *db.Encryption, id, publicKey string, objects ...*unstructured.Unstructured) ([]*unstructured.Unstructured, *db.ContentEncryption, error) {
+	if enc == nil {
+		return objects, nil, nil
+	}
+
+	if publicKey == "" {
+		decrypted := make([]*unstructured.Unstructured, len(objects))
+		for i, obj := range objects {
+			decrypted[i] = obj.DeepCopy()
+		}
+		err := e.Decrypt(enc, id, decrypted...)
+		return decrypted, nil, err
+	}
+
+	key, err := parsePublicKey(publicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	dataKey, err := e.unwrapKey(enc)
+	if err != nil {
+		return nil, nil, err
+	}
+	wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, key, dataKey, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return objects, &db.ContentEncryption{Algorithm: Algorithm, WrappedKey: wrapped}, nil
+}
+
+// ContentHash returns hash keyed by the KMS, in the form
+// "hmac-sha256:<hex>", so the hashes of low entropy values can't be guessed
+// without the KMS. hash is returned as it is without KMS. The keyed hashes
+// change with the primary key of the KMS.
+func (e *Encryptor) ContentHash(hash string) string {
+	if e.kms == nil {
+		return hash
+	}
+	mac := hmac.New(sha256.New, e.hashKey)
+	mac.Write([]byte(hash))
+	return hashPrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// valueAAD returns the additional data of a value of obj at path: the JSON
+// encoding of id, the group kind, namespace and name of obj, followed by
+// the fields of path.
+func valueAAD(id string, obj *unstructured.Unstructured, path []string) ([]byte, error) {
+	aad := []string{id, obj.GroupVersionKind().GroupKind().String(), obj.GetNamespace(), obj.GetName()}
+	return json.Marshal(append(aad, path...))
+}
+
+func (e *Encryptor) unwrapKey(enc *db.Encryption) ([]byte, error) {
+	if e.kms == nil {
+		return nil, fmt.Errorf("values encrypted with key %q can't be decrypted without %s", enc.KeyId, encryptionConfigFile)
+	}
+	return e.kms.UnwrapKey(enc.KeyId, enc.WrappedKey)
+}
+
+// CheckPublicKey checks publicKey is a PEM encoded RSA public key of at
+// least 2048 bits.
+func CheckPublicKey(publicKey string) error {
+	_, err := parsePublicKey(publicKey)
+	return err
+}
+
+func parsePublicKey(publicKey string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil {
+		return nil, errors.New("a PEM encoded public key is required")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("an RSA public key is required")
+	}
+	if rsaKey.N.BitLen() < 2048 {
+		return nil, errors.New("the RSA public key must have at least 2048 bits")
+	}
+	return rsaKey, nil
+}
+
+// transformField replaces the value of the field at the end of fields with
+// the output of transform, or the value of each of its entries when it
+// holds an object. transform gets the path of the value from the root,
+// object being at path. Missing fields are skipped.
+func transformField(object map[string]interface{}, path, fields []string, transform func([]string, interface{}) (interface{}, error)) error {
+	value, ok := object[fields[0]]
+	if !ok || value == nil {
+		return nil
+	}
+	path = appendPath(path, fields[0])
+
+	if len(fields) > 1 {
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		return transformField(nested, path, fields[1:], transform)
+	}
+
+	if entries, ok := value.(map[string]interface{}); ok {
+		for key, entry := range entries {
+			transformed, err := transform(appendPath(path, key), entry)
+			if err != nil {
+				return err
+			}
+			entries[key] = transformed
+		}
+		return nil
+	}
+
+	transformed, err := transform(path, value)
+	if err != nil {
+		return err
+	}
+	object[fields[0]] = transformed
+	return nil
+}
+
+// decryptValues returns value with its encrypted strings decrypted, value
+// being at path.
+func decryptValues(value interface{}, path []string, decrypt func([]string, string) (interface{}, error)) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		decrypted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			d, err := decryptValues(item, appendPath(path, key), decrypt)
+			if err != nil {
+				return nil, err
+			}
+			decrypted[key] = d
+		}
+		return decrypted, nil
+	case []interface{}:
+		decrypted := make([]interface{}, len(v))
+		for i, item := range v {
+			d, err := decryptValues(item, appendPath(path, strconv.Itoa(i)), decrypt)
+			if err != nil {
+				return nil, err
+			}
+			decrypted[i] = d
+		}
+		return decrypted, nil
+	case string:
+		if !strings.HasPrefix(v, valuePrefix) && !strings.HasPrefix(v, legacyValuePrefix) {
+			return v, nil
+		}
+		return decrypt(path, v)
+	default:
+		return v, nil
+	}
+}
+
+// appendPath returns path followed by field, without sharing the array of
+// path.
+func appendPath(path []string, field string) []string {
+	return append(path[:len(path):len(path)], field)
+}
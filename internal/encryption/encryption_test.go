@@ -0,0 +1,470 @@
+package encryption
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kube-orchestra/maestro/internal/db"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	e := newTestEncryptor(t, newTestKey(t, "key-1"))
+
+	secret := newSecret()
+	configMap := newConfigMap()
+	enc, err := e.Encrypt("resource1", secret, configMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc == nil || enc.KeyId != "key-1" {
+		t.Fatalf("got encryption %+v, want a data key wrapped by key-1", enc)
+	}
+
+	for _, value := range []interface{}{
+		secret.Object["data"].(map[string]interface{})["password"],
+		secret.Object["data"].(map[string]interface{})["token"],
+		secret.Object["stringData"],
+		configMap.Object["data"].(map[string]interface{})["password"],
+	} {
+		if s, ok := value.(string); !ok || !strings.HasPrefix(s, valuePrefix) {
+			t.Errorf("value %v isn't encrypted", value)
+		}
+	}
+	if got := configMap.Object["data"].(map[string]interface{})["host"]; got != "db" {
+		t.Errorf("value of a field that isn't sensitive changed to %v", got)
+	}
+
+	if err := e.Decrypt(enc, "resource1", secret, configMap); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(secret, newSecret()) {
+		t.Errorf("got secret %v, want %v", secret.Object, newSecret().Object)
+	}
+	if !reflect.DeepEqual(configMap, newConfigMap()) {
+		t.Errorf("got config map %v, want %v", configMap.Object, newConfigMap().Object)
+	}
+}
+
+func TestEncryptNothing(t *testing.T) {
+	for name, e := range map[string]*Encryptor{
+		"without configuration":   {},
+		"without sensitive field": newTestEncryptor(t, newTestKey(t, "key-1")),
+	} {
+		t.Run(name, func(t *testing.T) {
+			deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"spec":       map[string]interface{}{"replicas": int64(1)},
+			}}
+			enc, err := e.Encrypt("resource1", deployment)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if enc != nil {
+				t.Errorf("got encryption %+v, want nil", enc)
+			}
+			if err := e.Decrypt(enc, "resource1", deployment); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	secret := newSecret()
+	enc, err := (&Encryptor{}).Encrypt("resource1", secret)
+	if err != nil || enc != nil {
+		t.Fatalf("got encryption %+v and error %v, want none", enc, err)
+	}
+	if !reflect.DeepEqual(secret, newSecret()) {
+		t.Errorf("secret changed without configuration: %v", secret.Object)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	key1 := newTestKey(t, "key-1")
+	key2 := newTestKey(t, "key-2")
+
+	before := newSecret()
+	oldEnc, err := newTestEncryptor(t, key1).Encrypt("resource1", before)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := newTestEncryptor(t, key2, key1)
+	after := newSecret()
+	newEnc, err := rotated.Encrypt("resource1", after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newEnc.KeyId != "key-2" {
+		t.Errorf("new data key wrapped by %q, want key-2", newEnc.KeyId)
+	}
+
+	// values encrypted before the rotation are still decrypted
+	if err := rotated.Decrypt(oldEnc, "resource1", before); err != nil {
+		t.Fatal(err)
+	}
+	if err := rotated.Decrypt(newEnc, "resource1", after); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(before, newSecret()) || !reflect.DeepEqual(after, newSecret()) {
+		t.Errorf("got secrets %v and %v, want %v", before.Object, after.Object, newSecret().Object)
+	}
+
+	// once the previous key is removed, its data keys can't be unwrapped
+	stale := newSecret()
+	staleEnc, err := newTestEncryptor(t, key1).Encrypt("resource1", stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = newTestEncryptor(t, key2).Decrypt(staleEnc, "resource1", stale)
+	if err == nil || !strings.Contains(err.Error(), "key-1") {
+		t.Errorf("got error %v, want unknown key-1", err)
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	e := newTestEncryptor(t, newTestKey(t, "key-1"))
+
+	secret := newSecret()
+	enc, err := e.Encrypt("resource1", secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := newSecret()
+	otherEnc, err := e.Encrypt("resource1", other)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// values are only decrypted with the data key of their generation
+	if err := e.Decrypt(otherEnc, "resource1", secret); err == nil {
+		t.Error("values decrypted with the data key of another generation")
+	}
+	if err := (&Encryptor{}).Decrypt(enc, "resource1", secret); err == nil || !strings.Contains(err.Error(), encryptionConfigFile) {
+		t.Errorf("got error %v, want %s required", err, encryptionConfigFile)
+	}
+}
+
+func TestDecryptMoved(t *testing.T) {
+	e := newTestEncryptor(t, newTestKey(t, "key-1"))
+
+	tests := []struct {
+		name string
+		id   string
+		move func(secret, configMap *unstructured.Unstructured)
+	}{
+		{
+			name: "other resource",
+			id:   "resource2",
+			move: func(secret, configMap *unstructured.Unstructured) {},
+		},
+		{
+			name: "other entry",
+			id:   "resource1",
+			move: func(secret, configMap *unstructured.Unstructured) {
+				data := secret.Object["data"].(map[string]interface{})
+				data["password"], data["token"] = data["token"], data["password"]
+			},
+		},
+		{
+			name: "other field",
+			id:   "resource1",
+			move: func(secret, configMap *unstructured.Unstructured) {
+				secret.Object["stringData"] = secret.Object["data"].(map[string]interface{})["password"]
+			},
+		},
+		{
+			name: "other object",
+			id:   "resource1",
+			move: func(secret, configMap *unstructured.Unstructured) {
+				configMap.Object["data"].(map[string]interface{})["password"] = secret.Object["data"].(map[string]interface{})["password"]
+			},
+		},
+		{
+			name: "renamed object",
+			id:   "resource1",
+			move: func(secret, configMap *unstructured.Unstructured) {
+				secret.SetName("other")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := newSecret()
+			configMap := newConfigMap()
+			enc, err := e.Encrypt("resource1", secret, configMap)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.move(secret, configMap)
+			if err := e.Decrypt(enc, tt.id, secret, configMap); err == nil {
+				t.Error("moved value decrypted")
+			}
+		})
+	}
+}
+
+func TestDecryptLegacy(t *testing.T) {
+	e := newTestEncryptor(t, newTestKey(t, "key-1"))
+
+	// values encrypted without additional data are still decrypted
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		t.Fatal(err)
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := seal(aead, []byte(`"c2VjcmV0"`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrapped, keyID, err := e.kms.WrapKey(dataKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret := newSecret()
+	secret.Object["data"].(map[string]interface{})["password"] = legacyValuePrefix + base64.StdEncoding.EncodeToString(sealed)
+	if err := e.Decrypt(&db.Encryption{KeyId: keyID, WrappedKey: wrapped}, "resource1", secret); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(secret, newSecret()) {
+		t.Errorf("got secret %v, want %v", secret.Object, newSecret().Object)
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	key1 := newTestKey(t, "key-1")
+	e := newTestEncryptor(t, key1)
+	hash := "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+
+	keyed := e.ContentHash(hash)
+	if !strings.HasPrefix(keyed, hashPrefix) || strings.Contains(keyed, hash[len("sha256:"):]) {
+		t.Errorf("got %q, want a hash keyed by the KMS", keyed)
+	}
+	if got := newTestEncryptor(t, key1).ContentHash(hash); got != keyed {
+		t.Errorf("got %q with the same key, want %q", got, keyed)
+	}
+	if got := newTestEncryptor(t, newTestKey(t, "key-2"), key1).ContentHash(hash); got == keyed {
+		t.Error("got the same hash with another primary key")
+	}
+	if got := (&Encryptor{}).ContentHash(hash); got != hash {
+		t.Errorf("got %q without configuration, want %q", got, hash)
+	}
+}
+
+func TestSeal(t *testing.T) {
+	e := newTestEncryptor(t, newTestKey(t, "key-1"))
+
+	secret := newSecret()
+	enc, err := e.Encrypt("resource1", secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := secret.DeepCopy()
+
+	// consumers without public key get the objects decrypted, the stored
+	// ones stay encrypted
+	objects, contentEncryption, err := e.Seal(enc, "resource1", "", secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contentEncryption != nil {
+		t.Errorf("got content encryption %+v, want nil", contentEncryption)
+	}
+	if !reflect.DeepEqual(objects[0], newSecret()) {
+		t.Errorf("got %v, want %v", objects[0].Object, newSecret().Object)
+	}
+	if !reflect.DeepEqual(secret, stored) {
+		t.Error("sealing decrypted the stored object")
+	}
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	objects, contentEncryption, err = e.Seal(enc, "resource1", publicKey, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(objects[0], stored) {
+		t.Errorf("got %v, want the encrypted object", objects[0].Object)
+	}
+	if contentEncryption == nil || contentEncryption.Algorithm != Algorithm {
+		t.Fatalf("got content encryption %+v, want %s", contentEncryption, Algorithm)
+	}
+
+	// the agent decrypts the values with the data key it unwraps
+	dataKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, privateKey, contentEncryption.WrappedKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := objects[0].Object["data"].(map[string]interface{})["password"].(string)
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, valuePrefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := open(aead, sealed, []byte(`["resource1","Secret","","credentials","data","password"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != `"c2VjcmV0"` {
+		t.Errorf("got value %s, want \"c2VjcmV0\"", plaintext)
+	}
+}
+
+func TestCheckPublicKey(t *testing.T) {
+	smallKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&smallKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		publicKey string
+		wantErr   string
+	}{
+		{name: "not PEM", publicKey: "key", wantErr: "PEM"},
+		{name: "too small", publicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), wantErr: "2048"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckPublicKey(tt.publicKey)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := writeFile(t, dir, "keys.json", LocalKeys{Keys: []LocalKey{newTestKey(t, "key-1")}})
+	shortKey := writeFile(t, dir, "short.json", LocalKeys{Keys: []LocalKey{{Id: "key-1", Key: "c2hvcnQ="}}})
+
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{name: "unknown provider", config: Config{KMS: KMSConfig{Provider: "vault"}}, wantErr: "unknown provider"},
+		{name: "missing key file", config: Config{KMS: KMSConfig{Provider: "local", KeyFile: filepath.Join(dir, "missing.json")}}, wantErr: "missing.json"},
+		{name: "short key", config: Config{KMS: KMSConfig{Provider: "local", KeyFile: shortKey}}, wantErr: "32 base64 encoded bytes"},
+		{
+			name:    "missing kind",
+			config:  Config{KMS: KMSConfig{Provider: "local", KeyFile: keyFile}, Resources: []Resource{{Paths: []string{".data"}}}},
+			wantErr: "kind is required",
+		},
+		{
+			name:    "invalid path",
+			config:  Config{KMS: KMSConfig{Provider: "local", KeyFile: keyFile}, Resources: []Resource{{Kind: "Secret", Paths: []string{".data..key"}}}},
+			wantErr: "invalid path",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(encryptionConfigFile, writeFile(t, dir, "config.json", tt.config))
+			_, err := New()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// newTestEncryptor returns an encryptor of the local KMS with keys,
+// encrypting Secrets and the password of ConfigMaps.
+func newTestEncryptor(t *testing.T, keys ...LocalKey) *Encryptor {
+	t.Helper()
+	dir := t.TempDir()
+	config := Config{
+		KMS: KMSConfig{Provider: "local", KeyFile: writeFile(t, dir, "keys.json", LocalKeys{Keys: keys})},
+		Resources: []Resource{
+			{Kind: "Secret", Paths: []string{".data", ".stringData"}},
+			{Kind: "ConfigMap", Paths: []string{".data.password"}},
+		},
+	}
+	t.Setenv(encryptionConfigFile, writeFile(t, dir, "config.json", config))
+
+	e, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func newTestKey(t *testing.T, id string) LocalKey {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return LocalKey{Id: id, Key: base64.StdEncoding.EncodeToString(key)}
+}
+
+func writeFile(t *testing.T, dir, name string, value interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newSecret() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "credentials"},
+		"data": map[string]interface{}{
+			"password": "c2VjcmV0",
+			"token":    "dG9rZW4=",
+		},
+		"stringData": "plain",
+	}}
+}
+
+func newConfigMap() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "settings"},
+		"data": map[string]interface{}{
+			"host":     "db",
+			"password": "secret",
+			"port":     int64(5432),
+		},
+	}}
+}
@@ -0,0 +1,139 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// KMS wraps the data keys encrypting the values of resources, so they can
+// be stored next to the values they encrypt.
+type KMS interface {
+	// WrapKey encrypts a data key, returning it with the Id of the key
+	// encrypting it.
+	WrapKey(dataKey []byte) (wrapped []byte, keyID string, err error)
+	// UnwrapKey decrypts a data key wrapped by the key keyID.
+	UnwrapKey(keyID string, wrapped []byte) ([]byte, error)
+	// HashKey returns the key of the content hashes, it changes with the
+	// primary key.
+	HashKey() ([]byte, error)
+}
+
+// LocalKeys is a key file of the local KMS.
+type LocalKeys struct {
+	// keys of the KMS, the first one wraps new data keys, the others are
+	// kept to unwrap the data keys they wrapped before a rotation.
+	Keys []LocalKey `json:"keys"`
+}
+
+type LocalKey struct {
+	Id string `json:"id"`
+	// base64 encoded 32 bytes AES-256 key.
+	Key string `json:"key"`
+}
+
+// hashKeyInfo derives the key of the content hashes from the primary key of
+// the local KMS.
+const hashKeyInfo = "maestro content hash"
+
+// localKMS wraps data keys with AES-256-GCM keys read from a file, it is
+// meant for development and testing.
+type localKMS struct {
+	primary string
+	keys    map[string]cipher.AEAD
+	hashKey []byte
+}
+
+func newLocalKMS(path string) (*localKMS, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := &LocalKeys{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	if err := decoder.Decode(keys); err != nil {
+		return nil, fmt.Errorf("failed to read key file %s: %w", path, err)
+	}
+	if len(keys.Keys) == 0 {
+		return nil, fmt.Errorf("key file %s: at least one key is required", path)
+	}
+
+	k := &localKMS{primary: keys.Keys[0].Id, keys: map[string]cipher.AEAD{}}
+	for i, key := range keys.Keys {
+		if key.Id == "" {
+			return nil, fmt.Errorf("key file %s: keys[%d]: id is required", path, i)
+		}
+		if _, ok := k.keys[key.Id]; ok {
+			return nil, fmt.Errorf("key file %s: keys[%d]: duplicate id %q", path, i, key.Id)
+		}
+		secret, err := base64.StdEncoding.DecodeString(key.Key)
+		if err != nil || len(secret) != 32 {
+			return nil, fmt.Errorf("key file %s: keys[%d]: key must be 32 base64 encoded bytes", path, i)
+		}
+		k.keys[key.Id], err = newAEAD(secret)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			mac := hmac.New(sha256.New, secret)
+			mac.Write([]byte(hashKeyInfo))
+			k.hashKey = mac.Sum(nil)
+		}
+	}
+	return k, nil
+}
+
+func (k *localKMS) WrapKey(dataKey []byte) ([]byte, string, error) {
+	wrapped, err := seal(k.keys[k.primary], dataKey, nil)
+	return wrapped, k.primary, err
+}
+
+func (k *localKMS) UnwrapKey(keyID string, wrapped []byte) ([]byte, error) {
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", keyID)
+	}
+	return open(aead, wrapped, nil)
+}
+
+func (k *localKMS) HashKey() ([]byte, error) {
+	return k.hashKey, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext with a random nonce and the additional data aad,
+// returning the nonce followed by the ciphertext.
+func seal(aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// open decrypts the output of seal with the same additional data.
+func open(aead cipher.AEAD, sealed, aad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, aad)
+}
@@ -0,0 +1,140 @@
+package limits
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/kube-orchestra/maestro/internal/db"
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const resourceLimitsFile = "RESOURCE_LIMITS_FILE"
+
+// Limits caps the resources of the consumers matching label selectors.
+type Limits struct {
+	Limits []Limit `json:"limits"`
+}
+
+// Limit applies to the consumers matching ConsumerSelector. Zero or empty
+// fields don't limit anything.
+type Limit struct {
+	// label selector of the consumers the limit applies to, empty for every
+	// consumer.
+	ConsumerSelector string `json:"consumerSelector,omitempty"`
+	// maximum number of resources created for a consumer.
+	MaxResources int64 `json:"maxResources,omitempty"`
+	// maximum size of the JSON encoded object of a resource.
+	MaxManifestBytes int64 `json:"maxManifestBytes,omitempty"`
+	// kinds of the objects, as "Kind.group", e.g. "Deployment.apps", or
+	// "Kind" for the core group.
+	AllowedKinds []string `json:"allowedKinds,omitempty"`
+	// namespaces of the namespaced objects. Cluster scoped objects are only
+	// restricted by AllowedKinds.
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+
+	selector labels.Selector
+}
+
+// New loads the limits file RESOURCE_LIMITS_FILE. Without file, nothing is
+// limited.
+func New() (*Limits, error) {
+	path := os.Getenv(resourceLimitsFile)
+	if path == "" {
+		return &Limits{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Limits{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	if err := decoder.Decode(l); err != nil {
+		return nil, fmt.Errorf("failed to read resource limits %s: %w", path, err)
+	}
+
+	for i := range l.Limits {
+		limit := &l.Limits[i]
+		limit.selector, err = labels.Parse(limit.ConsumerSelector)
+		if err != nil {
+			return nil, fmt.Errorf("resource limits %s: limits[%d]: invalid consumerSelector: %w", path, i, err)
+		}
+	}
+	return l, nil
+}
+
+// For returns the first limit whose selector matches the labels of the
+// consumer, an empty limit if none does.
+func (l *Limits) For(consumer *v1.Consumer) *Limit {
+	set := labels.Set{}
+	for _, label := range consumer.Labels {
+		set[label.Key] = label.Value
+	}
+
+	for i := range l.Limits {
+		if l.Limits[i].selector.Matches(set) {
+			return &l.Limits[i]
+		}
+	}
+	return &Limit{}
+}
+
+// Admit checks the size, kind and namespace of obj, reporting violations
+// relative to fieldPath. It returns a *db.ErrorInvalidArgument listing
+// every violation found, or nil.
+func (l *Limit) Admit(fieldPath string, obj *unstructured.Unstructured) error {
+	fldPath := field.NewPath(fieldPath)
+	var errs field.ErrorList
+
+	if l.MaxManifestBytes > 0 {
+		data, err := json.Marshal(obj.Object)
+		if err != nil {
+			return err
+		}
+		if size := int64(len(data)); size > l.MaxManifestBytes {
+			errs = append(errs, field.TooLong(fldPath, fmt.Sprintf("<%d bytes>", size), int(l.MaxManifestBytes)))
+		}
+	}
+
+	if len(l.AllowedKinds) > 0 {
+		gk := obj.GroupVersionKind().GroupKind()
+		if !contains(l.AllowedKinds, gk.String()) {
+			errs = append(errs, field.NotSupported(fldPath.Child("kind"), gk.String(), l.AllowedKinds))
+		}
+	}
+
+	if namespace := obj.GetNamespace(); namespace != "" && len(l.AllowedNamespaces) > 0 {
+		if !contains(l.AllowedNamespaces, namespace) {
+			errs = append(errs, field.NotSupported(fldPath.Child("metadata", "namespace"), namespace, l.AllowedNamespaces))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	invalid := &db.ErrorInvalidArgument{}
+	for _, e := range errs {
+		invalid.Violations = append(invalid.Violations, db.FieldViolation{
+			Field:       e.Field,
+			Description: e.ErrorBody(),
+		})
+	}
+	return invalid
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
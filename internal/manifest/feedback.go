@@ -0,0 +1,150 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/kube-orchestra/maestro/internal/db"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// wellKnownStatus lists the fields reported by WellKnownStatus rules.
+var wellKnownStatus = map[schema.GroupKind][]db.JsonPath{
+	{Group: "apps", Kind: "Deployment"}: {
+		{Name: "replicas", Path: ".status.replicas"},
+		{Name: "readyReplicas", Path: ".status.readyReplicas"},
+		{Name: "availableReplicas", Path: ".status.availableReplicas"},
+		{Name: "updatedReplicas", Path: ".status.updatedReplicas"},
+		{Name: "observedGeneration", Path: ".status.observedGeneration"},
+	},
+	{Group: "apps", Kind: "StatefulSet"}: {
+		{Name: "replicas", Path: ".status.replicas"},
+		{Name: "readyReplicas", Path: ".status.readyReplicas"},
+		{Name: "availableReplicas", Path: ".status.availableReplicas"},
+		{Name: "updatedReplicas", Path: ".status.updatedReplicas"},
+		{Name: "observedGeneration", Path: ".status.observedGeneration"},
+	},
+	{Group: "apps", Kind: "DaemonSet"}: {
+		{Name: "desiredNumberScheduled", Path: ".status.desiredNumberScheduled"},
+		{Name: "numberReady", Path: ".status.numberReady"},
+		{Name: "numberAvailable", Path: ".status.numberAvailable"},
+		{Name: "updatedNumberScheduled", Path: ".status.updatedNumberScheduled"},
+		{Name: "observedGeneration", Path: ".status.observedGeneration"},
+	},
+	{Group: "batch", Kind: "Job"}: {
+		{Name: "active", Path: ".status.active"},
+		{Name: "succeeded", Path: ".status.succeeded"},
+		{Name: "failed", Path: ".status.failed"},
+	},
+	{Group: "", Kind: "Pod"}: {
+		{Name: "phase", Path: ".status.phase"},
+	},
+	{Group: "", Kind: "PersistentVolumeClaim"}: {
+		{Name: "phase", Path: ".status.phase"},
+	},
+	{Group: "", Kind: "Namespace"}: {
+		{Name: "phase", Path: ".status.phase"},
+	},
+}
+
+// ApplyFeedback keeps only the values selected by the feedback rules of
+// res in a status reported by its agent: they are extracted from the
+// content status when the agent didn't report them. The status of
+// resources without rules is left as is.
+func ApplyFeedback(res *db.Resource, status *db.StatusMessage) {
+	if len(res.FeedbackRules) == 0 {
+		return
+	}
+	if len(status.StatusFeedback) == 0 {
+		paths := FeedbackPaths(res.Object.GroupVersionKind().GroupKind(), res.FeedbackRules)
+		status.StatusFeedback = ExtractFeedback(paths, map[string]interface{}{
+			"status": status.ContentStatus,
+		})
+	}
+	status.ContentStatus = nil
+}
+
+// WellKnownStatusPaths returns the fields reported by WellKnownStatus rules
+// for gk, false if gk has none.
+func WellKnownStatusPaths(gk schema.GroupKind) ([]db.JsonPath, bool) {
+	paths, ok := wellKnownStatus[gk]
+	return paths, ok
+}
+
+// FeedbackPaths resolves the rules of an object of kind gk into the
+// JSONPaths sent to the agent.
+func FeedbackPaths(gk schema.GroupKind, rules []db.FeedbackRule) []db.JsonPath {
+	var paths []db.JsonPath
+	for _, rule := range rules {
+		switch rule.Type {
+		case db.FeedbackRuleWellKnownStatus:
+			paths = append(paths, wellKnownStatus[gk]...)
+		case db.FeedbackRuleJSONPaths:
+			paths = append(paths, rule.JsonPaths...)
+		}
+	}
+	return paths
+}
+
+// ParseFeedbackPath checks path is a valid Kubernetes JSONPath.
+func ParseFeedbackPath(path string) (*jsonpath.JSONPath, error) {
+	j := jsonpath.New("feedback")
+	j.AllowMissingKeys(true)
+	if err := j.Parse(fmt.Sprintf("{%s}", path)); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// ExtractFeedback evaluates paths against obj. Paths that don't match any
+// field are left out.
+func ExtractFeedback(paths []db.JsonPath, obj map[string]interface{}) []db.FeedbackValue {
+	var values []db.FeedbackValue
+	for _, p := range paths {
+		j, err := ParseFeedbackPath(p.Path)
+		if err != nil {
+			continue
+		}
+
+		results, err := j.FindResults(obj)
+		if err != nil || len(results) == 0 || len(results[0]) == 0 {
+			continue
+		}
+
+		var found []interface{}
+		for _, r := range results[0] {
+			found = append(found, r.Interface())
+		}
+
+		var value db.FieldValue
+		if len(found) == 1 {
+			value = toFieldValue(found[0])
+		} else {
+			value = toFieldValue(found)
+		}
+		values = append(values, db.FeedbackValue{Name: p.Name, Value: value})
+	}
+	return values
+}
+
+func toFieldValue(v interface{}) db.FieldValue {
+	switch v := v.(type) {
+	case int64:
+		return db.FieldValue{Type: db.FieldValueInteger, Integer: &v}
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < math.MaxInt64 {
+			i := int64(v)
+			return db.FieldValue{Type: db.FieldValueInteger, Integer: &i}
+		}
+	case string:
+		return db.FieldValue{Type: db.FieldValueString, String: &v}
+	case bool:
+		return db.FieldValue{Type: db.FieldValueBoolean, Boolean: &v}
+	}
+
+	raw, _ := json.Marshal(v)
+	jsonRaw := string(raw)
+	return db.FieldValue{Type: db.FieldValueJsonRaw, JsonRaw: &jsonRaw}
+}
@@ -0,0 +1,88 @@
+package manifest
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/kube-orchestra/maestro/internal/db"
+)
+
+func TestExtractFeedback(t *testing.T) {
+	obj := map[string]interface{}{
+		"status": map[string]interface{}{
+			"replicas": float64(3),
+			"phase":    "Running",
+			"ready":    true,
+			"ratio":    0.5,
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Available", "status": "True"},
+				map[string]interface{}{"type": "Progressing", "status": "False"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want *db.FieldValue
+	}{
+		{
+			name: "integer",
+			path: ".status.replicas",
+			want: &db.FieldValue{Type: db.FieldValueInteger, Integer: int64Ptr(3)},
+		},
+		{
+			name: "string",
+			path: ".status.phase",
+			want: &db.FieldValue{Type: db.FieldValueString, String: stringPtr("Running")},
+		},
+		{
+			name: "boolean",
+			path: ".status.ready",
+			want: &db.FieldValue{Type: db.FieldValueBoolean, Boolean: boolPtr(true)},
+		},
+		{
+			name: "fraction",
+			path: ".status.ratio",
+			want: &db.FieldValue{Type: db.FieldValueJsonRaw, JsonRaw: stringPtr("0.5")},
+		},
+		{
+			name: "several matches",
+			path: ".status.conditions[*].status",
+			want: &db.FieldValue{Type: db.FieldValueJsonRaw, JsonRaw: stringPtr(`["True","False"]`)},
+		},
+		{
+			name: "missing",
+			path: ".status.missing",
+		},
+		{
+			name: "invalid",
+			path: ".status[",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := ExtractFeedback([]db.JsonPath{{Name: "value", Path: tt.path}}, obj)
+			if tt.want == nil {
+				if len(values) != 0 {
+					t.Fatalf("got %v, want no value", values)
+				}
+				return
+			}
+			if len(values) != 1 || values[0].Name != "value" {
+				t.Fatalf("got %v, want a single value", values)
+			}
+			if !reflect.DeepEqual(values[0].Value, *tt.want) {
+				got, _ := json.Marshal(values[0].Value)
+				want, _ := json.Marshal(tt.want)
+				t.Errorf("got %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func int64Ptr(v int64) *int64    { return &v }
+func stringPtr(v string) *string { return &v }
+func boolPtr(v bool) *bool       { return &v }
@@ -0,0 +1,68 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// serverFields are set on the target cluster rather than by the manifest
+// author, and don't count as content changes.
+var serverFields = [][]string{
+	{"metadata", "resourceVersion"},
+	{"metadata", "generation"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "managedFields"},
+	{"status"},
+}
+
+// ContentHash returns the hash of the normalized content of obj, in the
+// form "sha256:<hex>". Manifests that only differ by key order, number
+// representation or server-set fields have the same hash.
+func ContentHash(obj *unstructured.Unstructured) (string, error) {
+	data, err := normalize(obj)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// ListContentHash returns the hash of the normalized content of objs, in
+// the form "sha256:<hex>". The order of objs is part of the content.
+func ListContentHash(objs []unstructured.Unstructured) (string, error) {
+	h := sha256.New()
+	for i := range objs {
+		data, err := normalize(&objs[i])
+		if err != nil {
+			return "", err
+		}
+		sum := sha256.Sum256(data)
+		h.Write(sum[:])
+	}
+
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// normalize encodes obj without its server-set fields.
+func normalize(obj *unstructured.Unstructured) ([]byte, error) {
+	data, err := json.Marshal(obj.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	// work on a copy decoded by encoding/json, so numbers are all float64
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	for _, fields := range serverFields {
+		unstructured.RemoveNestedField(normalized, fields...)
+	}
+
+	// encoding/json sorts map keys, so equal maps encode the same way
+	return json.Marshal(normalized)
+}
@@ -0,0 +1,122 @@
+package manifest
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestContentHash(t *testing.T) {
+	base := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "config", "namespace": "default"},
+		"data":       map[string]interface{}{"replicas": "3"},
+		"spec":       map[string]interface{}{"size": int64(3)},
+	}
+
+	tests := []struct {
+		name     string
+		object   map[string]interface{}
+		wantSame bool
+	}{
+		{
+			name: "number representation",
+			object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "config", "namespace": "default"},
+				"data":       map[string]interface{}{"replicas": "3"},
+				"spec":       map[string]interface{}{"size": float64(3)},
+			},
+			wantSame: true,
+		},
+		{
+			name: "server fields",
+			object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name":              "config",
+					"namespace":         "default",
+					"resourceVersion":   "42",
+					"generation":        int64(2),
+					"creationTimestamp": "2023-01-01T00:00:00Z",
+					"managedFields":     []interface{}{map[string]interface{}{"manager": "kubectl"}},
+				},
+				"data":   map[string]interface{}{"replicas": "3"},
+				"spec":   map[string]interface{}{"size": int64(3)},
+				"status": map[string]interface{}{"phase": "Active"},
+			},
+			wantSame: true,
+		},
+		{
+			name: "content",
+			object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "config", "namespace": "default"},
+				"data":       map[string]interface{}{"replicas": "4"},
+				"spec":       map[string]interface{}{"size": int64(3)},
+			},
+		},
+		{
+			name: "labels",
+			object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "config", "namespace": "default", "labels": map[string]interface{}{"app": "web"}},
+				"data":       map[string]interface{}{"replicas": "3"},
+				"spec":       map[string]interface{}{"size": int64(3)},
+			},
+		},
+	}
+
+	baseHash, err := ContentHash(&unstructured.Unstructured{Object: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(baseHash, "sha256:") || len(baseHash) != len("sha256:")+64 {
+		t.Fatalf("got hash %q, want sha256:<hex>", baseHash)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := ContentHash(&unstructured.Unstructured{Object: tt.object})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (hash == baseHash) != tt.wantSame {
+				t.Errorf("got same hash %v, want %v", hash == baseHash, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestListContentHash(t *testing.T) {
+	a := unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "a"}}}
+	b := unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "b"}}}
+	aWithStatus := unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "a", "resourceVersion": "7"}, "status": map[string]interface{}{}}}
+
+	hash := func(objs ...unstructured.Unstructured) string {
+		t.Helper()
+		h, err := ListContentHash(objs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+
+	if hash(a, b) != hash(aWithStatus, b) {
+		t.Errorf("got different hashes for lists differing by server fields")
+	}
+	if hash(a, b) == hash(b, a) {
+		t.Errorf("got same hash for lists in a different order")
+	}
+	if hash(a) == hash(a, b) {
+		t.Errorf("got same hash for lists of a different length")
+	}
+	if hash() == hash(a) {
+		t.Errorf("got same hash for an empty list")
+	}
+}
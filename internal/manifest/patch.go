@@ -0,0 +1,73 @@
+package manifest
+
+import (
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// builtins holds the Go types of the built-in kinds, which carry the patch
+// strategies used by strategic merge patches.
+var builtins = runtime.NewScheme()
+
+func init() {
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		admissionregistrationv1.AddToScheme,
+		appsv1.AddToScheme,
+		autoscalingv2.AddToScheme,
+		batchv1.AddToScheme,
+		coordinationv1.AddToScheme,
+		corev1.AddToScheme,
+		discoveryv1.AddToScheme,
+		networkingv1.AddToScheme,
+		policyv1.AddToScheme,
+		rbacv1.AddToScheme,
+		schedulingv1.AddToScheme,
+		storagev1.AddToScheme,
+	} {
+		if err := addToScheme(builtins); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// MergePatch applies an RFC 7386 JSON merge patch to the JSON document
+// original.
+func MergePatch(original, patch []byte) ([]byte, error) {
+	return jsonpatch.MergePatch(original, patch)
+}
+
+// JSONPatch applies an RFC 6902 JSON patch to the JSON document original.
+func JSONPatch(original, patch []byte) ([]byte, error) {
+	ops, err := jsonpatch.DecodePatch(patch)
+	if err != nil {
+		return nil, err
+	}
+	return ops.Apply(original)
+}
+
+// StrategicMergePatch applies a Kubernetes strategic merge patch to the
+// JSON document original, an object of kind gvk. Only built-in kinds
+// are supported, custom resources don't declare patch strategies.
+func StrategicMergePatch(gvk schema.GroupVersionKind, original, patch []byte) ([]byte, error) {
+	dataStruct, err := builtins.New(gvk)
+	if err != nil {
+		return nil, fmt.Errorf("strategic merge patch is not supported for %s, use a merge patch instead", gvk)
+	}
+	return strategicpatch.StrategicMergePatch(original, patch, dataStruct)
+}
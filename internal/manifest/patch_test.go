@@ -0,0 +1,150 @@
+package manifest
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const deployment = `{
+  "apiVersion": "apps/v1",
+  "kind": "Deployment",
+  "metadata": {"name": "web", "labels": {"app": "web", "tier": "frontend"}},
+  "spec": {
+    "replicas": 1,
+    "template": {
+      "spec": {
+        "containers": [
+          {"name": "web", "image": "web:1"},
+          {"name": "proxy", "image": "proxy:1"}
+        ]
+      }
+    }
+  }
+}`
+
+func TestPatch(t *testing.T) {
+	deploymentGVK := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+
+	tests := []struct {
+		name    string
+		apply   func(original, patch []byte) ([]byte, error)
+		patch   string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "merge patch",
+			apply: MergePatch,
+			patch: `{"metadata": {"labels": {"tier": null}}, "spec": {"replicas": 3}}`,
+			want: `{
+			  "apiVersion": "apps/v1",
+			  "kind": "Deployment",
+			  "metadata": {"name": "web", "labels": {"app": "web"}},
+			  "spec": {"replicas": 3, "template": {"spec": {"containers": [{"name": "web", "image": "web:1"}, {"name": "proxy", "image": "proxy:1"}]}}}
+			}`,
+		},
+		{
+			name:  "merge patch replaces lists",
+			apply: MergePatch,
+			patch: `{"spec": {"template": {"spec": {"containers": [{"name": "web", "image": "web:2"}]}}}}`,
+			want: `{
+			  "apiVersion": "apps/v1",
+			  "kind": "Deployment",
+			  "metadata": {"name": "web", "labels": {"app": "web", "tier": "frontend"}},
+			  "spec": {"replicas": 1, "template": {"spec": {"containers": [{"name": "web", "image": "web:2"}]}}}
+			}`,
+		},
+		{
+			name:  "JSON patch",
+			apply: JSONPatch,
+			patch: `[{"op": "replace", "path": "/spec/replicas", "value": 2}, {"op": "remove", "path": "/spec/template/spec/containers/1"}]`,
+			want: `{
+			  "apiVersion": "apps/v1",
+			  "kind": "Deployment",
+			  "metadata": {"name": "web", "labels": {"app": "web", "tier": "frontend"}},
+			  "spec": {"replicas": 2, "template": {"spec": {"containers": [{"name": "web", "image": "web:1"}]}}}
+			}`,
+		},
+		{
+			name:    "JSON patch failed test",
+			apply:   JSONPatch,
+			patch:   `[{"op": "test", "path": "/spec/replicas", "value": 2}]`,
+			wantErr: "testing value /spec/replicas failed",
+		},
+		{
+			name:    "JSON patch move without from",
+			apply:   JSONPatch,
+			patch:   `[{"op": "move", "path": "/spec/replicas"}]`,
+			wantErr: "from",
+		},
+		{
+			name:    "JSON patch not a list",
+			apply:   JSONPatch,
+			patch:   `{"op": "remove", "path": "/spec"}`,
+			wantErr: "cannot unmarshal",
+		},
+		{
+			name: "strategic merge patch merges containers by name",
+			apply: func(original, patch []byte) ([]byte, error) {
+				return StrategicMergePatch(deploymentGVK, original, patch)
+			},
+			patch: `{"spec": {"template": {"spec": {"containers": [{"name": "web", "image": "web:2"}]}}}}`,
+			want: `{
+			  "apiVersion": "apps/v1",
+			  "kind": "Deployment",
+			  "metadata": {"name": "web", "labels": {"app": "web", "tier": "frontend"}},
+			  "spec": {"replicas": 1, "template": {"spec": {"containers": [{"name": "web", "image": "web:2"}, {"name": "proxy", "image": "proxy:1"}]}}}
+			}`,
+		},
+		{
+			name: "strategic merge patch deletes containers by name",
+			apply: func(original, patch []byte) ([]byte, error) {
+				return StrategicMergePatch(deploymentGVK, original, patch)
+			},
+			patch: `{"spec": {"template": {"spec": {"containers": [{"name": "proxy", "$patch": "delete"}]}}}}`,
+			want: `{
+			  "apiVersion": "apps/v1",
+			  "kind": "Deployment",
+			  "metadata": {"name": "web", "labels": {"app": "web", "tier": "frontend"}},
+			  "spec": {"replicas": 1, "template": {"spec": {"containers": [{"name": "web", "image": "web:1"}]}}}
+			}`,
+		},
+		{
+			name: "strategic merge patch of a custom resource",
+			apply: func(original, patch []byte) ([]byte, error) {
+				return StrategicMergePatch(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}, original, patch)
+			},
+			patch:   `{"spec": {"replicas": 3}}`,
+			wantErr: "use a merge patch instead",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patched, err := tt.apply([]byte(deployment), []byte(tt.patch))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got, want interface{}
+			if err := json.Unmarshal(patched, &got); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %s, want %s", patched, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,536 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Schema is the subset of an OpenAPI v3 schema used to validate manifests.
+// CRD structural schemas and the schemas published by the Kubernetes
+// API server (both v2 definitions and v3 components) decode into it.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *SchemaOrBool      `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int64             `json:"minLength,omitempty"`
+	MaxLength            *int64             `json:"maxLength,omitempty"`
+	MinItems             *int64             `json:"minItems,omitempty"`
+	MaxItems             *int64             `json:"maxItems,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+
+	PreserveUnknownFields bool `json:"x-kubernetes-preserve-unknown-fields,omitempty"`
+	IntOrString           bool `json:"x-kubernetes-int-or-string,omitempty"`
+	EmbeddedResource      bool `json:"x-kubernetes-embedded-resource,omitempty"`
+
+	GroupVersionKinds []struct {
+		Group   string `json:"group"`
+		Version string `json:"version"`
+		Kind    string `json:"kind"`
+	} `json:"x-kubernetes-group-version-kind,omitempty"`
+
+	pattern *regexp.Regexp
+	// unsupported keywords of the schema itself, see unsupportedKeywords.
+	unsupported []string
+}
+
+// unsupportedKeywords are the keywords of OpenAPI v3 and Kubernetes schemas
+// that Validate ignores. The anyOf of int-or-string schemas is validated by
+// the extension.
+var unsupportedKeywords = []string{
+	"oneOf", "anyOf", "not", "uniqueItems", "multipleOf", "minProperties", "maxProperties",
+	"x-kubernetes-validations",
+}
+
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	type plain Schema
+	if err := json.Unmarshal(data, (*plain)(s)); err != nil {
+		return err
+	}
+
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return err
+	}
+	for _, k := range unsupportedKeywords {
+		if _, ok := keywords[k]; ok && !(k == "anyOf" && s.IntOrString) {
+			s.unsupported = append(s.unsupported, k)
+		}
+	}
+	return nil
+}
+
+// SchemaOrBool holds additionalProperties, which is either a boolean or
+// the schema of the additional values.
+type SchemaOrBool struct {
+	Allows bool
+	Schema *Schema
+}
+
+func (s *SchemaOrBool) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &s.Allows); err == nil {
+		return nil
+	}
+	s.Allows = true
+	return json.Unmarshal(data, &s.Schema)
+}
+
+// schemaRef is the schema of a kind, together with the definitions its
+// $ref's point to.
+type schemaRef struct {
+	schema      *Schema
+	definitions map[string]*Schema
+}
+
+// loadPath loads every .yaml, .yml and .json document found at path, which
+// is either a file or a directory.
+func (v *Validator) loadPath(path string) error {
+	return filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		switch filepath.Ext(p) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		if err := v.loadFile(p); err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+		return nil
+	})
+}
+
+// schemaDocument holds the fields of the supported documents: CRDs,
+// OpenAPI v2 and OpenAPI v3.
+type schemaDocument struct {
+	Kind        string             `json:"kind"`
+	Definitions map[string]*Schema `json:"definitions"`
+	Components  struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	} `json:"components"`
+	Spec struct {
+		Group string `json:"group"`
+		Scope string `json:"scope"`
+		Names struct {
+			Kind string `json:"kind"`
+		} `json:"names"`
+		Versions []struct {
+			Name   string `json:"name"`
+			Schema struct {
+				OpenAPIV3Schema *Schema `json:"openAPIV3Schema"`
+			} `json:"schema"`
+		} `json:"versions"`
+	} `json:"spec"`
+}
+
+func (v *Validator) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		var doc schemaDocument
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var schemas []*Schema
+		switch {
+		case doc.Kind == "CustomResourceDefinition":
+			err = v.addCRD(&doc)
+			for _, version := range doc.Spec.Versions {
+				schemas = append(schemas, version.Schema.OpenAPIV3Schema)
+			}
+		case doc.Definitions != nil:
+			err = v.addOpenAPI(doc.Definitions, "#/definitions/")
+			for _, s := range doc.Definitions {
+				schemas = append(schemas, s)
+			}
+		case doc.Components.Schemas != nil:
+			err = v.addOpenAPI(doc.Components.Schemas, "#/components/schemas/")
+			for _, s := range doc.Components.Schemas {
+				schemas = append(schemas, s)
+			}
+		default:
+			err = fmt.Errorf("not a CustomResourceDefinition or OpenAPI document")
+		}
+		if err != nil {
+			return err
+		}
+
+		if keywords := unsupported(schemas...); len(keywords) > 0 {
+			log.Printf("Schemas of %s use %s, which manifests aren't validated against", path, strings.Join(keywords, ", "))
+		}
+	}
+}
+
+// unsupported returns the unsupported keywords used by schemas and their
+// children, sorted.
+func unsupported(schemas ...*Schema) []string {
+	found := map[string]bool{}
+	var visit func(s *Schema)
+	visit = func(s *Schema) {
+		if s == nil {
+			return
+		}
+		for _, k := range s.unsupported {
+			found[k] = true
+		}
+		visit(s.Items)
+		for _, sub := range s.AllOf {
+			visit(sub)
+		}
+		for _, p := range s.Properties {
+			visit(p)
+		}
+		if s.AdditionalProperties != nil {
+			visit(s.AdditionalProperties.Schema)
+		}
+	}
+	for _, s := range schemas {
+		visit(s)
+	}
+
+	keywords := make([]string, 0, len(found))
+	for k := range found {
+		keywords = append(keywords, k)
+	}
+	sort.Strings(keywords)
+	return keywords
+}
+
+func (v *Validator) addCRD(doc *schemaDocument) error {
+	gk := schema.GroupKind{Group: doc.Spec.Group, Kind: doc.Spec.Names.Kind}
+	if doc.Spec.Scope == "Cluster" {
+		v.clusterScoped[gk] = true
+	}
+
+	for _, version := range doc.Spec.Versions {
+		s := version.Schema.OpenAPIV3Schema
+		if s == nil {
+			continue
+		}
+		if err := compile(s); err != nil {
+			return fmt.Errorf("%s: %w", gk.WithVersion(version.Name), err)
+		}
+		v.schemas[gk.WithVersion(version.Name)] = &schemaRef{schema: s}
+	}
+
+	return nil
+}
+
+func (v *Validator) addOpenAPI(schemas map[string]*Schema, refPrefix string) error {
+	definitions := make(map[string]*Schema, len(schemas))
+	for name, s := range schemas {
+		if err := compile(s); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		definitions[refPrefix+name] = s
+	}
+	if err := checkRefs(definitions); err != nil {
+		return err
+	}
+
+	for _, s := range schemas {
+		for _, gvk := range s.GroupVersionKinds {
+			v.schemas[schema.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind}] = &schemaRef{
+				schema:      s,
+				definitions: definitions,
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkRefs returns an error when a definition refers to itself through
+// $ref's and allOf's, which apply to the same value and would be followed
+// without end. Definitions referring to themselves through properties or
+// items are fine, they apply to nested values.
+func checkRefs(definitions map[string]*Schema) error {
+	refs := make([]string, 0, len(definitions))
+	for ref := range definitions {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+
+	checked := map[*Schema]bool{}
+	visiting := map[*Schema]bool{}
+	var visit func(s *Schema, path []string) error
+	visit = func(s *Schema, path []string) error {
+		if s == nil || checked[s] {
+			return nil
+		}
+		if visiting[s] {
+			return fmt.Errorf("$ref cycle: %s", strings.Join(path, " -> "))
+		}
+		visiting[s] = true
+
+		if s.Ref != "" {
+			if err := visit(definitions[s.Ref], append(path, s.Ref)); err != nil {
+				return err
+			}
+		}
+		for _, sub := range s.AllOf {
+			if err := visit(sub, path); err != nil {
+				return err
+			}
+		}
+
+		visiting[s] = false
+		checked[s] = true
+		return nil
+	}
+
+	for _, ref := range refs {
+		if err := visit(definitions[ref], []string{ref}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compile prepares the patterns of s and its children.
+func compile(s *Schema) error {
+	if s == nil {
+		return nil
+	}
+
+	if s.Pattern != "" && s.pattern == nil {
+		p, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", s.Pattern, err)
+		}
+		s.pattern = p
+	}
+
+	children := append([]*Schema{s.Items}, s.AllOf...)
+	for _, p := range s.Properties {
+		children = append(children, p)
+	}
+	if s.AdditionalProperties != nil {
+		children = append(children, s.AdditionalProperties.Schema)
+	}
+	for _, c := range children {
+		if err := compile(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rootFields are accepted on every object, even when the schema doesn't
+// declare them. Kubernetes validates metadata itself and CRD schemas
+// usually leave these out.
+var rootFields = map[string]bool{"apiVersion": true, "kind": true, "metadata": true}
+
+func (r *schemaRef) validateRoot(fldPath *field.Path, obj map[string]interface{}) field.ErrorList {
+	return r.validate(fldPath, obj, r.schema, true)
+}
+
+// resolve follows the $ref's of s, the definitions having no cycle, see
+// checkRefs.
+func (r *schemaRef) resolve(s *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		s = r.definitions[s.Ref]
+	}
+	return s
+}
+
+func (r *schemaRef) validate(fldPath *field.Path, value interface{}, s *Schema, root bool) field.ErrorList {
+	s = r.resolve(s)
+	if s == nil {
+		return nil
+	}
+
+	var errs field.ErrorList
+	for _, sub := range s.AllOf {
+		errs = append(errs, r.validate(fldPath, value, sub, root)...)
+	}
+
+	if value == nil {
+		if s.Nullable || s.Type == "" {
+			return errs
+		}
+		return append(errs, field.Invalid(fldPath, nil, fmt.Sprintf("must be of type %s", s.Type)))
+	}
+
+	if len(s.Enum) > 0 && !inEnum(value, s.Enum) {
+		errs = append(errs, field.NotSupported(fldPath, value, enumStrings(s.Enum)))
+	}
+
+	// OpenAPI v2 documents use a format instead of the extension.
+	if s.IntOrString || s.Format == "int-or-string" {
+		if _, ok := value.(string); ok {
+			return errs
+		}
+		if n, ok := value.(float64); ok && n == math.Trunc(n) {
+			return errs
+		}
+		if _, ok := value.(int64); ok {
+			return errs
+		}
+		return append(errs, field.Invalid(fldPath, value, "must be an integer or a string"))
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return append(errs, field.Invalid(fldPath, value, "must be of type object"))
+		}
+		errs = append(errs, r.validateObject(fldPath, obj, s, root)...)
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return append(errs, field.Invalid(fldPath, value, "must be of type array"))
+		}
+		if s.MinItems != nil && int64(len(items)) < *s.MinItems {
+			errs = append(errs, field.Invalid(fldPath, len(items), fmt.Sprintf("must have at least %d items", *s.MinItems)))
+		}
+		if s.MaxItems != nil && int64(len(items)) > *s.MaxItems {
+			errs = append(errs, field.TooMany(fldPath, len(items), int(*s.MaxItems)))
+		}
+		for i, item := range items {
+			errs = append(errs, r.validate(fldPath.Index(i), item, s.Items, false)...)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return append(errs, field.Invalid(fldPath, value, "must be of type string"))
+		}
+		if s.MinLength != nil && int64(len(str)) < *s.MinLength {
+			errs = append(errs, field.Invalid(fldPath, str, fmt.Sprintf("must be at least %d characters long", *s.MinLength)))
+		}
+		if s.MaxLength != nil && int64(len(str)) > *s.MaxLength {
+			errs = append(errs, field.TooLong(fldPath, str, int(*s.MaxLength)))
+		}
+		if s.pattern != nil && !s.pattern.MatchString(str) {
+			errs = append(errs, field.Invalid(fldPath, str, fmt.Sprintf("must match %q", s.Pattern)))
+		}
+	case "integer", "number":
+		n, ok := toFloat(value)
+		if !ok || (s.Type == "integer" && n != math.Trunc(n)) {
+			return append(errs, field.Invalid(fldPath, value, fmt.Sprintf("must be of type %s", s.Type)))
+		}
+		if s.Minimum != nil && (n < *s.Minimum || (s.ExclusiveMinimum && n == *s.Minimum)) {
+			errs = append(errs, field.Invalid(fldPath, value, fmt.Sprintf("must be greater than or equal to %v", *s.Minimum)))
+		}
+		if s.Maximum != nil && (n > *s.Maximum || (s.ExclusiveMaximum && n == *s.Maximum)) {
+			errs = append(errs, field.Invalid(fldPath, value, fmt.Sprintf("must be less than or equal to %v", *s.Maximum)))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return append(errs, field.Invalid(fldPath, value, "must be of type boolean"))
+		}
+	}
+
+	return errs
+}
+
+func (r *schemaRef) validateObject(fldPath *field.Path, obj map[string]interface{}, s *Schema, root bool) field.ErrorList {
+	var errs field.ErrorList
+
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			errs = append(errs, field.Required(fldPath.Child(name), ""))
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if (root || s.EmbeddedResource) && rootFields[k] {
+			continue
+		}
+		if p, ok := s.Properties[k]; ok {
+			errs = append(errs, r.validate(fldPath.Child(k), obj[k], p, false)...)
+			continue
+		}
+		switch {
+		case s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil:
+			errs = append(errs, r.validate(fldPath.Key(k), obj[k], s.AdditionalProperties.Schema, false)...)
+		case s.AdditionalProperties != nil && s.AdditionalProperties.Allows:
+		case s.PreserveUnknownFields, len(s.Properties) == 0 && s.AdditionalProperties == nil:
+		default:
+			errs = append(errs, field.Forbidden(fldPath.Child(k), "unknown field"))
+		}
+	}
+
+	return errs
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func inEnum(value interface{}, enum []interface{}) bool {
+	for _, e := range enum {
+		if n, ok := toFloat(value); ok {
+			if m, ok := toFloat(e); ok && n == m {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(e, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func enumStrings(enum []interface{}) []string {
+	values := make([]string, 0, len(enum))
+	for _, e := range enum {
+		values = append(values, strings.TrimSpace(fmt.Sprint(e)))
+	}
+	return values
+}
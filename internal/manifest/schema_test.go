@@ -0,0 +1,242 @@
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kube-orchestra/maestro/internal/db"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// widgetDefinitions is an OpenAPI v2 document defining the Widget kind,
+// with a $ref alias and a definition referring to itself through a
+// property.
+const widgetDefinitions = `{
+  "definitions": {
+    "example.v1.Widget": {
+      "type": "object",
+      "required": ["spec"],
+      "properties": {
+        "spec": {"$ref": "#/definitions/example.v1.WidgetSpec"}
+      },
+      "x-kubernetes-group-version-kind": [{"group": "example.com", "version": "v1", "kind": "Widget"}]
+    },
+    "example.v1.WidgetSpec": {"$ref": "#/definitions/example.v1.Spec"},
+    "example.v1.Spec": {
+      "type": "object",
+      "properties": {
+        "size": {"type": "string", "enum": ["small", "large"]},
+        "replicas": {"type": "integer", "minimum": 0},
+        "port": {"type": "string", "format": "int-or-string"},
+        "name": {"type": "string", "pattern": "^[a-z]+$"},
+        "labels": {"type": "object", "additionalProperties": {"type": "string"}},
+        "child": {"$ref": "#/definitions/example.v1.Spec"}
+      }
+    }
+  }
+}`
+
+func TestLoadRefCycle(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		wantErr  string
+	}{
+		{
+			name:     "self alias",
+			document: `{"definitions": {"A": {"$ref": "#/definitions/A"}}}`,
+			wantErr:  "$ref cycle: #/definitions/A -> #/definitions/A",
+		},
+		{
+			name: "alias cycle",
+			document: `{"definitions": {
+				"A": {"$ref": "#/definitions/B"},
+				"B": {"$ref": "#/definitions/C"},
+				"C": {"$ref": "#/definitions/A"}
+			}}`,
+			wantErr: "$ref cycle: #/definitions/A -> #/definitions/B -> #/definitions/C -> #/definitions/A",
+		},
+		{
+			name: "allOf cycle",
+			document: `{"components": {"schemas": {
+				"A": {"allOf": [{"type": "object"}, {"$ref": "#/components/schemas/B"}]},
+				"B": {"allOf": [{"$ref": "#/components/schemas/A"}]}
+			}}}`,
+			wantErr: "$ref cycle",
+		},
+		{
+			name:     "recursive through properties",
+			document: widgetDefinitions,
+		},
+		{
+			name:     "missing definition",
+			document: `{"definitions": {"A": {"$ref": "#/definitions/B"}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestValidator(t, tt.document)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	v, err := newTestValidator(t, widgetDefinitions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		spec       interface{}
+		wantFields []string
+	}{
+		{
+			name: "valid",
+			spec: map[string]interface{}{
+				"size":     "small",
+				"replicas": int64(2),
+				"port":     "http",
+				"name":     "web",
+				"labels":   map[string]interface{}{"app": "web"},
+				"child":    map[string]interface{}{"port": int64(8080), "child": map[string]interface{}{"size": "large"}},
+			},
+		},
+		{name: "missing required", wantFields: []string{"object.spec"}},
+		{name: "not an object", spec: "small", wantFields: []string{"object.spec"}},
+		{name: "unknown field", spec: map[string]interface{}{"color": "red"}, wantFields: []string{"object.spec.color"}},
+		{name: "not in enum", spec: map[string]interface{}{"size": "medium"}, wantFields: []string{"object.spec.size"}},
+		{name: "below minimum", spec: map[string]interface{}{"replicas": int64(-1)}, wantFields: []string{"object.spec.replicas"}},
+		{name: "not an integer", spec: map[string]interface{}{"replicas": 1.5}, wantFields: []string{"object.spec.replicas"}},
+		{name: "not an int or string", spec: map[string]interface{}{"port": true}, wantFields: []string{"object.spec.port"}},
+		{name: "pattern", spec: map[string]interface{}{"name": "Web"}, wantFields: []string{"object.spec.name"}},
+		{name: "additional property", spec: map[string]interface{}{"labels": map[string]interface{}{"app": int64(1)}}, wantFields: []string{"object.spec.labels[app]"}},
+		{
+			name:       "nested recursive",
+			spec:       map[string]interface{}{"child": map[string]interface{}{"child": map[string]interface{}{"size": "medium"}}},
+			wantFields: []string{"object.spec.child.child.size"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "example.com/v1",
+				"kind":       "Widget",
+				"metadata":   map[string]interface{}{"name": "widget"},
+			}}
+			if tt.spec != nil {
+				obj.Object["spec"] = tt.spec
+			}
+
+			err := v.Validate("object", obj)
+			var invalid *db.ErrorInvalidArgument
+			if len(tt.wantFields) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if !errors.As(err, &invalid) {
+				t.Fatalf("got error %v, want ErrorInvalidArgument", err)
+			}
+			var fields []string
+			for _, violation := range invalid.Violations {
+				fields = append(fields, violation.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.wantFields, ",") {
+				t.Errorf("got violations of %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+// newTestValidator returns a validator loading document from
+// MANIFEST_SCHEMA_PATHS.
+func newTestValidator(t *testing.T, document string) (*Validator, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(path, []byte(document), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(manifestSchemaPaths, path)
+	return NewValidator()
+}
+
+func TestUnsupportedKeywords(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     []string
+	}{
+		{name: "supported", document: widgetDefinitions, want: []string{}},
+		{
+			name: "nested",
+			document: `{"definitions": {"A": {
+				"type": "object",
+				"properties": {
+					"mode": {"oneOf": [{"type": "string"}, {"type": "integer"}]},
+					"tags": {"type": "array", "uniqueItems": true, "items": {"type": "string", "not": {"enum": ["x"]}}},
+					"limits": {"type": "object", "additionalProperties": {"type": "number", "multipleOf": 0.5}}
+				}
+			}}}`,
+			want: []string{"multipleOf", "not", "oneOf", "uniqueItems"},
+		},
+		{
+			name: "int-or-string",
+			document: `{"components": {"schemas": {"A": {"type": "object", "properties": {
+				"port": {"anyOf": [{"type": "integer"}, {"type": "string"}], "x-kubernetes-int-or-string": true},
+				"size": {"anyOf": [{"type": "integer"}, {"type": "string"}]}
+			}}}}}`,
+			want: []string{"anyOf"},
+		},
+		{
+			name: "CRD",
+			document: `{"kind": "CustomResourceDefinition", "spec": {"group": "example.com", "names": {"kind": "Widget"}, "versions": [
+				{"name": "v1", "schema": {"openAPIV3Schema": {"type": "object", "x-kubernetes-validations": [{"rule": "self.a == self.b"}]}}},
+				{"name": "v2", "schema": {"openAPIV3Schema": {"type": "object", "properties": {"spec": {"type": "object", "maxProperties": 3}}}}}
+			]}}`,
+			want: []string{"maxProperties", "x-kubernetes-validations"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc schemaDocument
+			if err := json.Unmarshal([]byte(tt.document), &doc); err != nil {
+				t.Fatal(err)
+			}
+			schemas := []*Schema{}
+			for _, s := range doc.Definitions {
+				schemas = append(schemas, s)
+			}
+			for _, s := range doc.Components.Schemas {
+				schemas = append(schemas, s)
+			}
+			for _, version := range doc.Spec.Versions {
+				schemas = append(schemas, version.Schema.OpenAPIV3Schema)
+			}
+
+			got := unsupported(schemas...)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+
+			// documents using them still load
+			if _, err := newTestValidator(t, tt.document); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
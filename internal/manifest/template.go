@@ -0,0 +1,146 @@
+package manifest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/kube-orchestra/maestro/internal/db"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// templateFuncs are the functions available to templates, besides the
+// text/template builtins.
+var templateFuncs = template.FuncMap{
+	"default": func(fallback, value string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	},
+	"toInt": func(value string) (int64, error) {
+		return strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	},
+	"toFloat": func(value string) (float64, error) {
+		return strconv.ParseFloat(strings.TrimSpace(value), 64)
+	},
+	"toBool": func(value string) (bool, error) {
+		return strconv.ParseBool(strings.TrimSpace(value))
+	},
+}
+
+// Render returns the object of a template: the string values of template
+// holding "{{" are executed as Go templates with values, e.g.
+// "{{ .region }}.example.com". A value made of a single action ending with
+// toInt, toFloat or toBool renders to a number or a boolean instead of a
+// string, e.g. "{{ .replicas | toInt }}". Missing values fail rendering,
+// unless read with index, e.g. `{{ index . "replicas" | default "1" }}`.
+// It returns a *db.ErrorInvalidArgument listing the values that can't be
+// rendered, relative to fieldPath.
+func Render(fieldPath string, tmpl *unstructured.Unstructured, values map[string]string) (*unstructured.Unstructured, error) {
+	if values == nil {
+		values = map[string]string{}
+	}
+
+	invalid := &db.ErrorInvalidArgument{}
+	rendered := renderValue(fieldPath, tmpl.Object, values, invalid)
+	if len(invalid.Violations) > 0 {
+		return nil, invalid
+	}
+	return &unstructured.Unstructured{Object: rendered.(map[string]interface{})}, nil
+}
+
+// CheckTemplate checks the templates of tmpl parse, without rendering
+// them. See Render.
+func CheckTemplate(fieldPath string, tmpl *unstructured.Unstructured) error {
+	invalid := &db.ErrorInvalidArgument{}
+	renderValue(fieldPath, tmpl.Object, nil, invalid)
+	if len(invalid.Violations) > 0 {
+		return invalid
+	}
+	return nil
+}
+
+// renderValue renders the strings of value, or only parses them when
+// values is nil.
+func renderValue(path string, value interface{}, values map[string]string, invalid *db.ErrorInvalidArgument) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		// violations are reported in a stable order
+		sort.Strings(keys)
+
+		rendered := make(map[string]interface{}, len(v))
+		for _, key := range keys {
+			rendered[key] = renderValue(path+"."+key, v[key], values, invalid)
+		}
+		return rendered
+	case []interface{}:
+		rendered := make([]interface{}, len(v))
+		for i, item := range v {
+			rendered[i] = renderValue(fmt.Sprintf("%s[%d]", path, i), item, values, invalid)
+		}
+		return rendered
+	case string:
+		if !strings.Contains(v, "{{") {
+			return v
+		}
+		rendered, err := renderString(v, values)
+		if err != nil {
+			invalid.Violations = append(invalid.Violations, db.FieldViolation{Field: path, Description: err.Error()})
+			return v
+		}
+		return rendered
+	default:
+		return v
+	}
+}
+
+func renderString(s string, values map[string]string) (interface{}, error) {
+	t, err := template.New("").Funcs(templateFuncs).Option("missingkey=error").Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if values == nil {
+		return s, nil
+	}
+
+	out := &strings.Builder{}
+	if err := t.Execute(out, values); err != nil {
+		return nil, err
+	}
+
+	switch typedFunc(t) {
+	case "toInt":
+		return strconv.ParseInt(out.String(), 10, 64)
+	case "toFloat":
+		return strconv.ParseFloat(out.String(), 64)
+	case "toBool":
+		return strconv.ParseBool(out.String())
+	}
+	return out.String(), nil
+}
+
+// typedFunc returns the function ending the pipeline of t when t is made
+// of a single action, e.g. "toInt" for "{{ .replicas | toInt }}".
+func typedFunc(t *template.Template) string {
+	if t.Tree == nil || len(t.Tree.Root.Nodes) != 1 {
+		return ""
+	}
+	action, ok := t.Tree.Root.Nodes[0].(*parse.ActionNode)
+	if !ok || len(action.Pipe.Cmds) == 0 {
+		return ""
+	}
+
+	last := action.Pipe.Cmds[len(action.Pipe.Cmds)-1]
+	if ident, ok := last.Args[0].(*parse.IdentifierNode); ok {
+		return ident.Ident
+	}
+	return ""
+}
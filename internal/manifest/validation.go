@@ -0,0 +1,159 @@
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kube-orchestra/maestro/internal/db"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// Comma separated list of CRD or OpenAPI documents, or directories
+	// containing them, used to validate manifests of the kinds they define.
+	manifestSchemaPaths = "MANIFEST_SCHEMA_PATHS"
+)
+
+// clusterScoped lists the built-in kinds that can't carry a namespace.
+// Kinds defined by CRDs loaded from disk are added according to their scope.
+var clusterScoped = map[schema.GroupKind]bool{
+	{Group: "", Kind: "Namespace"}:                                                  true,
+	{Group: "", Kind: "Node"}:                                                       true,
+	{Group: "", Kind: "PersistentVolume"}:                                           true,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"}:                       true,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"}:                true,
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}:               true,
+	{Group: "apiregistration.k8s.io", Kind: "APIService"}:                           true,
+	{Group: "storage.k8s.io", Kind: "StorageClass"}:                                 true,
+	{Group: "storage.k8s.io", Kind: "CSIDriver"}:                                    true,
+	{Group: "scheduling.k8s.io", Kind: "PriorityClass"}:                             true,
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}: true,
+	{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"}:   true,
+}
+
+// dnsLabelNamed lists the built-in kinds whose names must be DNS-1123
+// labels rather than subdomains.
+var dnsLabelNamed = map[schema.GroupKind]bool{
+	{Group: "", Kind: "Namespace"}: true,
+	{Group: "", Kind: "Service"}:   true,
+}
+
+// Validator checks Kubernetes manifests before they are stored and sent
+// to the agents.
+type Validator struct {
+	schemas       map[schema.GroupVersionKind]*schemaRef
+	clusterScoped map[schema.GroupKind]bool
+}
+
+// NewValidator creates a Validator, loading the schemas found in
+// MANIFEST_SCHEMA_PATHS. Without schemas, only the object identity and
+// metadata are validated.
+func NewValidator() (*Validator, error) {
+	v := &Validator{
+		schemas:       map[schema.GroupVersionKind]*schemaRef{},
+		clusterScoped: map[schema.GroupKind]bool{},
+	}
+	for gk := range clusterScoped {
+		v.clusterScoped[gk] = true
+	}
+
+	paths := os.Getenv(manifestSchemaPaths)
+	if len(paths) == 0 {
+		return v, nil
+	}
+
+	for _, path := range strings.Split(paths, ",") {
+		if err := v.loadPath(strings.TrimSpace(path)); err != nil {
+			return nil, fmt.Errorf("loading manifest schemas: %w", err)
+		}
+	}
+
+	return v, nil
+}
+
+// Validate checks obj, reporting violations relative to fieldPath, the path
+// of the manifest in the request. It returns a *db.ErrorInvalidArgument
+// listing every violation found, or nil.
+func (v *Validator) Validate(fieldPath string, obj *unstructured.Unstructured) error {
+	errs := v.validate(field.NewPath(fieldPath), obj)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	invalid := &db.ErrorInvalidArgument{}
+	for _, e := range errs {
+		invalid.Violations = append(invalid.Violations, db.FieldViolation{
+			Field:       e.Field,
+			Description: e.ErrorBody(),
+		})
+	}
+	return invalid
+}
+
+// ClusterScoped tells whether the objects of a kind have no namespace: the
+// built-in cluster-scoped kinds and those of the CRDs loaded with that
+// scope.
+func (v *Validator) ClusterScoped(gk schema.GroupKind) bool {
+	return v.clusterScoped[gk]
+}
+
+func (v *Validator) validate(fldPath *field.Path, obj *unstructured.Unstructured) field.ErrorList {
+	var errs field.ErrorList
+
+	apiVersion, _, err := unstructured.NestedString(obj.Object, "apiVersion")
+	if err != nil || apiVersion == "" {
+		errs = append(errs, field.Required(fldPath.Child("apiVersion"), "must be a non-empty string"))
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		errs = append(errs, field.Invalid(fldPath.Child("apiVersion"), apiVersion, err.Error()))
+	}
+
+	kind, _, err := unstructured.NestedString(obj.Object, "kind")
+	if err != nil || kind == "" {
+		errs = append(errs, field.Required(fldPath.Child("kind"), "must be a non-empty string"))
+	}
+
+	metadata, ok := obj.Object["metadata"].(map[string]interface{})
+	if !ok {
+		return append(errs, field.Required(fldPath.Child("metadata"), "must be an object"))
+	}
+	if name, _ := metadata["name"].(string); name == "" {
+		return append(errs, field.Required(fldPath.Child("metadata", "name"), "must be a non-empty string"))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	gvk := gv.WithKind(kind)
+	errs = append(errs, v.validateMetadata(fldPath.Child("metadata"), gvk.GroupKind(), obj)...)
+
+	if s, ok := v.schemas[gvk]; ok {
+		errs = append(errs, s.validateRoot(fldPath, obj.Object)...)
+	}
+
+	return errs
+}
+
+func (v *Validator) validateMetadata(fldPath *field.Path, gk schema.GroupKind, obj *unstructured.Unstructured) field.ErrorList {
+	var errs field.ErrorList
+
+	if obj.GetGenerateName() != "" {
+		errs = append(errs, field.Forbidden(fldPath.Child("generateName"), "not supported, set metadata.name instead"))
+	}
+
+	nameFn := apivalidation.NameIsDNSSubdomain
+	if dnsLabelNamed[gk] {
+		nameFn = apivalidation.NameIsDNSLabel
+	}
+
+	// A namespace is optional for namespaced kinds, the agent applies the
+	// object in its default namespace when it is missing.
+	requiresNamespace := !v.clusterScoped[gk] && obj.GetNamespace() != ""
+
+	return append(errs, apivalidation.ValidateObjectMetaAccessor(obj, requiresNamespace, nameFn, fldPath)...)
+}
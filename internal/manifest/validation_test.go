@@ -0,0 +1,180 @@
+package manifest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kube-orchestra/maestro/internal/db"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// widgetCRD defines the cluster-scoped Gadget kind.
+const widgetCRD = `
+kind: CustomResourceDefinition
+spec:
+  group: example.com
+  scope: Cluster
+  names:
+    kind: Gadget
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              size:
+                type: integer
+                maximum: 10
+`
+
+func TestValidate(t *testing.T) {
+	v, err := newTestValidator(t, widgetCRD)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		object     map[string]interface{}
+		wantFields []string
+	}{
+		{
+			name:   "valid",
+			object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "config", "namespace": "default"}},
+		},
+		{
+			name:   "without namespace",
+			object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "config"}},
+		},
+		{
+			name:       "missing identity",
+			object:     map[string]interface{}{"metadata": map[string]interface{}{"name": "config"}},
+			wantFields: []string{"object.apiVersion", "object.kind"},
+		},
+		{
+			name:       "invalid apiVersion",
+			object:     map[string]interface{}{"apiVersion": "a/b/c", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "config"}},
+			wantFields: []string{"object.apiVersion"},
+		},
+		{
+			name:       "missing metadata",
+			object:     map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"},
+			wantFields: []string{"object.metadata"},
+		},
+		{
+			name:       "missing name",
+			object:     map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{}},
+			wantFields: []string{"object.metadata.name"},
+		},
+		{
+			name:       "invalid name",
+			object:     map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "Config_1"}},
+			wantFields: []string{"object.metadata.name"},
+		},
+		{
+			name:       "subdomain name of a DNS label kind",
+			object:     map[string]interface{}{"apiVersion": "v1", "kind": "Service", "metadata": map[string]interface{}{"name": "web.example"}},
+			wantFields: []string{"object.metadata.name"},
+		},
+		{
+			name:       "generateName",
+			object:     map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "config", "generateName": "config-"}},
+			wantFields: []string{"object.metadata.generateName"},
+		},
+		{
+			name:       "namespace of a cluster-scoped kind",
+			object:     map[string]interface{}{"apiVersion": "v1", "kind": "Namespace", "metadata": map[string]interface{}{"name": "team", "namespace": "default"}},
+			wantFields: []string{"object.metadata.namespace"},
+		},
+		{
+			name:       "namespace of a cluster-scoped CRD",
+			object:     map[string]interface{}{"apiVersion": "example.com/v1", "kind": "Gadget", "metadata": map[string]interface{}{"name": "gadget", "namespace": "default"}},
+			wantFields: []string{"object.metadata.namespace"},
+		},
+		{
+			name: "schema of a CRD",
+			object: map[string]interface{}{
+				"apiVersion": "example.com/v1",
+				"kind":       "Gadget",
+				"metadata":   map[string]interface{}{"name": "gadget"},
+				"spec":       map[string]interface{}{"size": int64(11)},
+			},
+			wantFields: []string{"object.spec.size"},
+		},
+		{
+			name: "version without schema",
+			object: map[string]interface{}{
+				"apiVersion": "example.com/v2",
+				"kind":       "Gadget",
+				"metadata":   map[string]interface{}{"name": "gadget"},
+				"spec":       map[string]interface{}{"size": int64(11)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Validate("object", &unstructured.Unstructured{Object: tt.object})
+			if len(tt.wantFields) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			var invalid *db.ErrorInvalidArgument
+			if !errors.As(err, &invalid) {
+				t.Fatalf("got error %v, want ErrorInvalidArgument", err)
+			}
+			var fields []string
+			for _, violation := range invalid.Violations {
+				fields = append(fields, violation.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.wantFields, ",") {
+				t.Errorf("got violations of %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestClusterScoped(t *testing.T) {
+	v, err := newTestValidator(t, widgetCRD)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for gk, want := range map[schema.GroupKind]bool{
+		{Kind: "Namespace"}: true,
+		{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"}: true,
+		{Group: "example.com", Kind: "Gadget"}:                    true,
+		{Kind: "ConfigMap"}:                                       false,
+		{Group: "example.com", Kind: "Widget"}:                    false,
+	} {
+		if got := v.ClusterScoped(gk); got != want {
+			t.Errorf("%s: got cluster-scoped %v, want %v", gk, got, want)
+		}
+	}
+}
+
+func TestNewValidatorInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		wantErr  string
+	}{
+		{name: "not a schema document", document: `{"kind": "ConfigMap"}`, wantErr: "not a CustomResourceDefinition or OpenAPI document"},
+		{name: "invalid pattern", document: `{"definitions": {"A": {"type": "string", "pattern": "("}}}`, wantErr: "invalid pattern"},
+		{name: "not YAML or JSON", document: "{", wantErr: "schema.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestValidator(t, tt.document)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package mqtt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klauspost/compress/zstd"
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
+)
+
+const (
+	// maxMessageBytes bounds the reassembled and decompressed payloads of
+	// the agents.
+	maxMessageBytes = 32 << 20
+	// maxPendingPayloads bounds the payloads whose chunks are being
+	// received.
+	maxPendingPayloads = 1000
+	// chunkTimeout drops the chunks of the payloads not complete in time.
+	chunkTimeout = time.Minute
+	// envelopeOverhead is reserved in each chunk for the other fields of
+	// its envelope.
+	envelopeOverhead = 256
+	// minPayloadBytes is the lowest MQTT_MAX_PAYLOAD_BYTES.
+	minPayloadBytes = 1024
+	// maxChunks bounds the chunks of a payload: chunks of at least
+	// minChunkBytes add up to maxMessageBytes.
+	maxChunks     = maxMessageBytes / minChunkBytes
+	minChunkBytes = 512
+	// maxPendingBytes bounds the size of the chunks being reassembled, all
+	// payloads together.
+	maxPendingBytes = 8 * maxMessageBytes
+)
+
+const (
+	// EncodingGzip is the encoding of gzip compressed envelopes.
+	EncodingGzip = "gzip"
+	// EncodingZstd is the encoding of zstd compressed envelopes.
+	EncodingZstd = "zstd"
+)
+
+// Envelope frames the payloads that are compressed or split into chunks,
+// in both directions. Payloads holding a "data" field are envelopes, other
+// payloads are messages, possibly signed.
+type Envelope struct {
+	// Encoding of Data, once the chunks are reassembled: "gzip", "zstd",
+	// or empty for uncompressed payloads.
+	Encoding string `json:"encoding,omitempty"`
+	// Chunk is set when Data is a part of the payload.
+	Chunk *Chunk `json:"chunk,omitempty"`
+	// base64 encoded payload, or part of the payload.
+	Data []byte `json:"data"`
+}
+
+type Chunk struct {
+	// Id shared by the chunks of a payload.
+	Id string `json:"id"`
+	// Index of the chunk, from 0 to Count-1. Data is the concatenation of
+	// the data of the chunks in order.
+	Index int `json:"index"`
+	Count int `json:"count"`
+}
+
+// encode returns the payloads to publish for payload: payload itself, or
+// envelopes when the agent supports compression or payload is over
+// maxPayloadBytes, 0 meaning no limit.
+func encode(payload []byte, compression v1.Compression, maxPayloadBytes int) ([][]byte, error) {
+	envelope, err := compress(payload, compression)
+	if err != nil {
+		return nil, err
+	}
+
+	if envelope.Encoding == "" && (maxPayloadBytes == 0 || len(payload) <= maxPayloadBytes) {
+		return [][]byte{payload}, nil
+	}
+	if envelope.Encoding != "" {
+		data, err := json.Marshal(envelope)
+		if err != nil {
+			return nil, err
+		}
+		if maxPayloadBytes == 0 || len(data) <= maxPayloadBytes {
+			return [][]byte{data}, nil
+		}
+	}
+
+	// base64 encodes 3 bytes in 4 characters
+	chunkBytes := (maxPayloadBytes - envelopeOverhead) / 4 * 3
+	count := (len(envelope.Data) + chunkBytes - 1) / chunkBytes
+	id := uuid.NewString()
+
+	payloads := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * chunkBytes
+		if end > len(envelope.Data) {
+			end = len(envelope.Data)
+		}
+		data, err := json.Marshal(Envelope{
+			Encoding: envelope.Encoding,
+			Chunk:    &Chunk{Id: id, Index: i, Count: count},
+			Data:     envelope.Data[i*chunkBytes : end],
+		})
+		if err != nil {
+			return nil, err
+		}
+		payloads = append(payloads, data)
+	}
+	return payloads, nil
+}
+
+// compress returns the envelope of payload compressed with compression.
+func compress(payload []byte, compression v1.Compression) (Envelope, error) {
+	var w io.WriteCloser
+	compressed := &bytes.Buffer{}
+	envelope := Envelope{Data: payload}
+	switch compression {
+	case v1.Compression_GZIP:
+		w = gzip.NewWriter(compressed)
+		envelope.Encoding = EncodingGzip
+	case v1.Compression_ZSTD:
+		zw, err := zstd.NewWriter(compressed)
+		if err != nil {
+			return Envelope{}, err
+		}
+		w = zw
+		envelope.Encoding = EncodingZstd
+	default:
+		return envelope, nil
+	}
+
+	if _, err := w.Write(payload); err != nil {
+		return Envelope{}, err
+	}
+	if err := w.Close(); err != nil {
+		return Envelope{}, err
+	}
+	envelope.Data = compressed.Bytes()
+	return envelope, nil
+}
+
+// reassembler decodes the envelopes of the agents.
+type reassembler struct {
+	mu sync.Mutex
+	// payloads whose chunks are being received, by topic and chunk Id.
+	pending map[string]*pendingPayload
+	// size of the chunks of the pending payloads.
+	size int
+}
+
+type pendingPayload struct {
+	encoding string
+	count    int
+	// received chunks, by index.
+	parts   map[int][]byte
+	size    int
+	started time.Time
+}
+
+func newReassembler() *reassembler {
+	return &reassembler{pending: map[string]*pendingPayload{}}
+}
+
+// decode returns the payload of an agent published to topic: data itself
+// when it isn't an envelope, or the decompressed payload of the envelope.
+// It returns nil until every chunk of a payload arrived.
+func (r *reassembler) decode(topic string, data []byte) ([]byte, error) {
+	var envelope struct {
+		Envelope
+		Data *[]byte `json:"data"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil || envelope.Data == nil {
+		// not an envelope, the message decoding reports invalid payloads
+		return data, nil
+	}
+
+	payload := *envelope.Data
+	if envelope.Chunk != nil {
+		var err error
+		payload, err = r.add(topic, envelope.Encoding, envelope.Chunk, payload)
+		if err != nil || payload == nil {
+			return nil, err
+		}
+	}
+
+	switch envelope.Encoding {
+	case "":
+		return payload, nil
+	case EncodingGzip:
+		reader, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip data: %w", err)
+		}
+		return decompress(reader, EncodingGzip)
+	case EncodingZstd:
+		reader, err := zstd.NewReader(bytes.NewReader(payload), zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(maxMessageBytes))
+		if err != nil {
+			return nil, fmt.Errorf("invalid zstd data: %w", err)
+		}
+		defer reader.Close()
+		return decompress(reader, EncodingZstd)
+	default:
+		return nil, fmt.Errorf("unknown encoding %q", envelope.Encoding)
+	}
+}
+
+// decompress reads the payload decompressed by reader, of at most
+// maxMessageBytes.
+func decompress(reader io.Reader, encoding string) ([]byte, error) {
+	decompressed, err := io.ReadAll(io.LimitReader(reader, maxMessageBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s data: %w", encoding, err)
+	}
+	if len(decompressed) > maxMessageBytes {
+		return nil, fmt.Errorf("decompressed payload exceeds %d bytes", maxMessageBytes)
+	}
+	return decompressed, nil
+}
+
+// add stores a chunk, and returns the payload once every chunk arrived.
+func (r *reassembler) add(topic, encoding string, chunk *Chunk, data []byte) ([]byte, error) {
+	if chunk.Id == "" || chunk.Count < 1 || chunk.Count > maxChunks || chunk.Index < 0 || chunk.Index >= chunk.Count {
+		return nil, errors.New("invalid chunk")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for key, p := range r.pending {
+		if now.Sub(p.started) > chunkTimeout {
+			r.drop(key)
+		}
+	}
+
+	key := topic + "/" + chunk.Id
+	p, ok := r.pending[key]
+	if !ok {
+		if len(r.pending) >= maxPendingPayloads {
+			return nil, fmt.Errorf("more than %d chunked payloads are pending", maxPendingPayloads)
+		}
+		p = &pendingPayload{encoding: encoding, count: chunk.Count, parts: map[int][]byte{}, started: now}
+		r.pending[key] = p
+	}
+	if chunk.Count != p.count || encoding != p.encoding {
+		r.drop(key)
+		return nil, errors.New("chunks of the same payload don't match")
+	}
+
+	// a chunk received again replaces the previous one
+	p.size += len(data) - len(p.parts[chunk.Index])
+	r.size += len(data) - len(p.parts[chunk.Index])
+	p.parts[chunk.Index] = data
+	if p.size > maxMessageBytes {
+		r.drop(key)
+		return nil, fmt.Errorf("chunked payload exceeds %d bytes", maxMessageBytes)
+	}
+	if r.size > maxPendingBytes {
+		r.drop(key)
+		return nil, fmt.Errorf("chunked payloads being reassembled exceed %d bytes", maxPendingBytes)
+	}
+	if len(p.parts) < p.count {
+		return nil, nil
+	}
+
+	r.drop(key)
+	payload := make([]byte, 0, p.size)
+	for i := 0; i < p.count; i++ {
+		payload = append(payload, p.parts[i]...)
+	}
+	return payload, nil
+}
+
+// drop forgets the chunks of a pending payload.
+func (r *reassembler) drop(key string) {
+	r.size -= r.pending[key].size
+	delete(r.pending, key)
+}
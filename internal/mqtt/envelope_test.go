@@ -0,0 +1,246 @@
+package mqtt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"testing"
+
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
+)
+
+const testTopic = "v1/default/cluster1/resource1/status"
+
+func TestEncodeDecode(t *testing.T) {
+	small := []byte(`{"resourceGenerationID":1}`)
+	// random bytes don't compress, so gzip payloads are chunked too
+	large := make([]byte, 10000)
+	rand.New(rand.NewSource(1)).Read(large)
+
+	tests := []struct {
+		name            string
+		payload         []byte
+		compression     v1.Compression
+		maxPayloadBytes int
+		wantPayloads    int
+		wantEnvelope    bool
+	}{
+		{name: "as is", payload: small, wantPayloads: 1},
+		{name: "as is under the limit", payload: small, maxPayloadBytes: minPayloadBytes, wantPayloads: 1},
+		{name: "gzip", payload: small, compression: v1.Compression_GZIP, wantPayloads: 1, wantEnvelope: true},
+		{name: "chunked", payload: large, maxPayloadBytes: minPayloadBytes, wantPayloads: 18, wantEnvelope: true},
+		{name: "gzip chunked", payload: large, compression: v1.Compression_GZIP, maxPayloadBytes: minPayloadBytes, wantPayloads: 18, wantEnvelope: true},
+		{name: "zstd", payload: small, compression: v1.Compression_ZSTD, wantPayloads: 1, wantEnvelope: true},
+		{name: "zstd chunked", payload: large, compression: v1.Compression_ZSTD, maxPayloadBytes: minPayloadBytes, wantPayloads: 18, wantEnvelope: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payloads, err := encode(tt.payload, tt.compression, tt.maxPayloadBytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(payloads) != tt.wantPayloads {
+				t.Fatalf("got %d payloads, want %d", len(payloads), tt.wantPayloads)
+			}
+			for _, p := range payloads {
+				if tt.maxPayloadBytes > 0 && len(p) > tt.maxPayloadBytes {
+					t.Errorf("payload of %d bytes exceeds %d", len(p), tt.maxPayloadBytes)
+				}
+			}
+			if got := !bytes.Equal(payloads[0], tt.payload); got != tt.wantEnvelope {
+				t.Errorf("enveloped = %v, want %v", got, tt.wantEnvelope)
+			}
+
+			decoded := decodeAll(t, newReassembler(), payloads)
+			if !bytes.Equal(decoded, tt.payload) {
+				t.Errorf("decoded payload doesn't match")
+			}
+		})
+	}
+}
+
+func TestDecodeOutOfOrder(t *testing.T) {
+	payload := []byte(strings.Repeat("status ", 1000))
+	payloads, err := encode(payload, v1.Compression_COMPRESSION_UNSPECIFIED, minPayloadBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payloads) < 3 {
+		t.Fatalf("got %d payloads, want several", len(payloads))
+	}
+
+	reversed := make([][]byte, 0, len(payloads)+1)
+	for i := len(payloads) - 1; i >= 0; i-- {
+		reversed = append(reversed, payloads[i])
+	}
+	// duplicated chunk, e.g. redelivered with QoS 1
+	reversed = append([][]byte{payloads[1]}, reversed...)
+
+	decoded := decodeAll(t, newReassembler(), reversed)
+	if !bytes.Equal(decoded, payload) {
+		t.Errorf("decoded payload doesn't match")
+	}
+}
+
+func TestDecodeNotEnvelope(t *testing.T) {
+	for _, data := range []string{`{"reconcileStatus": {}}`, `not json`, `{"data": null}`} {
+		decoded, err := newReassembler().decode(testTopic, []byte(data))
+		if err != nil {
+			t.Fatalf("%s: %v", data, err)
+		}
+		if string(decoded) != data {
+			t.Errorf("%s: got %s", data, decoded)
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		payloads []Envelope
+		wantErr  string
+	}{
+		{
+			name:     "unknown encoding",
+			payloads: []Envelope{{Encoding: "br", Data: []byte("x")}},
+			wantErr:  "unknown encoding",
+		},
+		{
+			name:     "invalid gzip",
+			payloads: []Envelope{{Encoding: EncodingGzip, Data: []byte("x")}},
+			wantErr:  "gzip",
+		},
+		{
+			name:     "invalid zstd",
+			payloads: []Envelope{{Encoding: EncodingZstd, Data: []byte("x")}},
+			wantErr:  "zstd",
+		},
+		{
+			name:     "index out of range",
+			payloads: []Envelope{{Chunk: &Chunk{Id: "a", Index: 2, Count: 2}, Data: []byte("x")}},
+			wantErr:  "invalid chunk",
+		},
+		{
+			name:     "missing id",
+			payloads: []Envelope{{Chunk: &Chunk{Index: 0, Count: 2}, Data: []byte("x")}},
+			wantErr:  "invalid chunk",
+		},
+		{
+			name:     "huge count",
+			payloads: []Envelope{{Chunk: &Chunk{Id: "a", Index: 0, Count: 1 << 62}, Data: []byte("x")}},
+			wantErr:  "invalid chunk",
+		},
+		{
+			name: "mismatched count",
+			payloads: []Envelope{
+				{Chunk: &Chunk{Id: "a", Index: 0, Count: 3}, Data: []byte("x")},
+				{Chunk: &Chunk{Id: "a", Index: 1, Count: 2}, Data: []byte("x")},
+			},
+			wantErr: "don't match",
+		},
+		{
+			name: "mismatched encoding",
+			payloads: []Envelope{
+				{Chunk: &Chunk{Id: "a", Index: 0, Count: 2}, Data: []byte("x")},
+				{Encoding: EncodingGzip, Chunk: &Chunk{Id: "a", Index: 1, Count: 2}, Data: []byte("x")},
+			},
+			wantErr: "don't match",
+		},
+		{
+			name:     "oversize chunk",
+			payloads: []Envelope{{Chunk: &Chunk{Id: "a", Index: 0, Count: 2}, Data: make([]byte, maxMessageBytes+1)}},
+			wantErr:  "exceeds",
+		},
+		{
+			name:     "oversize decompressed",
+			payloads: []Envelope{{Encoding: EncodingGzip, Data: gzipped(t, make([]byte, maxMessageBytes+1))}},
+			wantErr:  "exceeds",
+		},
+		{
+			name:     "oversize zstd decompressed",
+			payloads: []Envelope{compressed(t, make([]byte, maxMessageBytes+1), v1.Compression_ZSTD)},
+			wantErr:  "exceeds",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newReassembler()
+			var err error
+			for _, e := range tt.payloads {
+				data, marshalErr := json.Marshal(e)
+				if marshalErr != nil {
+					t.Fatal(marshalErr)
+				}
+				_, err = r.decode(testTopic, data)
+				if err != nil {
+					break
+				}
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+			if len(r.pending) != 0 || r.size != 0 {
+				t.Errorf("%d payloads of %d bytes still pending", len(r.pending), r.size)
+			}
+		})
+	}
+}
+
+func TestDecodePendingBound(t *testing.T) {
+	r := newReassembler()
+	for i := 0; i < maxPendingPayloads; i++ {
+		data, _ := json.Marshal(Envelope{Chunk: &Chunk{Id: strings.Repeat("a", i+1), Index: 0, Count: 2}, Data: []byte("x")})
+		if _, err := r.decode(testTopic, data); err != nil {
+			t.Fatalf("chunk %d: %v", i, err)
+		}
+	}
+	data, _ := json.Marshal(Envelope{Chunk: &Chunk{Id: "b", Index: 0, Count: 2}, Data: []byte("x")})
+	if _, err := r.decode(testTopic, data); err == nil || !strings.Contains(err.Error(), "pending") {
+		t.Fatalf("got error %v, want too many pending payloads", err)
+	}
+}
+
+// decodeAll decodes payloads in order, and returns the decoded payload of
+// the last one, the others having to be pending chunks.
+func decodeAll(t *testing.T, r *reassembler, payloads [][]byte) []byte {
+	t.Helper()
+	var decoded []byte
+	for i, p := range payloads {
+		var err error
+		decoded, err = r.decode(testTopic, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i < len(payloads)-1 && decoded != nil {
+			t.Fatalf("payload %d decoded before the last chunk", i)
+		}
+	}
+	if decoded == nil {
+		t.Fatal("payload still pending after the last chunk")
+	}
+	return decoded
+}
+
+func compressed(t *testing.T, data []byte, compression v1.Compression) Envelope {
+	t.Helper()
+	envelope, err := compress(data, compression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return envelope
+}
+
+func gzipped(t *testing.T, data []byte) []byte {
+	t.Helper()
+	compressed := &bytes.Buffer{}
+	w := gzip.NewWriter(compressed)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return compressed.Bytes()
+}
@@ -2,12 +2,19 @@ package mqtt
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	mqtt "github.com/eclipse/paho.mqtt.golang"
 	"github.com/kube-orchestra/maestro/internal/db"
+	"github.com/kube-orchestra/maestro/internal/manifest"
+	"github.com/kube-orchestra/maestro/internal/signing"
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
 )
 
 const (
@@ -15,11 +22,27 @@ const (
 	mqttBrokerURL      = "MQTT_BROKER_URL"
 	mqttBrokerUsername = "MQTT_BROKER_USERNAME"
 	mqttBrokerPassword = "MQTT_BROKER_PASSWORD"
+	// payloads over this size are published in chunks, unlimited when
+	// unset.
+	mqttMaxPayloadBytes = "MQTT_MAX_PAYLOAD_BYTES"
+	// "false" stops publishing the content of the default tenant on the
+	// topics of the agents deployed before tenants, and receiving their
+	// status.
+	mqttLegacyTopics = "MQTT_LEGACY_TOPICS"
 )
 
 type Connection struct {
-	Client          mqtt.Client
-	ResourceChannel chan db.ResourceMessage
+	Client                mqtt.Client
+	ResourceChannel       chan db.ResourceMessage
+	ResourceBundleChannel chan db.ResourceBundleMessage
+	// called with a resource, as read before its status report, once the
+	// status is stored, set before StartStatusReceiver.
+	ResourceStatusObservers []func(res *db.Resource)
+	// signs the published messages, set before StartSender.
+	Signer *signing.Signer
+
+	maxPayloadBytes int
+	legacyTopics    bool
 }
 
 func NewConnection() *Connection {
@@ -28,30 +51,107 @@ func NewConnection() *Connection {
 		panic(err)
 	}
 
+	maxPayloadBytes := 0
+	if v := os.Getenv(mqttMaxPayloadBytes); v != "" {
+		maxPayloadBytes, err = strconv.Atoi(v)
+		if err != nil || maxPayloadBytes < minPayloadBytes {
+			panic(fmt.Errorf("%s must be a number of bytes of at least %d", mqttMaxPayloadBytes, minPayloadBytes))
+		}
+	}
+
+	legacyTopics := true
+	if v := os.Getenv(mqttLegacyTopics); v != "" {
+		legacyTopics, err = strconv.ParseBool(v)
+		if err != nil {
+			panic(fmt.Errorf("%s must be true or false", mqttLegacyTopics))
+		}
+	}
+
 	if token := client.Connect(); token.Wait() && token.Error() != nil {
 		panic(token.Error())
 	}
 
 	resourceChan := make(chan db.ResourceMessage)
+	resourceBundleChan := make(chan db.ResourceBundleMessage)
 	return &Connection{
-		Client:          client,
-		ResourceChannel: resourceChan,
+		Client:                client,
+		ResourceChannel:       resourceChan,
+		ResourceBundleChannel: resourceBundleChan,
+		maxPayloadBytes:       maxPayloadBytes,
+		legacyTopics:          legacyTopics,
 	}
 }
 
 func (c *Connection) StartSender() {
 	go func() {
 		for msg := range c.ResourceChannel {
-			topic := fmt.Sprintf("v1/%s/%s/content", msg.ConsumerId, msg.Id)
+			msg.SentTimestamp = time.Now().Unix()
 			msgJson, _ := json.Marshal(msg)
-			token := c.Client.Publish(topic, 1, false, msgJson)
-			token.Wait()
+			for _, topic := range c.contentTopics(msg.TenantId, msg.ConsumerId, msg.Id, false) {
+				c.publish(topic, msgJson, msg.Compression)
+			}
 		}
 	}()
+
+	go func() {
+		for msg := range c.ResourceBundleChannel {
+			msg.SentTimestamp = time.Now().Unix()
+			msgJson, _ := json.Marshal(msg)
+			for _, topic := range c.contentTopics(msg.TenantId, msg.ConsumerId, msg.Id, true) {
+				c.publish(topic, msgJson, msg.Compression)
+			}
+		}
+	}()
+}
+
+// contentTopics returns the topics the content of a resource or bundle is
+// published to: v1/<tenant>/<consumer>/<resource>/content or
+// v1/<tenant>/<consumer>/bundles/<bundle>/content and, for the default
+// tenant, the topics without tenant of the agents deployed before tenants,
+// until legacy topics are disabled.
+func (c *Connection) contentTopics(tenantID, consumerID, id string, bundle bool) []string {
+	tenantID = db.TenantOrDefault(tenantID)
+	path := id
+	if bundle {
+		path = "bundles/" + id
+	}
+
+	topics := []string{fmt.Sprintf("v1/%s/%s/%s/content", tenantID, consumerID, path)}
+	if c.legacyTopics && tenantID == db.DefaultTenant {
+		topics = append(topics, fmt.Sprintf("v1/%s/%s/content", consumerID, path))
+	}
+	return topics
+}
+
+// publish signs payload, when a signing key is configured, and publishes it
+// to topic, compressed with compression and in chunks when it is too large.
+func (c *Connection) publish(topic string, payload []byte, compression v1.Compression) {
+	signed, err := c.Signer.Sign(topic, payload)
+	if err != nil {
+		log.Printf("Failed to sign message of %s: %v", topic, err)
+		return
+	}
+
+	payloads, err := encode(signed, compression, c.maxPayloadBytes)
+	if err != nil {
+		log.Printf("Failed to encode message of %s: %v", topic, err)
+		return
+	}
+	for _, p := range payloads {
+		token := c.Client.Publish(topic, 1, false, p)
+		token.Wait()
+	}
 }
 
+// StartStatusReceiver subscribes to the status topics. The legacy bundle
+// status topics, v1/<consumer>/bundles/<bundle>/status, match the resource
+// status topics.
 func (c *Connection) StartStatusReceiver() {
-	c.Client.Subscribe("v1/+/+/status", 1, messagePubHandler)
+	c.Client.Subscribe("v1/+/+/+/status", 1, c.receiveStatus)
+	c.Client.Subscribe("v1/+/+/bundles/+/status", 1, c.receiveStatus)
+	if c.legacyTopics {
+		c.Client.Subscribe("v1/+/+/status", 1, c.receiveStatus)
+	}
 }
 
 var connectHandler mqtt.OnConnectHandler = func(client mqtt.Client) {
@@ -63,12 +163,169 @@ var connectLostHandler mqtt.ConnectionLostHandler = func(client mqtt.Client, err
 }
 
 var messagePubHandler mqtt.MessageHandler = func(client mqtt.Client, msg mqtt.Message) {
-	topicComponents := strings.Split(msg.Topic(), "/")
+	log.Printf("Ignored message of unexpected topic %s", msg.Topic())
+}
 
-	err := db.SetStatusResource(topicComponents[2], msg.Payload())
+// Tries of a status message while the store is unavailable, and the
+// delays between them. The message is then stored as a dead letter, so the
+// next messages aren't held back for longer.
+const (
+	maxStatusAttempts = 6
+	minRetryDelay     = 100 * time.Millisecond
+	maxRetryDelay     = time.Second
+)
+
+// sleep waits between the tries of a status message, replaced in tests.
+var sleep = time.Sleep
+
+// receiveStatus processes a status message, storing it as a dead letter
+// when it fails. It's tried again, up to maxStatusAttempts times, while the
+// store is unavailable.
+func (c *Connection) receiveStatus(_ mqtt.Client, msg mqtt.Message) {
+	payload, err := statusChunks.decode(msg.Topic(), msg.Payload())
+	if err == nil && payload == nil {
+		// the other chunks of the payload are still to come
+		return
+	}
+
+	if err == nil {
+		err = retryUnavailable(msg.Topic(), func() error {
+			return c.processStatus(msg.Topic(), payload)
+		})
+	}
+	if err == nil {
+		return
+	}
+	log.Printf("Failed to process status message of %s: %v", msg.Topic(), err)
+
+	// messages of unexpected topics go to the default tenant
+	t, _ := parseStatusTopic(msg.Topic())
+	tenantID := t.TenantId
+	if payload == nil {
+		payload = msg.Payload()
+	}
+	if _, err := db.PutDeadLetter(tenantID, msg.Topic(), payload, err); err != nil {
+		log.Printf("Failed to store dead letter of %s: %v", msg.Topic(), err)
+	}
+}
+
+// retryUnavailable calls process until it succeeds, fails with another
+// error than ErrorUnavailable or was called maxStatusAttempts times,
+// doubling the delay between the calls up to maxRetryDelay.
+func retryUnavailable(topic string, process func() error) error {
+	delay := minRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := process()
+		var unavailable *db.ErrorUnavailable
+		if !errors.As(err, &unavailable) || attempt == maxStatusAttempts {
+			return err
+		}
+		log.Printf("Retrying status message of %s in %s: %v", topic, delay, err)
+		sleep(delay)
+		if delay *= 2; delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// Replay processes the payload of a status message received on topic
+// again, e.g. of a dead letter. The payload must be whole: a chunk, stored
+// when its payload couldn't be reassembled, is refused.
+func (c *Connection) Replay(topic string, payload []byte) error {
+	decoded, err := newReassembler().decode(topic, payload)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	if decoded == nil {
+		return errors.New("a chunk of a status message can't be processed alone")
+	}
+	return c.processStatus(topic, decoded)
+}
+
+// statusChunks reassembles the chunked status messages of the agents.
+var statusChunks = newReassembler()
+
+// processStatus stores the status message of a resource or resource bundle
+// received on topic, reassembled and decompressed, once verified, and
+// notifies the observers of the status of resources. Statuses of the
+// resources and bundles of another consumer than the topic's are rejected.
+func (c *Connection) processStatus(topic string, payload []byte) error {
+	t, err := parseStatusTopic(topic)
+	if err != nil {
+		return err
+	}
+	tenantID, consumerID := t.TenantId, t.ConsumerId
+
+	message, err := verifyStatus(tenantID, consumerID, topic, payload)
+	if err != nil {
+		return err
+	}
+
+	if t.Bundle {
+		return db.SetStatusResourceBundle(tenantID, consumerID, t.Id, message)
+	}
+
+	status := db.StatusMessage{}
+	if err := json.Unmarshal(message, &status); err != nil {
+		return err
+	}
+	res, err := db.GetResource(tenantID, t.Id)
+	if err != nil {
+		return err
+	}
+	manifest.ApplyFeedback(res, &status)
+	if err := db.SetStatusResource(consumerID, res, status); err != nil {
+		return err
+	}
+	for _, observe := range c.ResourceStatusObservers {
+		observe(res)
+	}
+	return nil
+}
+
+// statusTopic identifies the resource or bundle of a status topic.
+type statusTopic struct {
+	TenantId   string
+	ConsumerId string
+	Id         string
+	Bundle     bool
+}
+
+// parseStatusTopic parses v1/<tenant>/<consumer>/<resource>/status and
+// v1/<tenant>/<consumer>/bundles/<bundle>/status, and the topics without
+// tenant of the default tenant, v1/<consumer>/<resource>/status and
+// v1/<consumer>/bundles/<bundle>/status. The latter is told apart from the
+// status of resources as no consumer is named "bundles".
+func parseStatusTopic(topic string) (statusTopic, error) {
+	components := strings.Split(topic, "/")
+	if len(components) < 4 || components[0] != "v1" || components[len(components)-1] != "status" {
+		return statusTopic{TenantId: db.DefaultTenant}, fmt.Errorf("unexpected topic %q", topic)
+	}
+
+	switch {
+	case len(components) == 4:
+		return statusTopic{TenantId: db.DefaultTenant, ConsumerId: components[1], Id: components[2]}, nil
+	case len(components) == 5 && components[2] == db.ReservedConsumerId:
+		return statusTopic{TenantId: db.DefaultTenant, ConsumerId: components[1], Id: components[3], Bundle: true}, nil
+	case len(components) == 5:
+		return statusTopic{TenantId: components[1], ConsumerId: components[2], Id: components[3]}, nil
+	case len(components) == 6 && components[3] == "bundles":
+		return statusTopic{TenantId: components[1], ConsumerId: components[2], Id: components[4], Bundle: true}, nil
+	}
+	return statusTopic{TenantId: db.DefaultTenant}, fmt.Errorf("unexpected topic %q", topic)
+}
+
+// verifyStatus returns the status message of payload, verifying its
+// signature when the consumer has a signing key.
+func verifyStatus(tenantID, consumerID, topic string, payload []byte) ([]byte, error) {
+	consumer, err := db.GetConsumer(db.TenantOrDefault(tenantID), consumerID)
+	if err != nil {
+		return nil, err
+	}
+	if consumer.SigningKey == "" {
+		return payload, nil
 	}
+	return signing.Verify(consumer.SigningKey, topic, payload)
 }
 
 func NewClient() (mqtt.Client, error) {
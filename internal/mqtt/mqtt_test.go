@@ -0,0 +1,104 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kube-orchestra/maestro/internal/db"
+)
+
+func TestParseStatusTopic(t *testing.T) {
+	tests := []struct {
+		topic   string
+		want    statusTopic
+		wantErr bool
+	}{
+		{topic: "v1/tenant1/cluster1/resource1/status", want: statusTopic{TenantId: "tenant1", ConsumerId: "cluster1", Id: "resource1"}},
+		{topic: "v1/tenant1/cluster1/bundles/bundle1/status", want: statusTopic{TenantId: "tenant1", ConsumerId: "cluster1", Id: "bundle1", Bundle: true}},
+		{topic: "v1/cluster1/resource1/status", want: statusTopic{TenantId: "default", ConsumerId: "cluster1", Id: "resource1"}},
+		{topic: "v1/cluster1/bundles/bundle1/status", want: statusTopic{TenantId: "default", ConsumerId: "cluster1", Id: "bundle1", Bundle: true}},
+		{topic: "v1/tenant1/cluster1/resource1/content", want: statusTopic{TenantId: "default"}, wantErr: true},
+		{topic: "v2/tenant1/cluster1/resource1/status", want: statusTopic{TenantId: "default"}, wantErr: true},
+		{topic: "v1/cluster1/status", want: statusTopic{TenantId: "default"}, wantErr: true},
+		{topic: "v1/tenant1/cluster1/other/bundle1/status", want: statusTopic{TenantId: "default"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			got, err := parseStatusTopic(tt.topic)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplayChunk(t *testing.T) {
+	data, err := json.Marshal(Envelope{Chunk: &Chunk{Id: "a", Index: 0, Count: 2}, Data: []byte("x")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = (&Connection{}).Replay(testTopic, data)
+	if err == nil || !strings.Contains(err.Error(), "chunk") {
+		t.Fatalf("got error %v, want a chunk refused", err)
+	}
+	if len(statusChunks.pending) != 0 {
+		t.Errorf("replayed chunk pending with the received ones")
+	}
+}
+
+func TestRetryUnavailable(t *testing.T) {
+	unavailable := &db.ErrorUnavailable{Err: errors.New("throttled")}
+	invalid := errors.New("invalid status")
+
+	tests := []struct {
+		name         string
+		errs         []error
+		wantErr      error
+		wantAttempts int
+	}{
+		{name: "success", errs: []error{nil}, wantAttempts: 1},
+		{name: "other error", errs: []error{invalid}, wantErr: invalid, wantAttempts: 1},
+		{name: "available again", errs: []error{unavailable, unavailable, nil}, wantAttempts: 3},
+		{name: "failing after unavailable", errs: []error{unavailable, invalid}, wantErr: invalid, wantAttempts: 2},
+		{name: "unavailable", errs: []error{unavailable, unavailable, unavailable, unavailable, unavailable, unavailable, nil}, wantErr: unavailable, wantAttempts: maxStatusAttempts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var delays []time.Duration
+			sleep = func(d time.Duration) { delays = append(delays, d) }
+			t.Cleanup(func() { sleep = time.Sleep })
+
+			attempts := 0
+			err := retryUnavailable("v1/tenant1/cluster1/resource1/status", func() error {
+				attempts++
+				return tt.errs[attempts-1]
+			})
+			if err != tt.wantErr {
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("got %d attempts, want %d", attempts, tt.wantAttempts)
+			}
+			if len(delays) != attempts-1 {
+				t.Errorf("got %d delays for %d attempts", len(delays), attempts)
+			}
+		})
+	}
+
+	var delays []time.Duration
+	sleep = func(d time.Duration) { delays = append(delays, d) }
+	t.Cleanup(func() { sleep = time.Sleep })
+	_ = retryUnavailable("v1/tenant1/cluster1/resource1/status", func() error { return unavailable })
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second}
+	if !reflect.DeepEqual(delays, want) {
+		t.Errorf("got delays %v, want %v", delays, want)
+	}
+}
@@ -0,0 +1,394 @@
+package placement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/kube-orchestra/maestro/internal/audit"
+	"github.com/kube-orchestra/maestro/internal/authn"
+	"github.com/kube-orchestra/maestro/internal/db"
+	resourcesv1 "github.com/kube-orchestra/maestro/internal/service/v1/resources"
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Reconciler keeps the resources of placements in line with their object
+// and the consumers matching their selector.
+type Reconciler struct {
+	resources *resourcesv1.ResourcesService
+	// serializes reconciliations, so a resource isn't created twice for a
+	// consumer.
+	mu sync.Mutex
+
+	// the placements waiting for a reconciliation to start, and those
+	// whose failed targets are to be tried again, by placement key.
+	queueMu sync.Mutex
+	queued  map[string]bool
+	retries map[string]bool
+}
+
+// retryDelay is the delay before the targets of a rolled back placement
+// that couldn't be restored are tried again.
+const retryDelay = 30 * time.Second
+
+func NewReconciler(resources *resourcesv1.ResourcesService) *Reconciler {
+	return &Reconciler{resources: resources, queued: map[string]bool{}, retries: map[string]bool{}}
+}
+
+// Reconcile creates the resources of the consumers matching a placement
+// and updates the resources created from a previous generation of it, as
+// far as its rollout strategy allows. It deletes the resources of the
+// consumers no longer matching and stores the targets and rollout state of
+// the placement, which it returns.
+// Resources that can't be created or updated, e.g. not admitted, are
+// recorded as failed targets. Placements being deleted are removed instead,
+// see Remove.
+func (r *Reconciler) Reconcile(ctx context.Context, tenantID, placementID string) (*db.Placement, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, err := db.GetPlacement(tenantID, placementID)
+	if err != nil {
+		return nil, err
+	}
+	return p, r.reconcile(ctx, p)
+}
+
+// Resume continues the paused rollout of a placement. The targets failed
+// so far no longer count towards its failure threshold, those that
+// couldn't be created or updated are tried again.
+func (r *Reconciler) Resume(ctx context.Context, tenantID, placementID string) (*db.Placement, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, err := db.GetPlacement(tenantID, placementID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Deleting {
+		return nil, db.PlacementDeletingError(p.Id)
+	}
+	if p.RolloutPhase != db.RolloutPaused {
+		return nil, &db.ErrorFailedPrecondition{
+			Type:        "ROLLOUT",
+			Subject:     p.Id,
+			Description: fmt.Sprintf("the rollout of placement %q is not paused", p.Id),
+		}
+	}
+
+	for consumerID, t := range p.Targets {
+		state, _, err := stateOf(p, t)
+		if err != nil {
+			return nil, err
+		}
+		if state != stateFailed {
+			continue
+		}
+		p.ResumedFailures = append(p.ResumedFailures, consumerID)
+		if t.Error != "" {
+			t.Generation = 0
+			p.Targets[consumerID] = t
+		}
+	}
+	p.RolloutPhase = db.RolloutProgressing
+	p.RolloutMessage = ""
+
+	err = db.UpdatePlacement(p)
+	if err != nil {
+		return nil, err
+	}
+	return p, r.reconcile(ctx, p)
+}
+
+func (r *Reconciler) reconcile(ctx context.Context, p *db.Placement) error {
+	if p.Deleting {
+		return r.remove(p)
+	}
+	generation := p.ResourceGenerationID
+
+	selector, err := labels.Parse(p.ConsumerSelector)
+	if err != nil {
+		return err
+	}
+	consumers, err := db.ListConsumers(p.TenantId)
+	if err != nil {
+		return err
+	}
+
+	var matching []*v1.Consumer
+	for _, c := range consumers {
+		if selector.Matches(labelSet(c)) {
+			matching = append(matching, c)
+		}
+	}
+
+	// the resources are created and updated on behalf of the caller, they
+	// aren't audited as the call
+	targets, err := r.rollout(detach(ctx), p, matching)
+	if err != nil {
+		return err
+	}
+
+	for consumerID, t := range p.Targets {
+		if _, ok := targets[consumerID]; ok || t.ResourceId == "" {
+			continue
+		}
+		if err := r.deleteResource(p.TenantId, t.ResourceId); err != nil {
+			// tried again on the next reconciliation
+			targets[consumerID] = db.PlacementTarget{ResourceId: t.ResourceId, Error: err.Error()}
+		}
+	}
+
+	p.Targets = targets
+	err = db.SetPlacementTargets(p, generation)
+	if p.RolloutPhase == db.RolloutRolledBack && converging(p) {
+		r.retryLater(p.TenantId, p.Id)
+	}
+	return err
+}
+
+// reconcileTarget creates or updates the resource of a target, whose
+// resource is res, nil when it has none, and returns the target and its
+// resource.
+func (r *Reconciler) reconcileTarget(ctx context.Context, p *db.Placement, c *v1.Consumer, t db.PlacementTarget, res *db.Resource) (db.PlacementTarget, *db.Resource) {
+	switch {
+	case res == nil:
+		// none yet, or removed, e.g. after the consumer stopped matching
+	case res.Deleting:
+		// recreated once the agent removed the object
+		return db.PlacementTarget{ResourceId: res.Id, Error: "resource is being deleted"}, res
+	case res.PlacementGeneration == p.ResourceGenerationID && t.Error == "":
+		return t, res
+	default:
+		if err := r.resources.UpdatePlaced(ctx, res, p); err != nil {
+			return db.PlacementTarget{ResourceId: res.Id, Error: err.Error()}, res
+		}
+		return db.PlacementTarget{ResourceId: res.Id}, res
+	}
+
+	created, err := r.resources.CreatePlaced(ctx, c, p)
+	if err != nil {
+		// adopt the resource created for the placement by a reconciliation
+		// whose targets weren't stored
+		var exists *db.ErrorAlreadyExists
+		if errors.As(err, &exists) {
+			if owner, getErr := db.GetResource(p.TenantId, exists.Id); getErr == nil && owner.PlacementId == p.Id {
+				return r.reconcileTarget(ctx, p, c, db.PlacementTarget{ResourceId: owner.Id, Error: err.Error()}, owner)
+			}
+		}
+		return db.PlacementTarget{Error: err.Error()}, nil
+	}
+	return db.PlacementTarget{ResourceId: created.Id}, created
+}
+
+// ConsumerChanged reconciles, in the background, the placements whose
+// selector started or stopped matching a consumer after it was created,
+// previous being nil, or its labels changed. When its labels changed, the
+// templated resources of the consumer, direct or placed, are rendered
+// again with them. Failures are logged, the placements are reconciled
+// again on the next change.
+func (r *Reconciler) ConsumerChanged(ctx context.Context, previous, consumer *v1.Consumer) {
+	// the reconciliations aren't audited as the call
+	ctx = detach(ctx)
+	tenantID := db.TenantOrDefault(consumer.TenantId)
+
+	go func() {
+		placements, err := db.ListPlacements(tenantID)
+		if err != nil {
+			log.Printf("Failed to list the placements of tenant %q: %v", tenantID, err)
+			return
+		}
+
+		for _, p := range placements {
+			selector, err := labels.Parse(p.ConsumerSelector)
+			if err != nil {
+				continue
+			}
+			matched := previous != nil && selector.Matches(labelSet(previous))
+			if matched == selector.Matches(labelSet(consumer)) {
+				continue
+			}
+			if _, err := r.Reconcile(ctx, tenantID, p.Id); err != nil {
+				log.Printf("Failed to reconcile placement %q of tenant %q: %v", p.Id, tenantID, err)
+			}
+		}
+
+		if previous == nil || labelSet(previous).String() == labelSet(consumer).String() {
+			return
+		}
+		// the resources of placements aren't updated by a reconciliation
+		// meanwhile
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if err := r.resources.Rerender(ctx, consumer); err != nil {
+			log.Printf("Failed to render the templates of consumer %q of tenant %q: %v", consumer.Id, tenantID, err)
+		}
+	}()
+}
+
+// ResourceStatusChanged advances the rollout of the placement of a
+// resource, or removes the placement being deleted once the resource was
+// its last one, after its agent reported the status of the resource.
+func (r *Reconciler) ResourceStatusChanged(res *db.Resource) {
+	if res.PlacementId == "" {
+		return
+	}
+	r.enqueue(res.TenantId, res.PlacementId)
+}
+
+// enqueue reconciles a placement in the background while its rollout is
+// converging or it's being deleted, unless a reconciliation of it is already waiting to start:
+// that one sees the statuses reported meanwhile, so the statuses of many
+// targets take a single reconciliation.
+func (r *Reconciler) enqueue(tenantID, placementID string) {
+	key := tenantID + "/" + placementID
+	r.queueMu.Lock()
+	if r.queued[key] {
+		r.queueMu.Unlock()
+		return
+	}
+	r.queued[key] = true
+	r.queueMu.Unlock()
+
+	// status reports aren't held up by the reconciliation
+	go func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.queueMu.Lock()
+		delete(r.queued, key)
+		r.queueMu.Unlock()
+
+		p, err := db.GetPlacement(tenantID, placementID)
+		if err != nil || !p.Deleting && !converging(p) {
+			return
+		}
+		if err := r.reconcile(context.Background(), p); err != nil {
+			log.Printf("Failed to reconcile placement %q of tenant %q: %v", placementID, tenantID, err)
+		}
+	}()
+}
+
+// retryLater reconciles a placement again after retryDelay, unless it's
+// already scheduled to.
+func (r *Reconciler) retryLater(tenantID, placementID string) {
+	key := tenantID + "/" + placementID
+	r.queueMu.Lock()
+	defer r.queueMu.Unlock()
+	if r.retries[key] {
+		return
+	}
+	r.retries[key] = true
+
+	time.AfterFunc(retryDelay, func() {
+		r.queueMu.Lock()
+		delete(r.retries, key)
+		r.queueMu.Unlock()
+		r.enqueue(tenantID, placementID)
+	})
+}
+
+// converging reports whether the rollout of a placement has targets left
+// to update: it's progressing, or rolled back while some targets weren't
+// restored yet, e.g. as they failed or were being deleted.
+func converging(p *db.Placement) bool {
+	switch p.RolloutPhase {
+	case db.RolloutProgressing:
+		return !p.Deleting
+	case db.RolloutRolledBack:
+		if p.Deleting {
+			return false
+		}
+		for _, t := range p.Targets {
+			if t.Generation != p.ResourceGenerationID || t.Error != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Remove deletes a placement: it's marked as being deleted and the
+// resources of its targets are deleted, then it's removed once their
+// agents reported them removed, see ResourceStatusChanged. When some can't
+// be deleted, its removal is tried again by the next reconciliation, or by
+// deleting it again. It returns the placement, also along with the error
+// of its resources.
+func (r *Reconciler) Remove(tenantID, placementID string) (*db.Placement, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, err := db.SetPlacementDeleting(tenantID, placementID)
+	if err != nil {
+		return nil, err
+	}
+	return p, r.remove(p)
+}
+
+// remove deletes the resources of the targets of a placement being
+// deleted, and removes the placement once none is left.
+func (r *Reconciler) remove(p *db.Placement) error {
+	var errs []error
+	remaining := false
+	for _, t := range p.Targets {
+		if t.ResourceId == "" {
+			continue
+		}
+		res, err := db.GetResource(p.TenantId, t.ResourceId)
+		var notFound *db.ErrorNotFound
+		switch {
+		case errors.As(err, &notFound):
+			continue
+		case err != nil:
+			errs = append(errs, err)
+		case !res.Deleting:
+			// resources already being deleted aren't published again on
+			// every status report
+			if err := r.resources.DeletePlaced(res); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		remaining = true
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if remaining {
+		return nil
+	}
+	return db.DeletePlacement(p.TenantId, p.Id)
+}
+
+func (r *Reconciler) deleteResource(tenantID, resourceID string) error {
+	res, err := db.GetResource(tenantID, resourceID)
+	var notFound *db.ErrorNotFound
+	if errors.As(err, &notFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return r.resources.DeletePlaced(res)
+}
+
+// detach returns a context carrying the caller of ctx but not its audit
+// record.
+func detach(ctx context.Context) context.Context {
+	detached := audit.WithPrincipal(context.Background(), audit.Principal(ctx))
+	if id, ok := authn.IdentityFrom(ctx); ok {
+		detached = authn.WithIdentity(detached, id)
+	}
+	return detached
+}
+
+func labelSet(c *v1.Consumer) labels.Set {
+	set := labels.Set{}
+	for _, l := range c.Labels {
+		set[l.Key] = l.Value
+	}
+	return set
+}
@@ -0,0 +1,293 @@
+package placement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kube-orchestra/maestro/internal/db"
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// targetState is the progress of a target towards the placement
+// generation.
+type targetState int
+
+const (
+	// the generation wasn't rolled out to the target yet.
+	stateOutdated targetState = iota
+	// the agent didn't report the generation yet.
+	stateInFlight
+	stateApplied
+	stateFailed
+	// the resource of the target is being deleted, it's created again once
+	// the agent removed its object.
+	stateDeleting
+)
+
+// stateOf returns the state of a target and, for failed targets, why
+// it failed.
+func stateOf(p *db.Placement, t db.PlacementTarget) (targetState, string, error) {
+	res, err := resourceOf(p, t)
+	if err != nil {
+		return 0, "", err
+	}
+	state, message := targetStateOf(p, t, res)
+	return state, message, nil
+}
+
+// resourceOf returns the resource of a target, nil when it has none or it
+// was removed.
+func resourceOf(p *db.Placement, t db.PlacementTarget) (*db.Resource, error) {
+	if t.ResourceId == "" {
+		return nil, nil
+	}
+	res, err := db.GetResource(p.TenantId, t.ResourceId)
+	var notFound *db.ErrorNotFound
+	if errors.As(err, &notFound) {
+		return nil, nil
+	}
+	return res, err
+}
+
+// targetStateOf returns the state of a target whose resource is res, and
+// for failed targets why it failed.
+func targetStateOf(p *db.Placement, t db.PlacementTarget, res *db.Resource) (targetState, string) {
+	switch {
+	case res != nil && res.Deleting:
+		return stateDeleting, ""
+	case t.Generation != p.ResourceGenerationID:
+		return stateOutdated, ""
+	case t.Error != "":
+		return stateFailed, t.Error
+	case res == nil || res.PlacementGeneration != p.ResourceGenerationID:
+		return stateOutdated, ""
+	}
+
+	reconciled := meta.FindStatusCondition(res.Status.ReconcileStatus.Conditions, db.StatusMessageReconciled)
+	switch {
+	case res.Status.ResourceGenerationID < res.ResourceGenerationID || reconciled == nil:
+		return stateInFlight, ""
+	case reconciled.Status == "True":
+		return stateApplied, ""
+	default:
+		return stateFailed, reconciled.Message
+	}
+}
+
+// TargetStatus returns the state of the resource of a placement on a
+// consumer.
+func TargetStatus(p *db.Placement, consumerID string, t db.PlacementTarget) (*v1.PlacementTarget, error) {
+	state, message, err := stateOf(p, t)
+	if err != nil {
+		return nil, err
+	}
+
+	target := &v1.PlacementTarget{ConsumerId: consumerID, ResourceId: t.ResourceId, Message: message}
+	switch state {
+	case stateApplied:
+		target.State = v1.PlacementTargetState_APPLIED
+	case stateFailed:
+		target.State = v1.PlacementTargetState_FAILED
+	default:
+		target.State = v1.PlacementTargetState_PENDING
+	}
+	return target, nil
+}
+
+// rollout creates and updates the resources of the consumers allowed by the
+// rollout strategy of p, and returns the targets of the consumers. It
+// pauses or rolls back the rollout once more targets failed than the
+// strategy allows. The resources of the targets are read once, the targets
+// settled right away by a batch count towards the next one.
+func (r *Reconciler) rollout(ctx context.Context, p *db.Placement, consumers []*v1.Consumer) (map[string]db.PlacementTarget, error) {
+	targets := map[string]db.PlacementTarget{}
+	resources := map[string]*db.Resource{}
+	for _, c := range consumers {
+		t := p.Targets[c.Id]
+		res, err := resourceOf(p, t)
+		if err != nil {
+			return nil, err
+		}
+		targets[c.Id] = t
+		resources[c.Id] = res
+	}
+
+	for {
+		batch, progress, err := plan(p, consumers, targets, resources)
+		if err != nil {
+			return nil, err
+		}
+
+		settled := false
+		inFlight := progress.inFlight
+		for _, c := range batch {
+			t, res := r.reconcileTarget(ctx, p, c, targets[c.Id], resources[c.Id])
+			t.Generation = p.ResourceGenerationID
+			targets[c.Id] = t
+			resources[c.Id] = res
+
+			if state, _ := targetStateOf(p, t, res); state == stateInFlight {
+				inFlight++
+			} else {
+				settled = true
+			}
+		}
+
+		if p.RolloutPhase == db.RolloutPaused || p.RolloutPhase == db.RolloutRolledBack {
+			return targets, nil
+		}
+		if settled {
+			// targets failing right away, or applied already as their
+			// object didn't change, count towards the next batch
+			continue
+		}
+		p.RolloutPhase = progressingPhase(progress, len(batch), inFlight)
+		return targets, nil
+	}
+}
+
+// progressingPhase returns the phase of a progressing rollout once batched
+// of its outdated targets were updated, leaving inFlight targets the agents
+// didn't report the generation for yet.
+func progressingPhase(progress rolloutProgress, batched, inFlight int) string {
+	if batched == len(progress.outdated) && inFlight == 0 && progress.deleting == 0 {
+		return db.RolloutComplete
+	}
+	return db.RolloutProgressing
+}
+
+// rolloutProgress counts the targets of a placement by state.
+type rolloutProgress struct {
+	outdated []*v1.Consumer
+	// targets to restore again once rolled back: those that couldn't be
+	// created or updated, or were being deleted.
+	unrestored []*v1.Consumer
+	// the failed targets counting towards the failure threshold.
+	failed                       []string
+	inFlight, deleting, canaries int
+}
+
+// progressOf returns the progress of the targets of consumers, whose
+// resources are resources.
+func progressOf(p *db.Placement, consumers []*v1.Consumer, targets map[string]db.PlacementTarget, resources map[string]*db.Resource, canary labels.Selector) rolloutProgress {
+	resumed := map[string]bool{}
+	for _, consumerID := range p.ResumedFailures {
+		resumed[consumerID] = true
+	}
+
+	var progress rolloutProgress
+	for _, c := range consumers {
+		t := targets[c.Id]
+		state, _ := targetStateOf(p, t, resources[c.Id])
+		switch state {
+		case stateOutdated:
+			progress.outdated = append(progress.outdated, c)
+		case stateInFlight:
+			progress.inFlight++
+		case stateDeleting:
+			progress.deleting++
+			progress.unrestored = append(progress.unrestored, c)
+		case stateFailed:
+			if !resumed[c.Id] {
+				progress.failed = append(progress.failed, c.Id)
+			}
+			if t.Error != "" {
+				progress.unrestored = append(progress.unrestored, c)
+			}
+		}
+		if (state == stateOutdated || state == stateInFlight) && canary.Matches(labelSet(c)) {
+			progress.canaries++
+		}
+	}
+	return progress
+}
+
+// plan returns the targets of p to create or update next, and the progress
+// of its targets. It pauses the rollout, or rolls it back to the previous
+// object, once more targets failed than the strategy allows.
+func plan(p *db.Placement, consumers []*v1.Consumer, targets map[string]db.PlacementTarget, resources map[string]*db.Resource) ([]*v1.Consumer, rolloutProgress, error) {
+	canary := labels.Nothing()
+	if p.Rollout.Type == db.RolloutCanary {
+		var err error
+		canary, err = labels.Parse(p.Rollout.CanarySelector)
+		if err != nil {
+			return nil, rolloutProgress{}, err
+		}
+	}
+	progress := progressOf(p, consumers, targets, resources, canary)
+
+	switch p.RolloutPhase {
+	case db.RolloutPaused:
+		return nil, progress, nil
+	case db.RolloutRolledBack:
+		// the previous object is restored everywhere at once, the targets
+		// that couldn't be restored are tried again
+		return append(progress.outdated, progress.unrestored...), progress, nil
+	}
+
+	if len(progress.failed) <= int(p.Rollout.MaxFailures) {
+		return nextBatch(p.Rollout, progress.outdated, progress.inFlight, progress.canaries, canary), progress, nil
+	}
+
+	message := fmt.Sprintf("%d targets failed: %s", len(progress.failed), strings.Join(progress.failed, ", "))
+	if p.Rollout.OnFailure == db.RolloutOnFailureRollback && p.PreviousObject != nil {
+		p.Object = *p.PreviousObject.DeepCopy()
+		p.ResourceGenerationID++
+		p.ResumedFailures = nil
+		p.RolloutPhase = db.RolloutRolledBack
+		p.RolloutMessage = message + ", the previous object was restored"
+
+		// every target is outdated by the restored generation
+		progress = progressOf(p, consumers, targets, resources, canary)
+		return append(progress.outdated, progress.unrestored...), progress, nil
+	}
+
+	p.RolloutPhase = db.RolloutPaused
+	p.RolloutMessage = message
+	if p.Rollout.OnFailure == db.RolloutOnFailureRollback {
+		p.RolloutMessage += ", the first generation can't be rolled back"
+	}
+	return nil, progress, nil
+}
+
+// nextBatch returns the outdated targets to update, given the number of
+// targets the agents didn't report the generation for yet and the number of
+// canaries not applied yet.
+func nextBatch(strategy db.RolloutStrategy, outdated []*v1.Consumer, inFlight, canaries int, canary labels.Selector) []*v1.Consumer {
+	maxConcurrency := int(strategy.MaxConcurrency)
+
+	switch strategy.Type {
+	case db.RolloutRolling:
+		if maxConcurrency < 1 {
+			maxConcurrency = 1
+		}
+	case db.RolloutCanary:
+		if canaries > 0 {
+			var pending []*v1.Consumer
+			for _, c := range outdated {
+				if canary.Matches(labelSet(c)) {
+					pending = append(pending, c)
+				}
+			}
+			outdated = pending
+		}
+	default:
+		return outdated
+	}
+
+	if maxConcurrency < 1 {
+		return outdated
+	}
+	available := maxConcurrency - inFlight
+	if available < 0 {
+		available = 0
+	}
+	if available < len(outdated) {
+		return outdated[:available]
+	}
+	return outdated
+}
@@ -0,0 +1,354 @@
+package placement
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kube-orchestra/maestro/internal/db"
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func consumer(id string, kv ...string) *v1.Consumer {
+	c := &v1.Consumer{Id: id}
+	for i := 0; i+1 < len(kv); i += 2 {
+		c.Labels = append(c.Labels, &v1.ConsumerLabel{Key: kv[i], Value: kv[i+1]})
+	}
+	return c
+}
+
+func ids(consumers []*v1.Consumer) []string {
+	ids := []string{}
+	for _, c := range consumers {
+		ids = append(ids, c.Id)
+	}
+	return ids
+}
+
+// placed returns a target of consumerID rolled out to generation, and its
+// resource, which reported reconciled ("True" or "False"), or nothing yet
+// when empty.
+func placed(consumerID string, generation int64, reconciled string) (db.PlacementTarget, *db.Resource) {
+	res := &db.Resource{
+		Id:                   consumerID + "-resource",
+		ConsumerId:           consumerID,
+		ResourceGenerationID: 1,
+		PlacementGeneration:  generation,
+	}
+	if reconciled != "" {
+		res.Status.ResourceGenerationID = 1
+		res.Status.ReconcileStatus.Conditions = []metav1.Condition{{
+			Type:    db.StatusMessageReconciled,
+			Status:  metav1.ConditionStatus(reconciled),
+			Message: "reconcile " + reconciled,
+		}}
+	}
+	return db.PlacementTarget{ResourceId: res.Id, Generation: generation}, res
+}
+
+func TestTargetStateOf(t *testing.T) {
+	p := &db.Placement{ResourceGenerationID: 2}
+	applied, appliedRes := placed("c1", 2, "True")
+	failed, failedRes := placed("c1", 2, "False")
+	inFlight, inFlightRes := placed("c1", 2, "")
+	older, olderRes := placed("c1", 1, "True")
+	deleting := *appliedRes
+	deleting.Deleting = true
+
+	tests := []struct {
+		name        string
+		target      db.PlacementTarget
+		res         *db.Resource
+		wantState   targetState
+		wantMessage string
+	}{
+		{name: "no resource", target: db.PlacementTarget{}, wantState: stateOutdated},
+		{name: "older generation", target: older, res: olderRes, wantState: stateOutdated},
+		{name: "resource of an older generation", target: db.PlacementTarget{ResourceId: "r", Generation: 2}, res: olderRes, wantState: stateOutdated},
+		{name: "in flight", target: inFlight, res: inFlightRes, wantState: stateInFlight},
+		{name: "applied", target: applied, res: appliedRes, wantState: stateApplied},
+		{name: "not reconciled", target: failed, res: failedRes, wantState: stateFailed, wantMessage: "reconcile False"},
+		{
+			name:        "not updated",
+			target:      db.PlacementTarget{ResourceId: "r", Generation: 2, Error: "not admitted"},
+			res:         olderRes,
+			wantState:   stateFailed,
+			wantMessage: "not admitted",
+		},
+		{name: "deleting", target: applied, res: &deleting, wantState: stateDeleting},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, message := targetStateOf(p, tt.target, tt.res)
+			if state != tt.wantState || message != tt.wantMessage {
+				t.Errorf("got %v %q, want %v %q", state, message, tt.wantState, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestNextBatch(t *testing.T) {
+	outdated := []*v1.Consumer{
+		consumer("c1", "ring", "canary"),
+		consumer("c2"),
+		consumer("c3", "ring", "canary"),
+		consumer("c4"),
+	}
+	canary := labels.SelectorFromSet(labels.Set{"ring": "canary"})
+
+	tests := []struct {
+		name     string
+		strategy db.RolloutStrategy
+		inFlight int
+		canaries int
+		want     []string
+	}{
+		{
+			name:     "all at once",
+			strategy: db.RolloutStrategy{MaxConcurrency: 1},
+			inFlight: 3,
+			want:     []string{"c1", "c2", "c3", "c4"},
+		},
+		{
+			name:     "rolling",
+			strategy: db.RolloutStrategy{Type: db.RolloutRolling, MaxConcurrency: 3},
+			inFlight: 1,
+			want:     []string{"c1", "c2"},
+		},
+		{
+			name:     "rolling defaults to one at a time",
+			strategy: db.RolloutStrategy{Type: db.RolloutRolling},
+			want:     []string{"c1"},
+		},
+		{
+			name:     "rolling with every slot in flight",
+			strategy: db.RolloutStrategy{Type: db.RolloutRolling, MaxConcurrency: 2},
+			inFlight: 3,
+			want:     []string{},
+		},
+		{
+			name:     "canaries first",
+			strategy: db.RolloutStrategy{Type: db.RolloutCanary},
+			canaries: 2,
+			want:     []string{"c1", "c3"},
+		},
+		{
+			name:     "canaries first, with concurrency",
+			strategy: db.RolloutStrategy{Type: db.RolloutCanary, MaxConcurrency: 1},
+			canaries: 2,
+			want:     []string{"c1"},
+		},
+		{
+			name:     "others once the canaries applied",
+			strategy: db.RolloutStrategy{Type: db.RolloutCanary, MaxConcurrency: 2},
+			want:     []string{"c1", "c2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ids(nextBatch(tt.strategy, outdated, tt.inFlight, tt.canaries, canary))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	previous := &unstructured.Unstructured{Object: map[string]interface{}{"kind": "ConfigMap", "data": map[string]interface{}{"v": "1"}}}
+	object := unstructured.Unstructured{Object: map[string]interface{}{"kind": "ConfigMap", "data": map[string]interface{}{"v": "2"}}}
+	consumers := []*v1.Consumer{consumer("c1", "ring", "canary"), consumer("c2"), consumer("c3")}
+
+	// states of c1, c2 and c3: "applied", "failed", "in flight",
+	// "outdated", "error" when it couldn't be updated, or "deleting".
+	type states [3]string
+
+	tests := []struct {
+		name      string
+		placement db.Placement
+		states    states
+		wantBatch []string
+		wantPhase string
+		// the placement generation after planning.
+		wantGeneration int64
+		wantObject     *unstructured.Unstructured
+		wantMessage    string
+	}{
+		{
+			name:           "all at once",
+			placement:      db.Placement{},
+			states:         states{"outdated", "outdated", "outdated"},
+			wantBatch:      []string{"c1", "c2", "c3"},
+			wantGeneration: 2,
+		},
+		{
+			name:           "rolling waits for the targets in flight",
+			placement:      db.Placement{Rollout: db.RolloutStrategy{Type: db.RolloutRolling, MaxConcurrency: 2}},
+			states:         states{"applied", "in flight", "outdated"},
+			wantBatch:      []string{"c3"},
+			wantGeneration: 2,
+		},
+		{
+			name:           "canary first",
+			placement:      db.Placement{Rollout: db.RolloutStrategy{Type: db.RolloutCanary, CanarySelector: "ring=canary"}},
+			states:         states{"outdated", "outdated", "outdated"},
+			wantBatch:      []string{"c1"},
+			wantGeneration: 2,
+		},
+		{
+			name:           "canary in flight holds back the others",
+			placement:      db.Placement{Rollout: db.RolloutStrategy{Type: db.RolloutCanary, CanarySelector: "ring=canary"}},
+			states:         states{"in flight", "outdated", "outdated"},
+			wantBatch:      []string{},
+			wantGeneration: 2,
+		},
+		{
+			name:           "failures within the threshold",
+			placement:      db.Placement{Rollout: db.RolloutStrategy{MaxFailures: 1}},
+			states:         states{"failed", "outdated", "applied"},
+			wantBatch:      []string{"c2"},
+			wantGeneration: 2,
+		},
+		{
+			name:           "pause",
+			placement:      db.Placement{},
+			states:         states{"failed", "error", "outdated"},
+			wantBatch:      []string{},
+			wantPhase:      db.RolloutPaused,
+			wantGeneration: 2,
+			wantMessage:    "2 targets failed: c1, c2",
+		},
+		{
+			name:           "paused",
+			placement:      db.Placement{RolloutPhase: db.RolloutPaused},
+			states:         states{"applied", "outdated", "outdated"},
+			wantBatch:      []string{},
+			wantPhase:      db.RolloutPaused,
+			wantGeneration: 2,
+		},
+		{
+			name:           "resumed failures don't count",
+			placement:      db.Placement{ResumedFailures: []string{"c1"}},
+			states:         states{"failed", "outdated", "applied"},
+			wantBatch:      []string{"c2"},
+			wantGeneration: 2,
+		},
+		{
+			name:           "rollback",
+			placement:      db.Placement{Rollout: db.RolloutStrategy{Type: db.RolloutRolling, MaxConcurrency: 1, OnFailure: db.RolloutOnFailureRollback}, PreviousObject: previous, ResumedFailures: []string{"c3"}},
+			states:         states{"failed", "error", "outdated"},
+			wantBatch:      []string{"c1", "c2", "c3"},
+			wantPhase:      db.RolloutRolledBack,
+			wantGeneration: 3,
+			wantObject:     previous,
+			wantMessage:    "2 targets failed: c1, c2, the previous object was restored",
+		},
+		{
+			name:           "rollback of the first generation",
+			placement:      db.Placement{Rollout: db.RolloutStrategy{OnFailure: db.RolloutOnFailureRollback}},
+			states:         states{"failed", "outdated", "outdated"},
+			wantBatch:      []string{},
+			wantPhase:      db.RolloutPaused,
+			wantGeneration: 2,
+			wantMessage:    "1 targets failed: c1, the first generation can't be rolled back",
+		},
+		{
+			name:           "rolled back retries the targets not restored",
+			placement:      db.Placement{RolloutPhase: db.RolloutRolledBack, Rollout: db.RolloutStrategy{Type: db.RolloutRolling, MaxConcurrency: 1}},
+			states:         states{"error", "deleting", "outdated"},
+			wantBatch:      []string{"c3", "c1", "c2"},
+			wantPhase:      db.RolloutRolledBack,
+			wantGeneration: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.placement
+			p.Object = *object.DeepCopy()
+			p.ResourceGenerationID = 2
+
+			targets := map[string]db.PlacementTarget{}
+			resources := map[string]*db.Resource{}
+			for i, state := range tt.states {
+				id := consumers[i].Id
+				var target db.PlacementTarget
+				var res *db.Resource
+				switch state {
+				case "applied":
+					target, res = placed(id, 2, "True")
+				case "failed":
+					target, res = placed(id, 2, "False")
+				case "in flight":
+					target, res = placed(id, 2, "")
+				case "outdated":
+					target, res = placed(id, 1, "True")
+				case "error":
+					target, res = placed(id, 1, "True")
+					target.Generation, target.Error = 2, "not admitted"
+				case "deleting":
+					target, res = placed(id, 2, "True")
+					res.Deleting = true
+				}
+				targets[id], resources[id] = target, res
+			}
+
+			batch, _, err := plan(&p, consumers, targets, resources)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ids(batch); !reflect.DeepEqual(got, tt.wantBatch) {
+				t.Errorf("got batch %v, want %v", got, tt.wantBatch)
+			}
+			if p.RolloutPhase != tt.wantPhase {
+				t.Errorf("got phase %q, want %q", p.RolloutPhase, tt.wantPhase)
+			}
+			if p.ResourceGenerationID != tt.wantGeneration {
+				t.Errorf("got generation %d, want %d", p.ResourceGenerationID, tt.wantGeneration)
+			}
+			wantObject := tt.wantObject
+			if wantObject == nil {
+				wantObject = &object
+			}
+			if !reflect.DeepEqual(p.Object.Object, wantObject.Object) {
+				t.Errorf("got object %v, want %v", p.Object.Object, wantObject.Object)
+			}
+			if p.RolloutMessage != tt.wantMessage {
+				t.Errorf("got message %q, want %q", p.RolloutMessage, tt.wantMessage)
+			}
+			if tt.wantPhase == db.RolloutRolledBack && len(p.ResumedFailures) > 0 {
+				t.Errorf("got resumed failures %v after the rollback", p.ResumedFailures)
+			}
+		})
+	}
+}
+
+func TestProgressingPhase(t *testing.T) {
+	outdated := []*v1.Consumer{consumer("c1"), consumer("c2")}
+
+	tests := []struct {
+		name     string
+		progress rolloutProgress
+		batched  int
+		inFlight int
+		want     string
+	}{
+		{name: "nothing left", want: db.RolloutComplete},
+		{name: "every outdated target batched", progress: rolloutProgress{outdated: outdated}, batched: 2, want: db.RolloutComplete},
+		{name: "outdated targets left", progress: rolloutProgress{outdated: outdated}, batched: 1, want: db.RolloutProgressing},
+		{name: "targets in flight", progress: rolloutProgress{outdated: outdated}, batched: 2, inFlight: 1, want: db.RolloutProgressing},
+		{name: "targets being deleted", progress: rolloutProgress{deleting: 1}, want: db.RolloutProgressing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := progressingPhase(tt.progress, tt.batched, tt.inFlight); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
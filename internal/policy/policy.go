@@ -0,0 +1,194 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/kube-orchestra/maestro/internal/admission"
+	"github.com/kube-orchestra/maestro/internal/db"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const admissionPoliciesFile = "ADMISSION_POLICIES_FILE"
+
+// costLimit bounds the evaluation of an expression, e.g. of comprehensions
+// over large lists.
+const costLimit = 1000000
+
+type Config struct {
+	Policies []Policy `json:"policies"`
+}
+
+// Policy is a CEL expression evaluating to true for the objects it allows.
+type Policy struct {
+	Id         string `json:"id"`
+	Expression string `json:"expression"`
+	// returned when the expression evaluates to false.
+	Message string `json:"message,omitempty"`
+}
+
+type compiled struct {
+	expression string
+	program    cel.Program
+}
+
+// Engine evaluates the policies of the file ADMISSION_POLICIES_FILE, which
+// apply to every tenant, and the policies stored in the tenant of the
+// evaluated object.
+type Engine struct {
+	env    *cel.Env
+	static []Policy
+
+	mu sync.Mutex
+	// programs of the policies by tenant and id, compiled again when their
+	// expression changes.
+	programs map[string]compiled
+}
+
+// New creates the engine and compiles the policies of the file
+// ADMISSION_POLICIES_FILE, when set.
+func New() (*Engine, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("object", cel.DynType),
+		cel.Variable("oldObject", cel.DynType),
+		cel.Variable("operation", cel.StringType),
+		cel.Variable("consumer", cel.MapType(cel.StringType, cel.DynType)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	e := &Engine{env: env, programs: map[string]compiled{}}
+
+	path := os.Getenv(admissionPoliciesFile)
+	if path == "" {
+		return e, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("failed to read admission policies %s: %w", path, err)
+	}
+
+	for i, p := range config.Policies {
+		if p.Id == "" {
+			return nil, fmt.Errorf("admission policies %s: policies[%d]: id is required", path, i)
+		}
+		if _, err := e.program("", p.Id, p.Expression); err != nil {
+			return nil, fmt.Errorf("admission policies %s: policies[%d]: %w", path, i, err)
+		}
+	}
+	e.static = config.Policies
+	return e, nil
+}
+
+// Check compiles expression, failing when it isn't valid CEL or doesn't
+// evaluate to a bool.
+func (e *Engine) Check(expression string) error {
+	_, err := e.compile(expression)
+	return err
+}
+
+func (e *Engine) compile(expression string) (cel.Program, error) {
+	ast, issues := e.env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("expression must evaluate to a bool, not %s", ast.OutputType())
+	}
+	return e.env.Program(ast, cel.CostLimit(costLimit), cel.InterruptCheckFrequency(100))
+}
+
+// program returns the compiled expression of a policy, tenant being empty
+// for the policies of the file.
+func (e *Engine) program(tenantID, id, expression string) (cel.Program, error) {
+	key := tenantID + "/" + id
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if c, ok := e.programs[key]; ok && c.expression == expression {
+		return c.program, nil
+	}
+
+	program, err := e.compile(expression)
+	if err != nil {
+		return nil, err
+	}
+	e.programs[key] = compiled{expression: expression, program: program}
+	return program, nil
+}
+
+// Evaluate evaluates the policies applying to the object of req. It returns
+// ErrorInvalidArgument listing the message of every policy not allowing
+// the object. Expressions failing to evaluate, e.g. on a missing field,
+// don't allow the object either.
+func (e *Engine) Evaluate(ctx context.Context, req *admission.Request) error {
+	stored, err := db.ListPolicies(req.TenantId)
+	if err != nil {
+		return err
+	}
+
+	var oldObject interface{}
+	if req.OldObject != nil {
+		oldObject = req.OldObject.Object
+	}
+	vars := map[string]interface{}{
+		"object":    req.Object.Object,
+		"oldObject": oldObject,
+		"operation": req.Operation,
+		"consumer": map[string]interface{}{
+			"id":       req.ConsumerId,
+			"tenantId": req.TenantId,
+			"labels":   req.ConsumerLabels,
+		},
+	}
+
+	var violations []db.FieldViolation
+	evaluate := func(tenantID string, p Policy) {
+		program, err := e.program(tenantID, p.Id, p.Expression)
+		if err != nil {
+			violations = append(violations, violation(p, fmt.Sprintf("invalid expression: %v", err)))
+			return
+		}
+
+		out, _, err := program.ContextEval(ctx, vars)
+		if err != nil {
+			violations = append(violations, violation(p, fmt.Sprintf("failed to evaluate: %v", err)))
+			return
+		}
+		if out != types.True {
+			message := p.Message
+			if message == "" {
+				message = fmt.Sprintf("failed expression %s", p.Expression)
+			}
+			violations = append(violations, violation(p, message))
+		}
+	}
+
+	for _, p := range e.static {
+		evaluate("", p)
+	}
+	for _, p := range stored {
+		evaluate(req.TenantId, Policy{Id: p.Id, Expression: p.Expression, Message: p.Message})
+	}
+
+	if len(violations) > 0 {
+		return &db.ErrorInvalidArgument{Violations: violations}
+	}
+	return nil
+}
+
+func violation(p Policy, description string) db.FieldViolation {
+	return db.FieldViolation{Field: "object", Description: fmt.Sprintf("policy %q: %s", p.Id, description)}
+}
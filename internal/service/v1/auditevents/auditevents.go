@@ -0,0 +1,69 @@
+package auditevents
+
+import (
+	"context"
+	"time"
+
+	"github.com/kube-orchestra/maestro/internal/db"
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
+)
+
+const defaultLimit = 100
+
+type Service struct {
+	v1.UnimplementedAuditServiceServer
+}
+
+func NewAuditService() *Service {
+	return &Service{}
+}
+
+// Query returns the audit events of a target or a principal, newest first.
+func (svc *Service) Query(_ context.Context, r *v1.AuditQueryRequest) (*v1.AuditEventList, error) {
+	if r.TargetId == "" && r.Principal == "" {
+		return nil, &db.ErrorInvalidArgument{Violations: []db.FieldViolation{
+			{Field: "targetId", Description: "either targetId or principal is required"},
+			{Field: "principal", Description: "either targetId or principal is required"},
+		}}
+	}
+	if r.Limit < 0 {
+		return nil, &db.ErrorInvalidArgument{Violations: []db.FieldViolation{
+			{Field: "limit", Description: "must not be negative"},
+		}}
+	}
+
+	limit := int(r.Limit)
+	if limit == 0 {
+		limit = defaultLimit
+	}
+
+	events, err := db.QueryAuditEvents(db.AuditFilter{
+		TenantId:   r.TenantId,
+		TargetKind: r.TargetKind,
+		TargetId:   r.TargetId,
+		Principal:  r.Principal,
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	list := &v1.AuditEventList{}
+	for _, e := range events {
+		list.Events = append(list.Events, &v1.AuditEvent{
+			Id:            e.Id,
+			Timestamp:     time.Unix(0, e.Timestamp).UTC().Format(time.RFC3339Nano),
+			Principal:     e.Principal,
+			Method:        e.Method,
+			TargetKind:    e.TargetKind,
+			TargetId:      e.TargetId,
+			OldGeneration: e.OldGeneration,
+			NewGeneration: e.NewGeneration,
+			Changes:       e.Changes,
+			Code:          e.Code,
+			TenantId:      e.TenantId,
+		})
+	}
+
+	return list, nil
+}
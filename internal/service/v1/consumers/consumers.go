@@ -2,82 +2,199 @@ package consumers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/kube-orchestra/maestro/internal/audit"
 	"github.com/kube-orchestra/maestro/internal/db"
+	"github.com/kube-orchestra/maestro/internal/encryption"
+	"github.com/kube-orchestra/maestro/internal/placement"
+	"github.com/kube-orchestra/maestro/internal/signing"
 	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 type Service struct {
 	v1.UnimplementedConsumerServiceServer
+	// places and removes the resources of placements as consumers and
+	// their labels change.
+	placements *placement.Reconciler
 }
 
-func NewConsumerService() *Service {
-	return &Service{}
+func NewConsumerService(placements *placement.Reconciler) *Service {
+	return &Service{placements: placements}
 }
 
 func (svc *Service) Read(_ context.Context, r *v1.ConsumerReadRequest) (*v1.Consumer, error) {
-	c, err := db.GetConsumer(r.Id)
+	c, err := db.GetConsumer(db.TenantOrDefault(r.TenantId), r.Id)
 	if err != nil {
 		return nil, err
 	}
 	return c, nil
 }
 
-type ConsumerExistsError struct{}
-
-func (m *ConsumerExistsError) Error() string {
-	return "Consumer already exists, use method PUT to update"
-}
+func (svc *Service) Create(ctx context.Context, r *v1.ConsumerCreateRequest) (*v1.Consumer, error) {
+	id := r.Id
+	if id == "" {
+		id = uuid.NewString()
+	} else if errs := validation.IsDNS1123Subdomain(id); len(errs) > 0 {
+		return nil, &db.ErrorInvalidArgument{Violations: []db.FieldViolation{
+			{Field: "id", Description: strings.Join(errs, ", ")},
+		}}
+	} else if id == db.ReservedConsumerId {
+		return nil, &db.ErrorInvalidArgument{Violations: []db.FieldViolation{
+			{Field: "id", Description: fmt.Sprintf("%q is reserved", id)},
+		}}
+	}
+	audit.SetTarget(ctx, db.ConsumerKind, id)
 
-func (svc *Service) Create(_ context.Context, r *v1.ConsumerCreateRequest) (*v1.Consumer, error) {
-	if r.Id != "" {
-		c, err := db.GetConsumer(r.Id)
-		if err != nil {
-			return nil, err
-		}
-		if c != nil {
-			return nil, &ConsumerExistsError{}
-		}
+	if err := checkAgent(r.PublicKey, r.SigningKey, r.Compression); err != nil {
+		return nil, err
 	}
 
 	newConsumer := &v1.Consumer{
-		Id:     uuid.NewString(),
-		Labels: r.Labels,
+		Id:          id,
+		Labels:      r.Labels,
+		TenantId:    db.TenantOrDefault(r.TenantId),
+		PublicKey:   r.PublicKey,
+		SigningKey:  r.SigningKey,
+		Compression: r.Compression,
 	}
 
-	err := db.PutConsumer(newConsumer)
+	err := db.CreateConsumer(newConsumer)
 	if err != nil {
 		return nil, err
 	}
+	audit.SetChange(ctx, 0, 0, audit.Diff(&v1.Consumer{}, newConsumer))
+	svc.placements.ConsumerChanged(ctx, nil, newConsumer)
 
 	return newConsumer, nil
 }
 
-type ConsumerDoesNotExistError struct{}
-
-func (m *ConsumerDoesNotExistError) Error() string {
-	return "Consumer doesn't exist, use method create it with method POST first"
-}
-
-func (svc *Service) Update(_ context.Context, c *v1.ConsumerUpdateRequest) (*v1.Consumer, error) {
-	consumer, err := db.GetConsumer(c.Id)
+func (svc *Service) Update(ctx context.Context, c *v1.ConsumerUpdateRequest) (*v1.Consumer, error) {
+	consumer, err := db.GetConsumer(db.TenantOrDefault(c.TenantId), c.Id)
 	if err != nil {
 		return nil, err
 	}
-	if consumer == nil {
-		return nil, &ConsumerDoesNotExistError{}
+
+	if err := checkAgent(c.PublicKey, c.SigningKey, c.Compression); err != nil {
+		return nil, err
 	}
 
 	updatedConsumer := &v1.Consumer{
-		Id:     c.Id,
-		Labels: c.Labels,
+		Id:          c.Id,
+		Labels:      c.Labels,
+		TenantId:    consumer.TenantId,
+		PublicKey:   c.PublicKey,
+		SigningKey:  c.SigningKey,
+		Compression: c.Compression,
 	}
 
-	err = db.PutConsumer(updatedConsumer)
+	err = db.UpdateConsumer(updatedConsumer, consumer.Labels)
 	if err != nil {
 		return nil, err
 	}
+	audit.SetChange(ctx, 0, 0, audit.Diff(consumer, updatedConsumer))
+	svc.placements.ConsumerChanged(ctx, consumer, updatedConsumer)
 
 	return updatedConsumer, nil
 }
+
+// Patch adds, overwrites or removes individual labels, leaving the
+// remaining labels of the consumer untouched. The patch is applied again
+// to the labels stored meanwhile by concurrent changes, up to
+// maxPatchAttempts times, then ErrorAborted is returned.
+func (svc *Service) Patch(ctx context.Context, p *v1.ConsumerPatchRequest) (*v1.Consumer, error) {
+	var err error
+	for attempt := 0; attempt < maxPatchAttempts; attempt++ {
+		var consumer *v1.Consumer
+		consumer, err = db.GetConsumer(db.TenantOrDefault(p.TenantId), p.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		// patchLabels updates the labels in place
+		previous := proto.Clone(consumer).(*v1.Consumer)
+		consumer.Labels = patchLabels(consumer.Labels, p.AddLabels, p.RemoveLabels)
+
+		err = db.UpdateConsumerLabels(consumer, previous.Labels)
+		var changed *db.ErrorAborted
+		if errors.As(err, &changed) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		audit.SetChange(ctx, 0, 0, audit.Diff(previous, consumer))
+		svc.placements.ConsumerChanged(ctx, previous, consumer)
+
+		return consumer, nil
+	}
+	return nil, err
+}
+
+// maxPatchAttempts bounds the attempts of Patch while the labels keep
+// changing concurrently.
+const maxPatchAttempts = 5
+
+// checkAgent checks the public and signing keys of a request, which are
+// optional, and its compression.
+func checkAgent(publicKey, signingKey string, compression v1.Compression) error {
+	invalid := &db.ErrorInvalidArgument{}
+	if publicKey != "" {
+		if err := encryption.CheckPublicKey(publicKey); err != nil {
+			invalid.Violations = append(invalid.Violations, db.FieldViolation{Field: "publicKey", Description: err.Error()})
+		}
+	}
+	if signingKey != "" {
+		if _, err := signing.ParsePublicKey(signingKey); err != nil {
+			invalid.Violations = append(invalid.Violations, db.FieldViolation{Field: "signingKey", Description: err.Error()})
+		}
+	}
+	if _, ok := v1.Compression_name[int32(compression)]; !ok {
+		invalid.Violations = append(invalid.Violations, db.FieldViolation{
+			Field:       "compression",
+			Description: fmt.Sprintf("unknown compression %s", compression),
+		})
+	}
+	if len(invalid.Violations) > 0 {
+		return invalid
+	}
+	return nil
+}
+
+func patchLabels(labels, add []*v1.ConsumerLabel, remove []string) []*v1.ConsumerLabel {
+	removed := make(map[string]bool, len(remove))
+	for _, key := range remove {
+		removed[key] = true
+	}
+
+	added := make(map[string]string, len(add))
+	for _, l := range add {
+		added[l.Key] = l.Value
+	}
+
+	patched := []*v1.ConsumerLabel{}
+	for _, l := range labels {
+		if removed[l.Key] {
+			continue
+		}
+		if value, ok := added[l.Key]; ok {
+			l.Value = value
+			delete(added, l.Key)
+		}
+		patched = append(patched, l)
+	}
+
+	for _, l := range add {
+		if _, ok := added[l.Key]; ok && !removed[l.Key] {
+			patched = append(patched, l)
+			delete(added, l.Key)
+		}
+	}
+
+	return patched
+}
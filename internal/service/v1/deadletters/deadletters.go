@@ -0,0 +1,123 @@
+package deadletters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kube-orchestra/maestro/internal/db"
+	"github.com/kube-orchestra/maestro/internal/mqtt"
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
+)
+
+const defaultLimit = 100
+
+type Service struct {
+	v1.UnimplementedDeadLetterServiceServer
+	// processes the messages of the replayed dead letters.
+	connection *mqtt.Connection
+}
+
+func NewDeadLetterService(connection *mqtt.Connection) *Service {
+	return &Service{connection: connection}
+}
+
+func (svc *Service) Read(_ context.Context, r *v1.DeadLetterReadRequest) (*v1.DeadLetter, error) {
+	d, err := db.GetDeadLetter(db.TenantOrDefault(r.TenantId), r.Id)
+	if err != nil {
+		return nil, err
+	}
+	return toDeadLetterResponse(d), nil
+}
+
+// List returns the dead letters of a tenant, newest first, and how many
+// messages of the tenant were dead-lettered so far.
+func (svc *Service) List(_ context.Context, r *v1.DeadLetterListRequest) (*v1.DeadLetterList, error) {
+	if r.Limit < 0 {
+		return nil, &db.ErrorInvalidArgument{Violations: []db.FieldViolation{
+			{Field: "limit", Description: "must not be negative"},
+		}}
+	}
+	limit := int(r.Limit)
+	if limit == 0 {
+		limit = defaultLimit
+	}
+
+	tenantID := db.TenantOrDefault(r.TenantId)
+	deadLetters, err := db.ListDeadLetters(tenantID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &v1.DeadLetterList{}
+	t, err := db.GetTenant(tenantID)
+	var notFound *db.ErrorNotFound
+	if err != nil && !errors.As(err, &notFound) {
+		return nil, err
+	}
+	if err == nil {
+		list.Count = t.DeadLetterCount
+	}
+
+	for i := range deadLetters {
+		list.DeadLetters = append(list.DeadLetters, toDeadLetterResponse(&deadLetters[i]))
+	}
+	return list, nil
+}
+
+// Replay processes the message of a dead letter again, as if it was just
+// received, and discards the dead letter when it succeeds.
+func (svc *Service) Replay(_ context.Context, r *v1.DeadLetterReplayRequest) (*v1.DeadLetter, error) {
+	d, err := db.GetDeadLetter(db.TenantOrDefault(r.TenantId), r.Id)
+	if err != nil {
+		return nil, err
+	}
+	if d.Truncated {
+		return nil, &db.ErrorFailedPrecondition{
+			Type:        "TRUNCATED",
+			Subject:     d.Id,
+			Description: fmt.Sprintf("the payload of dead letter %q is truncated", d.Id),
+		}
+	}
+
+	err = svc.connection.Replay(d.Topic, d.Payload)
+	var unavailable *db.ErrorUnavailable
+	if errors.As(err, &unavailable) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, &db.ErrorFailedPrecondition{
+			Type:        "REPLAY",
+			Subject:     d.Id,
+			Description: fmt.Sprintf("dead letter %q failed again: %v", d.Id, err),
+		}
+	}
+
+	d, err = db.DeleteDeadLetter(d.TenantId, d.Id)
+	if err != nil {
+		return nil, err
+	}
+	return toDeadLetterResponse(d), nil
+}
+
+// Delete discards a dead letter without processing it.
+func (svc *Service) Delete(_ context.Context, r *v1.DeadLetterDeleteRequest) (*v1.DeadLetter, error) {
+	d, err := db.DeleteDeadLetter(db.TenantOrDefault(r.TenantId), r.Id)
+	if err != nil {
+		return nil, err
+	}
+	return toDeadLetterResponse(d), nil
+}
+
+func toDeadLetterResponse(d *db.DeadLetter) *v1.DeadLetter {
+	return &v1.DeadLetter{
+		Id:        d.Id,
+		TenantId:  d.TenantId,
+		Topic:     d.Topic,
+		Payload:   d.Payload,
+		Truncated: d.Truncated,
+		Error:     d.Error,
+		Timestamp: time.Unix(0, d.Timestamp).UTC().Format(time.RFC3339Nano),
+	}
+}
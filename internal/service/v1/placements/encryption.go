@@ -0,0 +1,34 @@
+package placements
+
+import (
+	"github.com/kube-orchestra/maestro/internal/db"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// encrypt encrypts the sensitive values of the object and previous object
+// of p in place, with a new data key.
+func (svc *Service) encrypt(p *db.Placement) error {
+	objects := []*unstructured.Unstructured{&p.Object}
+	if p.PreviousObject != nil {
+		objects = append(objects, p.PreviousObject)
+	}
+
+	var err error
+	p.Encryption, err = svc.encryption.Encrypt(p.Id, objects...)
+	return err
+}
+
+// decrypt decrypts the object and previous object of p in place.
+func (svc *Service) decrypt(p *db.Placement) error {
+	objects := []*unstructured.Unstructured{&p.Object}
+	if p.PreviousObject != nil {
+		objects = append(objects, p.PreviousObject)
+	}
+
+	err := svc.encryption.Decrypt(p.Encryption, p.Id, objects...)
+	if err != nil {
+		return err
+	}
+	p.Encryption = nil
+	return nil
+}
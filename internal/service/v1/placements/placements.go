@@ -0,0 +1,278 @@
+package placements
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/kube-orchestra/maestro/internal/audit"
+	"github.com/kube-orchestra/maestro/internal/db"
+	"github.com/kube-orchestra/maestro/internal/encryption"
+	"github.com/kube-orchestra/maestro/internal/manifest"
+	"github.com/kube-orchestra/maestro/internal/placement"
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
+	"google.golang.org/protobuf/types/known/structpb"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+type Service struct {
+	v1.UnimplementedPlacementServiceServer
+	validator  *manifest.Validator
+	reconciler *placement.Reconciler
+	encryption *encryption.Encryptor
+}
+
+func NewPlacementService(validator *manifest.Validator, reconciler *placement.Reconciler, encryption *encryption.Encryptor) *Service {
+	return &Service{validator: validator, reconciler: reconciler, encryption: encryption}
+}
+
+// Read returns a placement with the status of its resources.
+func (svc *Service) Read(_ context.Context, r *v1.PlacementReadRequest) (*v1.Placement, error) {
+	p, err := db.GetPlacement(db.TenantOrDefault(r.TenantId), r.Id)
+	if err != nil {
+		return nil, err
+	}
+	return toPlacementResponse(p)
+}
+
+// Create stores a new placement and creates its resources for the
+// consumers matching its selector.
+func (svc *Service) Create(ctx context.Context, r *v1.PlacementCreateRequest) (*v1.Placement, error) {
+	object, err := svc.validate(r.ConsumerSelector, r.Object, r.Template)
+	if err != nil {
+		return nil, err
+	}
+	rollout, err := toRolloutStrategy(r.Rollout)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &db.Placement{
+		TenantId:             db.TenantOrDefault(r.TenantId),
+		Id:                   uuid.NewString(),
+		ConsumerSelector:     r.ConsumerSelector,
+		Object:               *object,
+		ResourceGenerationID: 1,
+		Rollout:              rollout,
+		RolloutPhase:         db.RolloutProgressing,
+		Template:             r.Template,
+		ConsumerValues:       toConsumerValues(r.ConsumerValues),
+	}
+	audit.SetTarget(ctx, db.PlacementKind, p.Id)
+	changes := audit.Diff(map[string]interface{}{}, placementFields(p))
+
+	err = svc.encrypt(p)
+	if err != nil {
+		return nil, err
+	}
+	err = db.CreatePlacement(p)
+	if err != nil {
+		return nil, err
+	}
+	audit.SetChange(ctx, 0, p.ResourceGenerationID, changes)
+
+	p, err = svc.reconciler.Reconcile(ctx, p.TenantId, p.Id)
+	if err != nil {
+		return nil, err
+	}
+	return toPlacementResponse(p)
+}
+
+// Update replaces the selector and object of a placement, then updates,
+// creates and deletes its resources accordingly, starting a new rollout.
+func (svc *Service) Update(ctx context.Context, r *v1.PlacementUpdateRequest) (*v1.Placement, error) {
+	object, err := svc.validate(r.ConsumerSelector, r.Object, r.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := db.GetPlacement(db.TenantOrDefault(r.TenantId), r.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = svc.decrypt(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Rollout != nil {
+		p.Rollout, err = toRolloutStrategy(r.Rollout)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	previous := placementFields(p)
+	// rollbacks restore the last object rolled out to every target
+	if p.RolloutPhase == db.RolloutComplete || p.RolloutPhase == db.RolloutRolledBack {
+		p.PreviousObject = p.Object.DeepCopy()
+	}
+	p.ConsumerSelector = r.ConsumerSelector
+	p.Object = *object
+	p.Template = r.Template
+	p.ConsumerValues = toConsumerValues(r.ConsumerValues)
+	p.ResourceGenerationID++
+	p.RolloutPhase = db.RolloutProgressing
+	p.RolloutMessage = ""
+	p.ResumedFailures = nil
+	changes := audit.Diff(previous, placementFields(p))
+
+	err = svc.encrypt(p)
+	if err != nil {
+		return nil, err
+	}
+	err = db.UpdatePlacement(p)
+	if err != nil {
+		return nil, err
+	}
+	audit.SetChange(ctx, p.ResourceGenerationID-1, p.ResourceGenerationID, changes)
+
+	p, err = svc.reconciler.Reconcile(ctx, p.TenantId, p.Id)
+	if err != nil {
+		return nil, err
+	}
+	return toPlacementResponse(p)
+}
+
+// Resume continues the paused rollout of a placement.
+func (svc *Service) Resume(ctx context.Context, r *v1.PlacementResumeRequest) (*v1.Placement, error) {
+	p, err := svc.reconciler.Resume(ctx, db.TenantOrDefault(r.TenantId), r.Id)
+	if err != nil {
+		return nil, err
+	}
+	audit.SetChange(ctx, p.ResourceGenerationID, p.ResourceGenerationID, []string{"rolloutPhase"})
+
+	return toPlacementResponse(p)
+}
+
+// Delete removes a placement. Its resources are deleted from their
+// consumers, the placement is removed once they all are.
+func (svc *Service) Delete(ctx context.Context, r *v1.PlacementDeleteRequest) (*v1.Placement, error) {
+	p, err := svc.reconciler.Remove(db.TenantOrDefault(r.TenantId), r.Id)
+	if p != nil {
+		audit.SetChange(ctx, p.ResourceGenerationID, 0, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toPlacementResponse(p)
+}
+
+// validate checks the selector and object of a request. Templates are only
+// parsed, their rendering is validated for every consumer.
+func (svc *Service) validate(selector string, object *structpb.Struct, isTemplate bool) (*unstructured.Unstructured, error) {
+	if _, err := labels.Parse(selector); err != nil {
+		return nil, &db.ErrorInvalidArgument{Violations: []db.FieldViolation{
+			{Field: "consumerSelector", Description: err.Error()},
+		}}
+	}
+	if object == nil {
+		return nil, &db.ErrorInvalidArgument{Violations: []db.FieldViolation{
+			{Field: "object", Description: "a Kubernetes manifest is required"},
+		}}
+	}
+
+	u := &unstructured.Unstructured{Object: object.AsMap()}
+	if isTemplate {
+		return u, manifest.CheckTemplate("object", u)
+	}
+	err := svc.validator.Validate("object", u)
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// placementFields are the fields of a placement set by its callers, as
+// compared in audit events.
+func placementFields(p *db.Placement) map[string]interface{} {
+	return map[string]interface{}{
+		"consumerSelector": p.ConsumerSelector,
+		"object":           p.Object.Object,
+		"rollout":          p.Rollout,
+		"template":         p.Template,
+		"consumerValues":   p.ConsumerValues,
+	}
+}
+
+func toPlacementResponse(p *db.Placement) (*v1.Placement, error) {
+	object, err := structpb.NewStruct(p.Object.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := aggregateStatus(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.Placement{
+		Id:               p.Id,
+		TenantId:         p.TenantId,
+		ConsumerSelector: p.ConsumerSelector,
+		Object:           object,
+		GenerationId:     p.ResourceGenerationID,
+		Status:           status,
+		Rollout:          toRolloutStrategyResponse(p.Rollout),
+		Template:         p.Template,
+		ConsumerValues:   toConsumerValuesResponse(p.ConsumerValues),
+		Deleting:         p.Deleting,
+	}, nil
+}
+
+// aggregateStatus counts the targets of a placement by state, from the
+// status reported for their resource.
+func aggregateStatus(p *db.Placement) (*v1.PlacementStatus, error) {
+	consumerIDs := make([]string, 0, len(p.Targets))
+	for id := range p.Targets {
+		consumerIDs = append(consumerIDs, id)
+	}
+	sort.Strings(consumerIDs)
+
+	status := &v1.PlacementStatus{
+		RolloutPhase:   toRolloutPhaseResponse(p.RolloutPhase),
+		RolloutMessage: p.RolloutMessage,
+	}
+	for _, consumerID := range consumerIDs {
+		t, err := placement.TargetStatus(p, consumerID, p.Targets[consumerID])
+		if err != nil {
+			return nil, err
+		}
+
+		status.Targets++
+		switch t.State {
+		case v1.PlacementTargetState_APPLIED:
+			status.Applied++
+		case v1.PlacementTargetState_FAILED:
+			status.Failed++
+		default:
+			status.Pending++
+		}
+		status.Details = append(status.Details, t)
+	}
+	return status, nil
+}
+
+func toConsumerValues(values map[string]*v1.TemplateValues) map[string]map[string]string {
+	if len(values) == 0 {
+		return nil
+	}
+	converted := make(map[string]map[string]string, len(values))
+	for consumerID, v := range values {
+		converted[consumerID] = v.GetValues()
+	}
+	return converted
+}
+
+func toConsumerValuesResponse(values map[string]map[string]string) map[string]*v1.TemplateValues {
+	if len(values) == 0 {
+		return nil
+	}
+	converted := make(map[string]*v1.TemplateValues, len(values))
+	for consumerID, v := range values {
+		converted[consumerID] = &v1.TemplateValues{Values: v}
+	}
+	return converted
+}
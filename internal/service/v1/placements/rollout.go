@@ -0,0 +1,108 @@
+package placements
+
+import (
+	"fmt"
+
+	"github.com/kube-orchestra/maestro/internal/db"
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// toRolloutStrategy converts and validates the rollout strategy of a
+// request. A nil strategy updates every target at once.
+func toRolloutStrategy(strategy *v1.RolloutStrategy) (db.RolloutStrategy, error) {
+	converted := db.RolloutStrategy{Type: db.RolloutAllAtOnce, OnFailure: db.RolloutOnFailurePause}
+	if strategy == nil {
+		return converted, nil
+	}
+
+	invalid := &db.ErrorInvalidArgument{}
+	switch strategy.Type {
+	case v1.RolloutType_ROLLOUT_TYPE_UNSPECIFIED, v1.RolloutType_ALL_AT_ONCE:
+	case v1.RolloutType_ROLLING:
+		converted.Type = db.RolloutRolling
+	case v1.RolloutType_CANARY:
+		converted.Type = db.RolloutCanary
+		if _, err := labels.Parse(strategy.CanarySelector); strategy.CanarySelector == "" || err != nil {
+			description := "a label selector is required"
+			if err != nil {
+				description = err.Error()
+			}
+			invalid.Violations = append(invalid.Violations, db.FieldViolation{
+				Field:       "rollout.canarySelector",
+				Description: description,
+			})
+		}
+		converted.CanarySelector = strategy.CanarySelector
+	default:
+		invalid.Violations = append(invalid.Violations, db.FieldViolation{
+			Field:       "rollout.type",
+			Description: fmt.Sprintf("unknown rollout type %s", strategy.Type),
+		})
+	}
+
+	switch strategy.OnFailure {
+	case v1.RolloutFailureAction_ROLLOUT_FAILURE_ACTION_UNSPECIFIED, v1.RolloutFailureAction_PAUSE:
+	case v1.RolloutFailureAction_ROLLBACK:
+		converted.OnFailure = db.RolloutOnFailureRollback
+	default:
+		invalid.Violations = append(invalid.Violations, db.FieldViolation{
+			Field:       "rollout.onFailure",
+			Description: fmt.Sprintf("unknown rollout failure action %s", strategy.OnFailure),
+		})
+	}
+
+	if strategy.MaxConcurrency < 0 {
+		invalid.Violations = append(invalid.Violations, db.FieldViolation{
+			Field:       "rollout.maxConcurrency",
+			Description: "must not be negative",
+		})
+	}
+	if strategy.MaxFailures < 0 {
+		invalid.Violations = append(invalid.Violations, db.FieldViolation{
+			Field:       "rollout.maxFailures",
+			Description: "must not be negative",
+		})
+	}
+	converted.MaxConcurrency = strategy.MaxConcurrency
+	converted.MaxFailures = strategy.MaxFailures
+
+	if len(invalid.Violations) > 0 {
+		return db.RolloutStrategy{}, invalid
+	}
+	return converted, nil
+}
+
+func toRolloutStrategyResponse(strategy db.RolloutStrategy) *v1.RolloutStrategy {
+	converted := &v1.RolloutStrategy{
+		Type:           v1.RolloutType_ALL_AT_ONCE,
+		MaxConcurrency: strategy.MaxConcurrency,
+		CanarySelector: strategy.CanarySelector,
+		MaxFailures:    strategy.MaxFailures,
+		OnFailure:      v1.RolloutFailureAction_PAUSE,
+	}
+	switch strategy.Type {
+	case db.RolloutRolling:
+		converted.Type = v1.RolloutType_ROLLING
+	case db.RolloutCanary:
+		converted.Type = v1.RolloutType_CANARY
+	}
+	if strategy.OnFailure == db.RolloutOnFailureRollback {
+		converted.OnFailure = v1.RolloutFailureAction_ROLLBACK
+	}
+	return converted
+}
+
+func toRolloutPhaseResponse(phase string) v1.RolloutPhase {
+	switch phase {
+	case db.RolloutProgressing:
+		return v1.RolloutPhase_PROGRESSING
+	case db.RolloutComplete:
+		return v1.RolloutPhase_COMPLETE
+	case db.RolloutPaused:
+		return v1.RolloutPhase_PAUSED
+	case db.RolloutRolledBack:
+		return v1.RolloutPhase_ROLLED_BACK
+	}
+	return v1.RolloutPhase_ROLLOUT_PHASE_UNSPECIFIED
+}
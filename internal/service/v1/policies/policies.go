@@ -0,0 +1,123 @@
+package policies
+
+import (
+	"context"
+	"strings"
+
+	"github.com/kube-orchestra/maestro/internal/audit"
+	"github.com/kube-orchestra/maestro/internal/db"
+	"github.com/kube-orchestra/maestro/internal/policy"
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+type Service struct {
+	v1.UnimplementedPolicyServiceServer
+	engine *policy.Engine
+}
+
+func NewPolicyService(engine *policy.Engine) *Service {
+	return &Service{engine: engine}
+}
+
+func (svc *Service) Read(_ context.Context, r *v1.PolicyReadRequest) (*v1.Policy, error) {
+	p, err := db.GetPolicy(db.TenantOrDefault(r.TenantId), r.Id)
+	if err != nil {
+		return nil, err
+	}
+	return toPolicyResponse(p), nil
+}
+
+func (svc *Service) List(_ context.Context, r *v1.PolicyListRequest) (*v1.PolicyList, error) {
+	policies, err := db.ListPolicies(db.TenantOrDefault(r.TenantId))
+	if err != nil {
+		return nil, err
+	}
+
+	list := &v1.PolicyList{}
+	for i := range policies {
+		list.Policies = append(list.Policies, toPolicyResponse(&policies[i]))
+	}
+	return list, nil
+}
+
+// Create stores a new policy, evaluated from then on for the resources
+// created and updated in its tenant.
+func (svc *Service) Create(ctx context.Context, r *v1.PolicyCreateRequest) (*v1.Policy, error) {
+	violations := svc.expressionViolations(r.Expression)
+	if errs := validation.IsDNS1123Subdomain(r.Id); len(errs) > 0 {
+		violations = append(violations, db.FieldViolation{Field: "id", Description: strings.Join(errs, ", ")})
+	}
+	if len(violations) > 0 {
+		return nil, &db.ErrorInvalidArgument{Violations: violations}
+	}
+	audit.SetTarget(ctx, db.PolicyKind, r.Id)
+
+	p := &db.Policy{
+		TenantId:   db.TenantOrDefault(r.TenantId),
+		Id:         r.Id,
+		Expression: r.Expression,
+		Message:    r.Message,
+	}
+	err := db.CreatePolicy(p)
+	if err != nil {
+		return nil, err
+	}
+	audit.SetChange(ctx, 0, 0, audit.Diff(&db.Policy{}, p))
+
+	return toPolicyResponse(p), nil
+}
+
+func (svc *Service) Update(ctx context.Context, r *v1.PolicyUpdateRequest) (*v1.Policy, error) {
+	if violations := svc.expressionViolations(r.Expression); len(violations) > 0 {
+		return nil, &db.ErrorInvalidArgument{Violations: violations}
+	}
+
+	previous, err := db.GetPolicy(db.TenantOrDefault(r.TenantId), r.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &db.Policy{
+		TenantId:   previous.TenantId,
+		Id:         r.Id,
+		Expression: r.Expression,
+		Message:    r.Message,
+	}
+	err = db.UpdatePolicy(p)
+	if err != nil {
+		return nil, err
+	}
+	audit.SetChange(ctx, 0, 0, audit.Diff(previous, p))
+
+	return toPolicyResponse(p), nil
+}
+
+func (svc *Service) Delete(ctx context.Context, r *v1.PolicyDeleteRequest) (*v1.Policy, error) {
+	p, err := db.DeletePolicy(db.TenantOrDefault(r.TenantId), r.Id)
+	if err != nil {
+		return nil, err
+	}
+	audit.SetChange(ctx, 0, 0, audit.Diff(p, &db.Policy{}))
+
+	return toPolicyResponse(p), nil
+}
+
+func (svc *Service) expressionViolations(expression string) []db.FieldViolation {
+	if expression == "" {
+		return []db.FieldViolation{{Field: "expression", Description: "a CEL expression is required"}}
+	}
+	if err := svc.engine.Check(expression); err != nil {
+		return []db.FieldViolation{{Field: "expression", Description: err.Error()}}
+	}
+	return nil
+}
+
+func toPolicyResponse(p *db.Policy) *v1.Policy {
+	return &v1.Policy{
+		Id:         p.Id,
+		TenantId:   p.TenantId,
+		Expression: p.Expression,
+		Message:    p.Message,
+	}
+}
@@ -0,0 +1,39 @@
+package resourcebundles
+
+import (
+	"github.com/kube-orchestra/maestro/internal/db"
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// encrypt encrypts the sensitive values of the manifests of b in place,
+// with a new data key.
+func (svc *ResourceBundlesService) encrypt(b *db.ResourceBundle) error {
+	var err error
+	b.Encryption, err = svc.encryption.Encrypt(b.Id, manifestsOf(b)...)
+	return err
+}
+
+// decrypt decrypts the manifests of b in place.
+func (svc *ResourceBundlesService) decrypt(b *db.ResourceBundle) error {
+	err := svc.encryption.Decrypt(b.Encryption, b.Id, manifestsOf(b)...)
+	if err != nil {
+		return err
+	}
+	b.Encryption = nil
+	return nil
+}
+
+// content returns the manifests of b as published to consumer: their values
+// stay encrypted when the consumer has a public key.
+func (svc *ResourceBundlesService) content(consumer *v1.Consumer, b *db.ResourceBundle) ([]*unstructured.Unstructured, *db.ContentEncryption, error) {
+	return svc.encryption.Seal(b.Encryption, b.Id, consumer.PublicKey, manifestsOf(b)...)
+}
+
+func manifestsOf(b *db.ResourceBundle) []*unstructured.Unstructured {
+	manifests := make([]*unstructured.Unstructured, len(b.Manifests))
+	for i := range b.Manifests {
+		manifests[i] = &b.Manifests[i]
+	}
+	return manifests
+}
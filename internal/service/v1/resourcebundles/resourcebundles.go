@@ -0,0 +1,368 @@
+package resourcebundles
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/kube-orchestra/maestro/internal/audit"
+	"github.com/kube-orchestra/maestro/internal/db"
+	"github.com/kube-orchestra/maestro/internal/encryption"
+	"github.com/kube-orchestra/maestro/internal/limits"
+	"github.com/kube-orchestra/maestro/internal/manifest"
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
+	"google.golang.org/protobuf/types/known/structpb"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type ResourceBundlesService struct {
+	v1.UnimplementedResourceBundleServiceServer
+	bundleChan chan<- db.ResourceBundleMessage
+	validator  *manifest.Validator
+	limits     *limits.Limits
+	encryption *encryption.Encryptor
+}
+
+func NewResourceBundleService(bundleChan chan<- db.ResourceBundleMessage, validator *manifest.Validator, limits *limits.Limits, encryption *encryption.Encryptor) *ResourceBundlesService {
+	return &ResourceBundlesService{bundleChan: bundleChan, validator: validator, limits: limits, encryption: encryption}
+}
+
+func (svc *ResourceBundlesService) Read(_ context.Context, r *v1.ResourceBundleReadRequest) (*v1.ResourceBundle, error) {
+	b, err := db.GetResourceBundle(db.TenantOrDefault(r.TenantId), r.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toResourceBundleResponse(b)
+}
+
+func (svc *ResourceBundlesService) Create(ctx context.Context, r *v1.ResourceBundleCreateRequest) (*v1.ResourceBundle, error) {
+	manifests, err := svc.toManifests(r.Manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	tenantID := db.TenantOrDefault(r.TenantId)
+	consumer, err := db.RequireConsumer(tenantID, r.ConsumerId)
+	if err != nil {
+		return nil, err
+	}
+	limit := svc.limits.For(consumer)
+	err = admit(limit, manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	contentHash, err := manifest.ListContentHash(manifests)
+	if err != nil {
+		return nil, err
+	}
+	contentHash = svc.encryption.ContentHash(contentHash)
+
+	b := &db.ResourceBundle{
+		Id:          uuid.NewString(),
+		TenantId:    tenantID,
+		ConsumerId:  r.ConsumerId,
+		Manifests:   manifests,
+		ContentHash: contentHash,
+	}
+	audit.SetTarget(ctx, db.ResourceBundleKind, b.Id)
+	changes := audit.Diff(byOrdinal(nil), byOrdinal(manifests))
+
+	err = svc.encrypt(b)
+	if err != nil {
+		return nil, err
+	}
+	err = db.CreateResourceBundle(b, limit.MaxResources)
+	if err != nil {
+		return nil, err
+	}
+	audit.SetChange(ctx, 0, b.ResourceGenerationID, changes)
+
+	err = svc.publish(consumer, b)
+	if err != nil {
+		return nil, err
+	}
+
+	return toResourceBundleResponse(b)
+}
+
+// Update replaces the manifests of a bundle. Manifests whose content hash
+// didn't change are neither stored nor published.
+func (svc *ResourceBundlesService) Update(ctx context.Context, r *v1.ResourceBundleUpdateRequest) (*v1.ResourceBundle, error) {
+	manifests, err := svc.toManifests(r.Manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := db.GetResourceBundle(db.TenantOrDefault(r.TenantId), r.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.Deleting {
+		return nil, &db.ErrorFailedPrecondition{
+			Type:        "DELETION",
+			Subject:     b.Id,
+			Description: fmt.Sprintf("resource bundle %q is being deleted", b.Id),
+		}
+	}
+
+	contentHash, err := manifest.ListContentHash(manifests)
+	if err != nil {
+		return nil, err
+	}
+	contentHash = svc.encryption.ContentHash(contentHash)
+	if b.ContentHash == contentHash {
+		audit.SetChange(ctx, b.ResourceGenerationID, b.ResourceGenerationID, nil)
+		return toResourceBundleResponse(b)
+	}
+
+	consumer, err := db.GetConsumer(b.TenantId, b.ConsumerId)
+	if err != nil {
+		return nil, err
+	}
+	err = admit(svc.limits.For(consumer), manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	err = svc.decrypt(b)
+	if err != nil {
+		return nil, err
+	}
+	previous := db.TargetKeysOf(b)
+	changes := audit.Diff(byOrdinal(b.Manifests), byOrdinal(manifests))
+	b.Manifests = manifests
+	b.ContentHash = contentHash
+
+	err = svc.encrypt(b)
+	if err != nil {
+		return nil, err
+	}
+	err = db.UpdateResourceBundle(b, previous)
+	if err != nil {
+		return nil, err
+	}
+	audit.SetChange(ctx, b.ResourceGenerationID-1, b.ResourceGenerationID, changes)
+
+	err = svc.publish(consumer, b)
+	if err != nil {
+		return nil, err
+	}
+
+	return toResourceBundleResponse(b)
+}
+
+// Delete asks the agent to remove every manifest of the bundle. The bundle
+// is kept, flagged as deleting, until the agent reports it is deleted.
+func (svc *ResourceBundlesService) Delete(ctx context.Context, r *v1.ResourceBundleDeleteRequest) (*v1.ResourceBundle, error) {
+	b, err := db.GetResourceBundle(db.TenantOrDefault(r.TenantId), r.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.Deleting {
+		audit.SetChange(ctx, b.ResourceGenerationID, b.ResourceGenerationID, nil)
+		return toResourceBundleResponse(b)
+	}
+
+	consumer, err := db.GetConsumer(b.TenantId, b.ConsumerId)
+	if err != nil {
+		return nil, err
+	}
+
+	b.Deleting = true
+
+	err = db.UpdateResourceBundle(b, db.TargetKeysOf(b))
+	if err != nil {
+		return nil, err
+	}
+	audit.SetChange(ctx, b.ResourceGenerationID-1, b.ResourceGenerationID, []string{"deleting"})
+
+	err = svc.publish(consumer, b)
+	if err != nil {
+		return nil, err
+	}
+
+	return toResourceBundleResponse(b)
+}
+
+func (svc *ResourceBundlesService) publish(consumer *v1.Consumer, b *db.ResourceBundle) error {
+	manifests, contentEncryption, err := svc.content(consumer, b)
+	if err != nil {
+		return err
+	}
+
+	svc.bundleChan <- db.ResourceBundleMessage{
+		Id:          b.Id,
+		TenantId:    b.TenantId,
+		ConsumerId:  b.ConsumerId,
+		Compression: consumer.Compression,
+		MessageMeta: db.MessageMeta{
+			ResourceGenerationID: b.ResourceGenerationID,
+			ContentHash:          b.ContentHash,
+		},
+		Manifests:  manifests,
+		Encryption: contentEncryption,
+		Delete:     b.Deleting,
+	}
+	return nil
+}
+
+// byOrdinal keys manifests by their position, so audit diffs name the
+// fields of each manifest.
+func byOrdinal(manifests []unstructured.Unstructured) map[string]interface{} {
+	m := make(map[string]interface{}, len(manifests))
+	for i := range manifests {
+		m[fmt.Sprintf("manifests[%d]", i)] = manifests[i].Object
+	}
+	return m
+}
+
+// toManifests converts and validates the manifests of a request.
+func (svc *ResourceBundlesService) toManifests(structs []*structpb.Struct) ([]unstructured.Unstructured, error) {
+	if len(structs) == 0 {
+		return nil, &db.ErrorInvalidArgument{Violations: []db.FieldViolation{
+			{Field: "manifests", Description: "at least one Kubernetes manifest is required"},
+		}}
+	}
+	if len(structs) > db.MaxBundleManifests {
+		return nil, &db.ErrorInvalidArgument{Violations: []db.FieldViolation{
+			{Field: "manifests", Description: fmt.Sprintf("at most %d Kubernetes manifests are allowed", db.MaxBundleManifests)},
+		}}
+	}
+
+	invalid := &db.ErrorInvalidArgument{}
+	manifests := make([]unstructured.Unstructured, len(structs))
+	for i, s := range structs {
+		manifests[i] = unstructured.Unstructured{Object: s.AsMap()}
+
+		err := svc.validator.Validate(fmt.Sprintf("manifests[%d]", i), &manifests[i])
+		var invalidErr *db.ErrorInvalidArgument
+		if errors.As(err, &invalidErr) {
+			invalid.Violations = append(invalid.Violations, invalidErr.Violations...)
+		}
+	}
+	if len(invalid.Violations) > 0 {
+		return nil, invalid
+	}
+
+	// a target object is managed by a single manifest
+	first := map[string]int{}
+	for i, key := range db.TargetKeysOf(&db.ResourceBundle{Manifests: manifests}) {
+		if j, ok := first[key.String()]; ok {
+			invalid.Violations = append(invalid.Violations, db.FieldViolation{
+				Field:       fmt.Sprintf("manifests[%d]", i),
+				Description: fmt.Sprintf("manages the same object as manifests[%d]", j),
+			})
+			continue
+		}
+		first[key.String()] = i
+	}
+	if len(invalid.Violations) > 0 {
+		return nil, invalid
+	}
+
+	return manifests, nil
+}
+
+// admit checks every manifest against the limit of the consumer of a
+// bundle.
+func admit(limit *limits.Limit, manifests []unstructured.Unstructured) error {
+	invalid := &db.ErrorInvalidArgument{}
+	for i := range manifests {
+		err := limit.Admit(fmt.Sprintf("manifests[%d]", i), &manifests[i])
+		var invalidErr *db.ErrorInvalidArgument
+		if errors.As(err, &invalidErr) {
+			invalid.Violations = append(invalid.Violations, invalidErr.Violations...)
+		} else if err != nil {
+			return err
+		}
+	}
+	if len(invalid.Violations) > 0 {
+		return invalid
+	}
+	return nil
+}
+
+// statusSummary counts the manifests by their Reconciled condition at the
+// current generation of the bundle.
+type statusSummary struct {
+	Total      int `json:"total"`
+	Reconciled int `json:"reconciled"`
+	Failed     int `json:"failed"`
+	Pending    int `json:"pending"`
+}
+
+func summarize(b *db.ResourceBundle) statusSummary {
+	summary := statusSummary{Total: len(b.Manifests)}
+	if b.Status.ResourceGenerationID == b.ResourceGenerationID {
+		for _, s := range b.Status.ManifestStatuses {
+			if s.Ordinal < 0 || s.Ordinal >= summary.Total {
+				continue
+			}
+			reconciled := meta.FindStatusCondition(s.ReconcileStatus.Conditions, db.StatusMessageReconciled)
+			if reconciled == nil {
+				continue
+			}
+			switch reconciled.Status {
+			case metav1.ConditionTrue:
+				summary.Reconciled++
+			case metav1.ConditionFalse:
+				summary.Failed++
+			}
+		}
+	}
+	summary.Pending = summary.Total - summary.Reconciled - summary.Failed
+	return summary
+}
+
+func toResourceBundleResponse(b *db.ResourceBundle) (*v1.ResourceBundle, error) {
+	manifests := make([]*structpb.Struct, len(b.Manifests))
+	for i := range b.Manifests {
+		s, err := structpb.NewStruct(b.Manifests[i].UnstructuredContent())
+		if err != nil {
+			return nil, err
+		}
+		manifests[i] = s
+	}
+
+	// status to proto struct
+	statusJson, _ := json.Marshal(&b.Status)
+	var statusMap map[string]interface{}
+	err := json.Unmarshal(statusJson, &statusMap)
+	if err != nil {
+		return nil, err
+	}
+	summaryJson, _ := json.Marshal(summarize(b))
+	var summaryMap map[string]interface{}
+	err = json.Unmarshal(summaryJson, &summaryMap)
+	if err != nil {
+		return nil, err
+	}
+	statusMap["summary"] = summaryMap
+
+	statusProtoStruct, err := structpb.NewStruct(statusMap)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.ResourceBundle{
+		Id:           b.Id,
+		TenantId:     b.TenantId,
+		ConsumerId:   b.ConsumerId,
+		GenerationId: b.ResourceGenerationID,
+		Manifests:    manifests,
+		Status:       statusProtoStruct,
+		ContentHash:  b.ContentHash,
+		Deleting:     b.Deleting,
+		// reported along with each status
+		ObservedGenerationId: b.Status.ResourceGenerationID,
+		InSync:               b.Status.ResourceGenerationID == b.ResourceGenerationID,
+	}, nil
+}
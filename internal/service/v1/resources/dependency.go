@@ -0,0 +1,208 @@
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/kube-orchestra/maestro/internal/db"
+	"github.com/kube-orchestra/maestro/internal/manifest"
+)
+
+// toDependencies converts and validates the dependencies of a request for
+// res: they must be other resources of its consumer, and must not depend
+// on res themselves.
+func toDependencies(res *db.Resource, dependsOn map[string]string) ([]db.Dependency, error) {
+	ids := make([]string, 0, len(dependsOn))
+	for id := range dependsOn {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	invalid := &db.ErrorInvalidArgument{}
+	var converted []db.Dependency
+	for _, id := range ids {
+		d := db.Dependency{ResourceId: id, Condition: dependsOn[id]}
+		field := fmt.Sprintf("dependsOn[%s]", id)
+		if d.ResourceId == "" || d.ResourceId == res.Id {
+			invalid.Violations = append(invalid.Violations, db.FieldViolation{
+				Field:       field,
+				Description: "the Id of another resource of the consumer is required",
+			})
+			continue
+		}
+
+		dependency, err := db.GetResource(res.TenantId, d.ResourceId)
+		var notFound *db.ErrorNotFound
+		if errors.As(err, &notFound) || (err == nil && dependency.ConsumerId != res.ConsumerId) {
+			invalid.Violations = append(invalid.Violations, db.FieldViolation{
+				Field:       field,
+				Description: fmt.Sprintf("consumer %q has no resource %q", res.ConsumerId, d.ResourceId),
+			})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		cycle, err := dependsOnResource(res.TenantId, dependency, res.Id, map[string]bool{})
+		if err != nil {
+			return nil, err
+		}
+		if cycle {
+			invalid.Violations = append(invalid.Violations, db.FieldViolation{
+				Field:       field,
+				Description: fmt.Sprintf("resource %q depends on resource %q", d.ResourceId, res.Id),
+			})
+			continue
+		}
+
+		converted = append(converted, d)
+	}
+	if len(invalid.Violations) > 0 {
+		return nil, invalid
+	}
+
+	return converted, nil
+}
+
+// dependsOnResource tells whether res depends on the resource id, directly
+// or through its dependencies.
+func dependsOnResource(tenantID string, res *db.Resource, id string, visited map[string]bool) (bool, error) {
+	visited[res.Id] = true
+	for _, d := range res.DependsOn {
+		if d.ResourceId == id {
+			return true, nil
+		}
+		if visited[d.ResourceId] {
+			continue
+		}
+
+		dependency, err := db.GetResource(tenantID, d.ResourceId)
+		var notFound *db.ErrorNotFound
+		if errors.As(err, &notFound) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+
+		found, err := dependsOnResource(tenantID, dependency, id, visited)
+		if found || err != nil {
+			return found, err
+		}
+	}
+	return false, nil
+}
+
+func toDependenciesResponse(dependsOn []db.Dependency) map[string]string {
+	if len(dependsOn) == 0 {
+		return nil
+	}
+	converted := make(map[string]string, len(dependsOn))
+	for _, d := range dependsOn {
+		converted[d.ResourceId] = d.Condition
+	}
+	return converted
+}
+
+// ResourceStatusChanged publishes the held resources depending on a
+// resource, after its agent reported the status of the resource.
+func (svc *ResourcesService) ResourceStatusChanged(res *db.Resource) {
+	tenantID, resourceID := res.TenantId, res.Id
+	// status reports aren't held up by publishing
+	go func() {
+		dependents, err := db.ListResourceDependents(resourceID)
+		if err != nil {
+			log.Printf("Failed to list the dependents of resource %q: %v", resourceID, err)
+			return
+		}
+
+		for _, id := range dependents {
+			if _, err := svc.release(tenantID, id); err != nil {
+				log.Printf("Failed to release resource %q: %v", id, err)
+			}
+		}
+	}()
+}
+
+// release publishes the held generation of a resource once its
+// dependencies are ready, and tells whether it did.
+func (svc *ResourcesService) release(tenantID, resourceID string) (bool, error) {
+	res, err := db.GetResource(tenantID, resourceID)
+	var notFound *db.ErrorNotFound
+	if errors.As(err, &notFound) {
+		return false, nil
+	}
+	if err != nil || !res.Held {
+		return false, err
+	}
+
+	ready, err := db.DependenciesReady(res)
+	if err != nil || !ready {
+		return false, err
+	}
+
+	consumer, err := db.GetConsumer(res.TenantId, res.ConsumerId)
+	if err != nil {
+		return false, err
+	}
+	content, contentEncryption, err := svc.content(consumer, res)
+	if err != nil {
+		return false, err
+	}
+
+	released, err := db.ReleaseResource(res)
+	if err != nil || !released {
+		return false, err
+	}
+
+	svc.resourceChan <- db.ResourceMessage{
+		Id:          res.Id,
+		TenantId:    res.TenantId,
+		ConsumerId:  res.ConsumerId,
+		Compression: consumer.Compression,
+		MessageMeta: db.MessageMeta{
+			ResourceGenerationID: res.ResourceGenerationID,
+			ContentHash:          res.ContentHash,
+		},
+		Content:       content,
+		FeedbackRules: manifest.FeedbackPaths(res.Object.GroupVersionKind().GroupKind(), res.FeedbackRules),
+		Encryption:    contentEncryption,
+	}
+	return true, nil
+}
+
+// checkNoDependents returns ErrorFailedPrecondition when resources, not
+// being deleted, depend on res.
+func checkNoDependents(res *db.Resource) error {
+	ids, err := db.ListResourceDependents(res.Id)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		dependent, err := db.GetResource(res.TenantId, id)
+		var notFound *db.ErrorNotFound
+		if errors.As(err, &notFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if dependent.Deleting {
+			continue
+		}
+		for _, d := range dependent.DependsOn {
+			if d.ResourceId == res.Id {
+				return &db.ErrorFailedPrecondition{
+					Type:        "DEPENDENTS",
+					Subject:     res.Id,
+					Description: fmt.Sprintf("resource %q depends on resource %q", dependent.Id, res.Id),
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package resources
+
+import (
+	"github.com/kube-orchestra/maestro/internal/db"
+	"github.com/kube-orchestra/maestro/internal/manifest"
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// encrypt encrypts the sensitive values of the object and template of res
+// in place, with a new data key.
+func (svc *ResourcesService) encrypt(res *db.Resource) error {
+	objects := []*unstructured.Unstructured{&res.Object}
+	if res.Template != nil {
+		objects = append(objects, &res.Template.Object)
+	}
+
+	var err error
+	res.Encryption, err = svc.encryption.Encrypt(res.Id, objects...)
+	return err
+}
+
+// decrypt returns copies of the object and template of res with their
+// encrypted values decrypted.
+func (svc *ResourcesService) decrypt(res *db.Resource) (*unstructured.Unstructured, *db.ResourceTemplate, error) {
+	object := res.Object.DeepCopy()
+	objects := []*unstructured.Unstructured{object}
+
+	var tmpl *db.ResourceTemplate
+	if res.Template != nil {
+		tmpl = &db.ResourceTemplate{Object: *res.Template.Object.DeepCopy(), Values: res.Template.Values}
+		objects = append(objects, &tmpl.Object)
+	}
+
+	err := svc.encryption.Decrypt(res.Encryption, res.Id, objects...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return object, tmpl, nil
+}
+
+// contentHash returns the content hash of object, keyed so it doesn't
+// reveal the encrypted values.
+func (svc *ResourcesService) contentHash(object *unstructured.Unstructured) (string, error) {
+	hash, err := manifest.ContentHash(object)
+	if err != nil {
+		return "", err
+	}
+	return svc.encryption.ContentHash(hash), nil
+}
+
+// content returns the object of res as published to consumer: its values
+// stay encrypted when the consumer has a public key.
+func (svc *ResourcesService) content(consumer *v1.Consumer, res *db.Resource) (*unstructured.Unstructured, *db.ContentEncryption, error) {
+	objects, contentEncryption, err := svc.encryption.Seal(res.Encryption, res.Id, consumer.PublicKey, &res.Object)
+	if err != nil {
+		return nil, nil, err
+	}
+	return objects[0], contentEncryption, nil
+}
+
+// placementObject returns a copy of the object of p with its encrypted
+// values decrypted.
+func (svc *ResourcesService) placementObject(p *db.Placement) (*unstructured.Unstructured, error) {
+	object := p.Object.DeepCopy()
+	err := svc.encryption.Decrypt(p.Encryption, p.Id, object)
+	if err != nil {
+		return nil, err
+	}
+	return object, nil
+}
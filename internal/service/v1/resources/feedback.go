@@ -0,0 +1,125 @@
+package resources
+
+import (
+	"fmt"
+
+	"github.com/kube-orchestra/maestro/internal/db"
+	"github.com/kube-orchestra/maestro/internal/manifest"
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// toFeedbackRules converts and validates the feedback rules of a request
+// for object.
+func toFeedbackRules(rules []*v1.FeedbackRule, object *unstructured.Unstructured) ([]db.FeedbackRule, error) {
+	invalid := &db.ErrorInvalidArgument{}
+	var converted []db.FeedbackRule
+	for i, rule := range rules {
+		field := fmt.Sprintf("feedbackRules[%d]", i)
+		switch rule.Type {
+		case v1.FeedbackRuleType_WELL_KNOWN_STATUS:
+			gk := object.GroupVersionKind().GroupKind()
+			if _, ok := manifest.WellKnownStatusPaths(gk); !ok {
+				invalid.Violations = append(invalid.Violations, db.FieldViolation{
+					Field:       field + ".type",
+					Description: fmt.Sprintf("%s has no well known status, use JSON_PATHS instead", gk),
+				})
+				continue
+			}
+			converted = append(converted, db.FeedbackRule{Type: db.FeedbackRuleWellKnownStatus})
+		case v1.FeedbackRuleType_JSON_PATHS:
+			if len(rule.JsonPaths) == 0 {
+				invalid.Violations = append(invalid.Violations, db.FieldViolation{
+					Field:       field + ".jsonPaths",
+					Description: "at least one JSONPath is required",
+				})
+				continue
+			}
+			paths := make([]db.JsonPath, len(rule.JsonPaths))
+			for j, p := range rule.JsonPaths {
+				pathField := fmt.Sprintf("%s.jsonPaths[%d]", field, j)
+				if p.Name == "" {
+					invalid.Violations = append(invalid.Violations, db.FieldViolation{
+						Field:       pathField + ".name",
+						Description: "a name is required",
+					})
+				}
+				if _, err := manifest.ParseFeedbackPath(p.Path); p.Path == "" || err != nil {
+					description := "a JSONPath is required"
+					if err != nil {
+						description = err.Error()
+					}
+					invalid.Violations = append(invalid.Violations, db.FieldViolation{
+						Field:       pathField + ".path",
+						Description: description,
+					})
+				}
+				paths[j] = db.JsonPath{Name: p.Name, Path: p.Path}
+			}
+			converted = append(converted, db.FeedbackRule{Type: db.FeedbackRuleJSONPaths, JsonPaths: paths})
+		default:
+			invalid.Violations = append(invalid.Violations, db.FieldViolation{
+				Field:       field + ".type",
+				Description: fmt.Sprintf("unknown feedback rule type %s", rule.Type),
+			})
+		}
+	}
+	if len(invalid.Violations) > 0 {
+		return nil, invalid
+	}
+
+	return converted, nil
+}
+
+func toFeedbackRulesResponse(rules []db.FeedbackRule) []*v1.FeedbackRule {
+	var converted []*v1.FeedbackRule
+	for _, rule := range rules {
+		switch rule.Type {
+		case db.FeedbackRuleWellKnownStatus:
+			converted = append(converted, &v1.FeedbackRule{Type: v1.FeedbackRuleType_WELL_KNOWN_STATUS})
+		case db.FeedbackRuleJSONPaths:
+			paths := make([]*v1.JsonPath, len(rule.JsonPaths))
+			for i, p := range rule.JsonPaths {
+				paths[i] = &v1.JsonPath{Name: p.Name, Path: p.Path}
+			}
+			converted = append(converted, &v1.FeedbackRule{Type: v1.FeedbackRuleType_JSON_PATHS, JsonPaths: paths})
+		}
+	}
+	return converted
+}
+
+func toStatusFeedbackResponse(values []db.FeedbackValue) []*v1.FeedbackValue {
+	var converted []*v1.FeedbackValue
+	for _, v := range values {
+		value := &v1.FeedbackValue{Name: v.Name}
+		switch {
+		case v.Value.Integer != nil:
+			value.Value = &v1.FeedbackValue_Integer{Integer: *v.Value.Integer}
+		case v.Value.String != nil:
+			value.Value = &v1.FeedbackValue_String_{String_: *v.Value.String}
+		case v.Value.Boolean != nil:
+			value.Value = &v1.FeedbackValue_Boolean{Boolean: *v.Value.Boolean}
+		case v.Value.JsonRaw != nil:
+			value.Value = &v1.FeedbackValue_JsonRaw{JsonRaw: *v.Value.JsonRaw}
+		}
+		converted = append(converted, value)
+	}
+	return converted
+}
+
+func equalFeedbackRules(a, b []db.FeedbackRule) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Type != b[i].Type || len(a[i].JsonPaths) != len(b[i].JsonPaths) {
+			return false
+		}
+		for j := range a[i].JsonPaths {
+			if a[i].JsonPaths[j] != b[i].JsonPaths[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
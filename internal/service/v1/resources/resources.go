@@ -3,9 +3,17 @@ package resources
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/kube-orchestra/maestro/internal/admission"
+	"github.com/kube-orchestra/maestro/internal/audit"
 	"github.com/kube-orchestra/maestro/internal/db"
+	"github.com/kube-orchestra/maestro/internal/encryption"
+	"github.com/kube-orchestra/maestro/internal/limits"
+	"github.com/kube-orchestra/maestro/internal/manifest"
+	"github.com/kube-orchestra/maestro/internal/policy"
 	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
 	"google.golang.org/protobuf/types/known/structpb"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -20,18 +28,45 @@ func prettyPrint(i interface{}) string {
 type ResourcesService struct {
 	v1.UnimplementedResourceServiceServer
 	resourceChan chan<- db.ResourceMessage
+	validator    *manifest.Validator
+	limits       *limits.Limits
+	admission    *admission.Chain
+	policies     *policy.Engine
+	encryption   *encryption.Encryptor
 }
 
-func NewResourceService(resourceChan chan<- db.ResourceMessage) *ResourcesService {
-	return &ResourcesService{resourceChan: resourceChan}
+func NewResourceService(resourceChan chan<- db.ResourceMessage, validator *manifest.Validator, limits *limits.Limits, admission *admission.Chain, policies *policy.Engine, encryption *encryption.Encryptor) *ResourcesService {
+	return &ResourcesService{resourceChan: resourceChan, validator: validator, limits: limits, admission: admission, policies: policies, encryption: encryption}
 }
 
 func (svc *ResourcesService) Read(_ context.Context, r *v1.ResourceReadRequest) (*v1.Resource, error) {
-	res, err := db.GetResource(r.Id)
+	res, err := db.GetResource(db.TenantOrDefault(r.TenantId), r.Id)
 	if err != nil {
 		return nil, err
 	}
 
+	return toResourceResponse(res)
+}
+
+// Lookup returns the resource managing the object identified by the
+// tenant, consumer, group, kind, namespace and name of the request.
+func (svc *ResourcesService) Lookup(_ context.Context, r *v1.ResourceLookupRequest) (*v1.Resource, error) {
+	res, err := db.GetResourceByTargetKey(db.TargetKey{
+		TenantId:   db.TenantOrDefault(r.TenantId),
+		ConsumerId: r.ConsumerId,
+		Group:      r.Group,
+		Kind:       r.Kind,
+		Namespace:  r.Namespace,
+		Name:       r.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toResourceResponse(res)
+}
+
+func toResourceResponse(res *db.Resource) (*v1.Resource, error) {
 	// object to proto struct
 	objProtoStruct, err := structpb.NewStruct(res.Object.UnstructuredContent())
 	if err != nil {
@@ -51,87 +86,717 @@ func (svc *ResourcesService) Read(_ context.Context, r *v1.ResourceReadRequest)
 	}
 
 	resResponse := &v1.Resource{
-		Id:           res.Id,
-		ConsumerId:   res.ConsumerId,
-		GenerationId: res.ResourceGenerationID,
-		Object:       objProtoStruct,
-		Status:       statusProtoStruct,
+		Id:             res.Id,
+		TenantId:       res.TenantId,
+		ConsumerId:     res.ConsumerId,
+		GenerationId:   res.ResourceGenerationID,
+		Object:         objProtoStruct,
+		Status:         statusProtoStruct,
+		ContentHash:    res.ContentHash,
+		FeedbackRules:  toFeedbackRulesResponse(res.FeedbackRules),
+		StatusFeedback: toStatusFeedbackResponse(res.Status.StatusFeedback),
+		PlacementId:    res.PlacementId,
+		Deleting:       res.Deleting,
+		DependsOn:      toDependenciesResponse(res.DependsOn),
+		Held:           res.Held,
+
+		ObservedGenerationId: res.Status.ResourceGenerationID,
+		InSync:               res.Status.ResourceGenerationID == res.ResourceGenerationID,
+	}
+	if err := setTemplateResponse(resResponse, res); err != nil {
+		return nil, err
 	}
 
 	return resResponse, nil
 }
 
-func (svc *ResourcesService) Create(_ context.Context, r *v1.ResourceCreateRequest) (*v1.Resource, error) {
-	unstructuredObject := unstructured.Unstructured{Object: r.Object.AsMap()}
+func (svc *ResourcesService) Create(ctx context.Context, r *v1.ResourceCreateRequest) (*v1.Resource, error) {
+	if r.Object == nil {
+		return nil, missingObjectError()
+	}
 
-	// set uid
-	uid := uuid.NewString()
-	unstructuredObject.SetUID(types.UID(uid))
+	tenantID := db.TenantOrDefault(r.TenantId)
+	consumer, err := db.RequireConsumer(tenantID, r.ConsumerId)
+	if err != nil {
+		return nil, err
+	}
+
+	object := &unstructured.Unstructured{Object: r.Object.AsMap()}
+	tmpl := toTemplate(r.Template, object, r.TemplateValues)
+	object, err = render(consumer, object, tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &db.Resource{
+		Id:         uuid.NewString(),
+		TenantId:   tenantID,
+		ConsumerId: r.ConsumerId,
+		Object:     *object,
+		Template:   tmpl,
+	}
+	audit.SetTarget(ctx, db.ResourceKind, res.Id)
+
+	res.DependsOn, err = toDependencies(res, r.DependsOn)
+	if err != nil {
+		return nil, err
+	}
+
+	err = svc.create(ctx, consumer, res, r.FeedbackRules)
+	if err != nil {
+		return nil, err
+	}
+
+	// mutating webhooks may have changed the object of the request
+	objProtoStruct, err := structpb.NewStruct(res.Object.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &v1.Resource{Id: res.Id,
+		TenantId:      res.TenantId,
+		ConsumerId:    res.ConsumerId,
+		GenerationId:  res.ResourceGenerationID,
+		Object:        objProtoStruct,
+		ContentHash:   res.ContentHash,
+		FeedbackRules: toFeedbackRulesResponse(res.FeedbackRules),
+		DependsOn:     toDependenciesResponse(res.DependsOn),
+		Held:          res.Held}
+	if err := setTemplateResponse(response, res); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
 
-	res := db.Resource{
-		Id:                   uid,
-		ConsumerId:           r.ConsumerId,
-		Object:               unstructuredObject,
-		ResourceGenerationID: 1,
+// CreatePlaced creates the resource of a placement for a consumer.
+func (svc *ResourcesService) CreatePlaced(ctx context.Context, consumer *v1.Consumer, p *db.Placement) (*db.Resource, error) {
+	placed, err := svc.placementObject(p)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := placementTemplate(p, placed, consumer.Id)
+	object, err := render(consumer, placed, tmpl)
+	if err != nil {
+		return nil, err
 	}
 
-	// TODO: check that it doesn't exist
-	err := db.PutResource(&res)
+	res := &db.Resource{
+		Id:                  uuid.NewString(),
+		TenantId:            p.TenantId,
+		ConsumerId:          consumer.Id,
+		Object:              *object,
+		PlacementId:         p.Id,
+		PlacementGeneration: p.ResourceGenerationID,
+		Template:            tmpl,
+	}
+	err = svc.create(ctx, consumer, res, nil)
 	if err != nil {
 		return nil, err
 	}
+	return res, nil
+}
+
+// create validates and admits the object of res, stores res as its first
+// generation and publishes it to the consumer.
+func (svc *ResourcesService) create(ctx context.Context, consumer *v1.Consumer, res *db.Resource, rules []*v1.FeedbackRule) error {
+	err := svc.validator.Validate("object", &res.Object)
+	if err != nil {
+		return err
+	}
+
+	// set uid
+	res.Object.SetUID(types.UID(res.Id))
+
+	err = svc.admit(ctx, admission.OperationCreate, res.Id, consumer, &res.Object, nil)
+	if err != nil {
+		return err
+	}
+
+	limit := svc.limits.For(consumer)
+	err = limit.Admit("object", &res.Object)
+	if err != nil {
+		return err
+	}
+
+	res.FeedbackRules, err = toFeedbackRules(rules, &res.Object)
+	if err != nil {
+		return err
+	}
+
+	res.ContentHash, err = svc.contentHash(&res.Object)
+	if err != nil {
+		return err
+	}
+
+	ready, err := db.DependenciesReady(res)
+	if err != nil {
+		return err
+	}
+	res.Held = !ready
+
+	changes := audit.Diff(map[string]interface{}{}, res.Object.Object)
+	err = svc.encrypt(res)
+	if err != nil {
+		return err
+	}
+
+	err = db.CreateResource(res, limit.MaxResources)
+	if err != nil {
+		return err
+	}
+	audit.SetChange(ctx, 0, res.ResourceGenerationID, changes)
+
+	err = db.PutResourceDependents(res)
+	if err != nil {
+		return err
+	}
+	if res.Held {
+		// the dependencies may have become ready in the meantime
+		released, err := svc.release(res.TenantId, res.Id)
+		res.Held = !released
+		return err
+	}
+
+	content, contentEncryption, err := svc.content(consumer, res)
+	if err != nil {
+		return err
+	}
 
 	messageMeta := db.MessageMeta{
-		SentTimestamp:        0,
 		ResourceGenerationID: res.ResourceGenerationID,
+		ContentHash:          res.ContentHash,
 	}
 	resourceMessage := db.ResourceMessage{
-		Id:          res.Id,
-		ConsumerId:  res.ConsumerId,
-		MessageMeta: messageMeta,
-		Content:     &unstructuredObject,
+		Id:            res.Id,
+		TenantId:      res.TenantId,
+		ConsumerId:    res.ConsumerId,
+		Compression:   consumer.Compression,
+		MessageMeta:   messageMeta,
+		Content:       content,
+		FeedbackRules: manifest.FeedbackPaths(res.Object.GroupVersionKind().GroupKind(), res.FeedbackRules),
+		Encryption:    contentEncryption,
 	}
 	svc.resourceChan <- resourceMessage
 
-	return &v1.Resource{Id: res.Id,
-		ConsumerId:   res.ConsumerId,
-		GenerationId: res.ResourceGenerationID,
-		Object:       r.Object}, nil
+	return nil
 }
 
-func (svc *ResourcesService) Update(_ context.Context, r *v1.ResourceUpdateRequest) (*v1.Resource, error) {
-	// TODO: rewrite using UpdateItem dynamodb
+func (svc *ResourcesService) Update(ctx context.Context, r *v1.ResourceUpdateRequest) (*v1.Resource, error) {
+	if r.Object == nil {
+		return nil, missingObjectError()
+	}
+
+	// check that it exists
+	res, err := db.GetResource(db.TenantOrDefault(r.TenantId), r.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	consumer, err := db.GetConsumer(res.TenantId, res.ConsumerId)
+	if err != nil {
+		return nil, err
+	}
+	object := &unstructured.Unstructured{Object: r.Object.AsMap()}
+	tmpl := toTemplate(r.Template, object, r.TemplateValues)
+	object, err = render(consumer, object, tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	err = svc.validator.Validate("object", object)
+	if err != nil {
+		return nil, err
+	}
+
+	// without rules in the request, the existing rules are kept
+	feedbackRules := res.FeedbackRules
+	if len(r.FeedbackRules) > 0 {
+		feedbackRules, err = toFeedbackRules(r.FeedbackRules, object)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return svc.update(ctx, res, *object, feedbackRules, tmpl)
+}
 
+// History returns the status transitions of a resource, oldest first.
+func (svc *ResourcesService) History(_ context.Context, r *v1.ResourceHistoryRequest) (*v1.ResourceHistory, error) {
 	// check that it exists
-	res, err := db.GetResource(r.Id)
+	_, err := db.GetResource(db.TenantOrDefault(r.TenantId), r.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := db.GetStatusHistory(r.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &v1.ResourceHistory{Id: r.Id}
+	for _, e := range entries {
+		entry := &v1.StatusHistoryEntry{
+			Timestamp:          time.Unix(0, e.Timestamp).UTC().Format(time.RFC3339Nano),
+			GenerationId:       e.ResourceGenerationID,
+			ObservedGeneration: e.ObservedGeneration,
+		}
+		for _, t := range e.Transitions {
+			entry.Transitions = append(entry.Transitions, &v1.ConditionTransition{
+				Type:               t.Type,
+				PreviousStatus:     t.PreviousStatus,
+				Status:             t.Status,
+				Reason:             t.Reason,
+				Message:            t.Message,
+				LastTransitionTime: t.LastTransitionTime,
+			})
+		}
+		history.Entries = append(history.Entries, entry)
+	}
+
+	return history, nil
+}
+
+// Usage returns the resource count of a consumer and the limits applying
+// to it.
+func (svc *ResourcesService) Usage(_ context.Context, r *v1.ResourceUsageRequest) (*v1.ResourceUsage, error) {
+	tenantID := db.TenantOrDefault(r.TenantId)
+	consumer, err := db.GetConsumer(tenantID, r.ConsumerId)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := db.GetConsumerResourceCount(tenantID, r.ConsumerId)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := svc.limits.For(consumer)
+	return &v1.ResourceUsage{
+		ConsumerId:        r.ConsumerId,
+		TenantId:          tenantID,
+		ResourceCount:     count,
+		MaxResources:      limit.MaxResources,
+		MaxManifestBytes:  limit.MaxManifestBytes,
+		AllowedKinds:      limit.AllowedKinds,
+		AllowedNamespaces: limit.AllowedNamespaces,
+	}, nil
+}
+
+// SetDependencies replaces the dependencies of a resource, publishing its
+// held generation when the new dependencies are ready.
+func (svc *ResourcesService) SetDependencies(ctx context.Context, r *v1.ResourceDependenciesRequest) (*v1.Resource, error) {
+	res, err := db.GetResource(db.TenantOrDefault(r.TenantId), r.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	dependsOn, err := toDependencies(res, r.DependsOn)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := res.DependsOn
+	res.DependsOn = dependsOn
+	err = db.SetResourceDependencies(res, previous)
+	if err != nil {
+		return nil, err
+	}
+	audit.SetChange(ctx, res.ResourceGenerationID, res.ResourceGenerationID, []string{"dependsOn"})
+
+	if res.Held {
+		released, err := svc.release(res.TenantId, res.Id)
+		if err != nil {
+			return nil, err
+		}
+		res.Held = !released
+	}
+	return toResourceResponse(res)
+}
+
+// SetFeedbackRules replaces the feedback rules of a resource. An empty
+// list makes the agent report the whole status again.
+func (svc *ResourcesService) SetFeedbackRules(ctx context.Context, r *v1.ResourceFeedbackRulesRequest) (*v1.Resource, error) {
+	res, err := db.GetResource(db.TenantOrDefault(r.TenantId), r.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	object, tmpl, err := svc.decrypt(res)
+	if err != nil {
+		return nil, err
+	}
+
+	feedbackRules, err := toFeedbackRules(r.FeedbackRules, object)
+	if err != nil {
+		return nil, err
+	}
+
+	return svc.update(ctx, res, *object, feedbackRules, tmpl)
+}
+
+// Patch applies a merge, JSON or strategic merge patch to the object of a
+// resource, or to its template for objects rendered from a template.
+func (svc *ResourcesService) Patch(ctx context.Context, r *v1.ResourcePatchRequest) (*v1.Resource, error) {
+	if r.Patch == nil {
+		return nil, &db.ErrorInvalidArgument{Violations: []db.FieldViolation{
+			{Field: "patch", Description: "a patch document is required"},
+		}}
+	}
+
+	res, err := db.GetResource(db.TenantOrDefault(r.TenantId), r.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, storedTmpl, err := svc.decrypt(res)
+	if err != nil {
+		return nil, err
+	}
+	target := stored
+	if storedTmpl != nil {
+		target = &storedTmpl.Object
+	}
+	original, err := json.Marshal(target.Object)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := r.Patch.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var patched []byte
+	switch r.PatchType {
+	case v1.PatchType_PATCH_TYPE_UNSPECIFIED, v1.PatchType_MERGE_PATCH:
+		patched, err = manifest.MergePatch(original, patch)
+	case v1.PatchType_JSON_PATCH:
+		patched, err = manifest.JSONPatch(original, patch)
+	case v1.PatchType_STRATEGIC_MERGE_PATCH:
+		patched, err = manifest.StrategicMergePatch(res.Object.GroupVersionKind(), original, patch)
+	default:
+		err = fmt.Errorf("unknown patch type %s", r.PatchType)
+	}
+	if err != nil {
+		return nil, &db.ErrorInvalidArgument{Violations: []db.FieldViolation{
+			{Field: "patch", Description: err.Error()},
+		}}
+	}
+
+	var patchedObject map[string]interface{}
+	if err := json.Unmarshal(patched, &patchedObject); err != nil {
+		return nil, err
+	}
+	object := &unstructured.Unstructured{Object: patchedObject}
+
+	var tmpl *db.ResourceTemplate
+	if res.Template != nil {
+		consumer, err := db.GetConsumer(res.TenantId, res.ConsumerId)
+		if err != nil {
+			return nil, err
+		}
+		tmpl = &db.ResourceTemplate{Object: *object, Values: res.Template.Values}
+		object, err = render(consumer, object, tmpl)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = svc.validator.Validate("object", object)
+	if err != nil {
+		return nil, err
+	}
+
+	return svc.update(ctx, res, *object, res.FeedbackRules, tmpl)
+}
+
+// update replaces the object and feedback rules of res, stores it with the
+// next generation and publishes it to the consumer, or holds it until its
+// dependencies are ready. When neither the content hash nor the rules
+// changed nothing is stored or published, res is returned as it is. This is
+// checked before the admission webhooks are called, and again after they
+// mutated the object. Otherwise the object must be within the limits of the
+// consumer.
+func (svc *ResourcesService) update(ctx context.Context, res *db.Resource, object unstructured.Unstructured, feedbackRules []db.FeedbackRule, tmpl *db.ResourceTemplate) (*v1.Resource, error) {
+	if res.Deleting {
+		return nil, &db.ErrorFailedPrecondition{
+			Type:        "DELETING",
+			Subject:     res.Id,
+			Description: fmt.Sprintf("resource %q is being deleted", res.Id),
+		}
+	}
+
+	object.SetUID(types.UID(res.Id))
+
+	consumer, err := db.GetConsumer(res.TenantId, res.ConsumerId)
+	if err != nil {
+		return nil, err
+	}
+	stored, storedTmpl, err := svc.decrypt(res)
+	if err != nil {
+		return nil, err
+	}
+
+	// resources stored before content hashes were tracked have none yet
+	if res.ContentHash == "" {
+		res.ContentHash, err = svc.contentHash(stored)
+		if err != nil {
+			return nil, err
+		}
+	}
+	unchanged := equalFeedbackRules(res.FeedbackRules, feedbackRules) && equalTemplates(storedTmpl, tmpl)
+
+	contentHash, err := svc.contentHash(&object)
+	if err != nil {
+		return nil, err
+	}
+	if unchanged && res.ContentHash == contentHash {
+		audit.SetChange(ctx, res.ResourceGenerationID, res.ResourceGenerationID, nil)
+		return toResourceResponse(res)
+	}
+
+	err = svc.admit(ctx, admission.OperationUpdate, res.Id, consumer, &object, stored)
+	if err != nil {
+		return nil, err
+	}
+
+	// the webhooks may have mutated the object back to the stored one
+	contentHash, err = svc.contentHash(&object)
+	if err != nil {
+		return nil, err
+	}
+	if unchanged && res.ContentHash == contentHash {
+		audit.SetChange(ctx, res.ResourceGenerationID, res.ResourceGenerationID, nil)
+		return toResourceResponse(res)
+	}
+
+	err = svc.limits.For(consumer).Admit("object", &object)
 	if err != nil {
 		return nil, err
 	}
 
-	res.Object = unstructured.Unstructured{Object: r.Object.AsMap()}
-	res.Object.SetUID(types.UID(r.Id))
-	res.ResourceGenerationID++
+	changes := audit.Diff(stored.Object, object.Object)
+	if !equalFeedbackRules(res.FeedbackRules, feedbackRules) {
+		changes = append(changes, "feedbackRules")
+	}
+	if !equalTemplates(storedTmpl, tmpl) {
+		changes = append(changes, "template")
+	}
+	previousKey := db.TargetKeyOf(res)
+	res.Object = object
+	res.ContentHash = contentHash
+	res.FeedbackRules = feedbackRules
+	res.Template = tmpl
 
-	err = db.PutResource(res)
+	ready, err := db.DependenciesReady(res)
+	if err != nil {
+		return nil, err
+	}
+	res.Held = !ready
+
+	err = svc.encrypt(res)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.UpdateResource(res, previousKey)
+	if err != nil {
+		return nil, err
+	}
+	audit.SetChange(ctx, res.ResourceGenerationID-1, res.ResourceGenerationID, changes)
+
+	if res.Held {
+		// the dependencies may have become ready in the meantime
+		released, err := svc.release(res.TenantId, res.Id)
+		if err != nil {
+			return nil, err
+		}
+		res.Held = !released
+		return toResourceResponse(res)
+	}
+
+	content, contentEncryption, err := svc.content(consumer, res)
 	if err != nil {
 		return nil, err
 	}
 
 	messageMeta := db.MessageMeta{
-		SentTimestamp:        0,
-		ResourceGenerationID: res.ResourceGenerationID + 1,
+		ResourceGenerationID: res.ResourceGenerationID,
+		ContentHash:          res.ContentHash,
 	}
 
 	resourceMessage := db.ResourceMessage{
+		Id:            res.Id,
+		TenantId:      res.TenantId,
+		ConsumerId:    res.ConsumerId,
+		Compression:   consumer.Compression,
+		MessageMeta:   messageMeta,
+		Content:       content,
+		FeedbackRules: manifest.FeedbackPaths(res.Object.GroupVersionKind().GroupKind(), res.FeedbackRules),
+		Encryption:    contentEncryption,
+	}
+	svc.resourceChan <- resourceMessage
+
+	return toResourceResponse(res)
+}
+
+// UpdatePlaced sets the object of the resource of a placement to the
+// object of the placement.
+func (svc *ResourcesService) UpdatePlaced(ctx context.Context, res *db.Resource, p *db.Placement) error {
+	consumer, err := db.GetConsumer(res.TenantId, res.ConsumerId)
+	if err != nil {
+		return err
+	}
+	placed, err := svc.placementObject(p)
+	if err != nil {
+		return err
+	}
+	tmpl := placementTemplate(p, placed, consumer.Id)
+	object, err := render(consumer, placed, tmpl)
+	if err != nil {
+		return err
+	}
+	err = svc.validator.Validate("object", object)
+	if err != nil {
+		return err
+	}
+
+	generation := res.ResourceGenerationID
+	res.PlacementGeneration = p.ResourceGenerationID
+	_, err = svc.update(ctx, res, *object, res.FeedbackRules, tmpl)
+	if err != nil {
+		return err
+	}
+
+	// the object didn't change, e.g. when a rollback restores it on a
+	// target the rollout didn't reach, so nothing was stored
+	if res.ResourceGenerationID == generation {
+		return db.SetResourcePlacementGeneration(res)
+	}
+	return nil
+}
+
+// Delete deletes a resource created directly: its agent is asked to remove
+// the object, the resource is removed once it reports it did, releasing
+// its place in the quotas. The resources of placements are deleted by their
+// placement, and resources other resources depend on can't be deleted.
+func (svc *ResourcesService) Delete(ctx context.Context, r *v1.ResourceDeleteRequest) (*v1.Resource, error) {
+	res, err := db.GetResource(db.TenantOrDefault(r.TenantId), r.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Deleting {
+		audit.SetChange(ctx, res.ResourceGenerationID, res.ResourceGenerationID, nil)
+		return toResourceResponse(res)
+	}
+	if res.PlacementId != "" {
+		return nil, &db.ErrorFailedPrecondition{
+			Type:        "PLACEMENT",
+			Subject:     res.Id,
+			Description: fmt.Sprintf("resource %q is managed by placement %q", res.Id, res.PlacementId),
+		}
+	}
+	if err := checkNoDependents(res); err != nil {
+		return nil, err
+	}
+
+	err = svc.delete(res)
+	if err != nil {
+		return nil, err
+	}
+	audit.SetChange(ctx, res.ResourceGenerationID-1, res.ResourceGenerationID, []string{"deleting"})
+	return toResourceResponse(res)
+}
+
+// DeletePlaced deletes the resource of a placement: its agent is asked to
+// remove the object, the resource is removed once it reports it did.
+func (svc *ResourcesService) DeletePlaced(res *db.Resource) error {
+	if res.Deleting {
+		return nil
+	}
+	return svc.delete(res)
+}
+
+// delete flags res as being deleted and asks its agent to remove its
+// object.
+func (svc *ResourcesService) delete(res *db.Resource) error {
+
+	consumer, err := db.GetConsumer(res.TenantId, res.ConsumerId)
+	if err != nil {
+		return err
+	}
+	content, contentEncryption, err := svc.content(consumer, res)
+	if err != nil {
+		return err
+	}
+
+	// deletions aren't held, the agent may have applied a previous
+	// generation
+	res.Deleting = true
+	res.Held = false
+	err = db.UpdateResource(res, db.TargetKeyOf(res))
+	if err != nil {
+		return err
+	}
+
+	svc.resourceChan <- db.ResourceMessage{
 		Id:          res.Id,
+		TenantId:    res.TenantId,
 		ConsumerId:  res.ConsumerId,
-		MessageMeta: messageMeta,
-		Content:     &res.Object,
+		Compression: consumer.Compression,
+		MessageMeta: db.MessageMeta{
+			ResourceGenerationID: res.ResourceGenerationID,
+			ContentHash:          res.ContentHash,
+		},
+		Content:    content,
+		Encryption: contentEncryption,
+		Delete:     true,
 	}
-	svc.resourceChan <- resourceMessage
+	return nil
+}
+
+// admit passes object through the mutating admission webhooks, validates
+// the mutated object again, evaluates the policies against it, then passes
+// it through the validating webhooks.
+// oldObject is the stored object of updates, nil on creation.
+func (svc *ResourcesService) admit(ctx context.Context, operation, id string, consumer *v1.Consumer, object, oldObject *unstructured.Unstructured) error {
+	consumerLabels := map[string]string{}
+	for _, label := range consumer.Labels {
+		consumerLabels[label.Key] = label.Value
+	}
+	req := admission.WithCaller(ctx, &admission.Request{
+		Operation:      operation,
+		ResourceId:     id,
+		TenantId:       db.TenantOrDefault(consumer.TenantId),
+		ConsumerId:     consumer.Id,
+		ConsumerLabels: consumerLabels,
+		Object:         object,
+		OldObject:      oldObject,
+	})
+
+	mutated, err := svc.admission.Mutate(ctx, req)
+	if err != nil {
+		return err
+	}
+	if mutated {
+		// webhooks can't take over another resource
+		object.SetUID(types.UID(id))
+		err = svc.validator.Validate("object", object)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = svc.policies.Evaluate(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return svc.admission.Validate(ctx, req)
+}
 
-	return &v1.Resource{Id: res.Id,
-		ConsumerId:   res.ConsumerId,
-		GenerationId: res.ResourceGenerationID,
-		Object:       r.Object}, nil
+func missingObjectError() error {
+	return &db.ErrorInvalidArgument{Violations: []db.FieldViolation{
+		{Field: "object", Description: "a Kubernetes manifest is required"},
+	}}
 }
@@ -0,0 +1,124 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kube-orchestra/maestro/internal/db"
+	"github.com/kube-orchestra/maestro/internal/manifest"
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
+	"google.golang.org/protobuf/types/known/structpb"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// toTemplate returns the template of a request for object, nil when object
+// isn't a template.
+func toTemplate(isTemplate bool, object *unstructured.Unstructured, values map[string]string) *db.ResourceTemplate {
+	if !isTemplate {
+		return nil
+	}
+	return &db.ResourceTemplate{Object: *object, Values: values}
+}
+
+// placementTemplate returns the template of the resource of a placement on
+// a consumer, from the decrypted object of the placement. It's nil when the
+// object isn't a template.
+func placementTemplate(p *db.Placement, object *unstructured.Unstructured, consumerID string) *db.ResourceTemplate {
+	if !p.Template {
+		return nil
+	}
+	return &db.ResourceTemplate{Object: *object.DeepCopy(), Values: p.ConsumerValues[consumerID]}
+}
+
+// render returns the object of a resource for consumer: object itself, or
+// the rendering of tmpl when set.
+func render(consumer *v1.Consumer, object *unstructured.Unstructured, tmpl *db.ResourceTemplate) (*unstructured.Unstructured, error) {
+	if tmpl == nil {
+		return object, nil
+	}
+
+	// the values of the template take precedence over the labels
+	values := map[string]string{}
+	for _, l := range consumer.Labels {
+		values[l.Key] = l.Value
+	}
+	for key, value := range tmpl.Values {
+		values[key] = value
+	}
+	return manifest.Render("object", &tmpl.Object, values)
+}
+
+// Rerender renders the templated resources of a consumer again after its
+// labels changed, and updates those whose object changed. Resources being
+// deleted are skipped. It returns the errors of the resources that
+// couldn't be rendered or updated, which keep their object.
+func (svc *ResourcesService) Rerender(ctx context.Context, consumer *v1.Consumer) error {
+	resources, err := db.ListTemplatedResources(db.TenantOrDefault(consumer.TenantId), consumer.Id)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, res := range resources {
+		if res.Deleting {
+			continue
+		}
+		if err := svc.rerender(ctx, consumer, res); err != nil {
+			errs = append(errs, fmt.Errorf("resource %q: %w", res.Id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (svc *ResourcesService) rerender(ctx context.Context, consumer *v1.Consumer, res *db.Resource) error {
+	_, tmpl, err := svc.decrypt(res)
+	if err != nil || tmpl == nil {
+		return err
+	}
+	object, err := render(consumer, &tmpl.Object, tmpl)
+	if err != nil {
+		return err
+	}
+	err = svc.validator.Validate("object", object)
+	if err != nil {
+		return err
+	}
+
+	// nothing is stored when the rendered object didn't change
+	_, err = svc.update(ctx, res, *object, res.FeedbackRules, tmpl)
+	return err
+}
+
+func equalTemplates(a, b *db.ResourceTemplate) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if len(a.Values) != len(b.Values) {
+		return false
+	}
+	for key, value := range a.Values {
+		if v, ok := b.Values[key]; !ok || v != value {
+			return false
+		}
+	}
+
+	hashA, errA := manifest.ContentHash(&a.Object)
+	hashB, errB := manifest.ContentHash(&b.Object)
+	return errA == nil && errB == nil && hashA == hashB
+}
+
+// setTemplateResponse adds the template of res, if any, to a response.
+func setTemplateResponse(response *v1.Resource, res *db.Resource) error {
+	if res.Template == nil {
+		return nil
+	}
+
+	tmpl, err := structpb.NewStruct(res.Template.Object.Object)
+	if err != nil {
+		return err
+	}
+	response.Template = tmpl
+	response.TemplateValues = res.Template.Values
+	return nil
+}
@@ -0,0 +1,35 @@
+package signingkeys
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/kube-orchestra/maestro/internal/signing"
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
+)
+
+type Service struct {
+	v1.UnimplementedSigningKeyServiceServer
+	signer *signing.Signer
+}
+
+func NewSigningKeyService(signer *signing.Signer) *Service {
+	return &Service{signer: signer}
+}
+
+// List returns the public keys of the signer as a JSON Web Key Set, empty
+// when messages aren't signed.
+func (svc *Service) List(_ context.Context, _ *v1.SigningKeyListRequest) (*v1.SigningKeyList, error) {
+	list := &v1.SigningKeyList{ActiveKid: svc.signer.ActiveKey()}
+	for _, k := range svc.signer.PublicKeys() {
+		list.Keys = append(list.Keys, &v1.SigningKey{
+			Kid: k.Id,
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(k.Key),
+			Alg: signing.Algorithm,
+			Use: "sig",
+		})
+	}
+	return list, nil
+}
@@ -0,0 +1,105 @@
+package tenants
+
+import (
+	"context"
+	"strings"
+
+	"github.com/kube-orchestra/maestro/internal/audit"
+	"github.com/kube-orchestra/maestro/internal/db"
+	v1 "github.com/kube-orchestra/maestro/proto/api/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+type Service struct {
+	v1.UnimplementedTenantServiceServer
+}
+
+func NewTenantService() *Service {
+	return &Service{}
+}
+
+func (svc *Service) Read(_ context.Context, r *v1.TenantReadRequest) (*v1.Tenant, error) {
+	t, err := db.GetTenant(r.Id)
+	if err != nil {
+		return nil, err
+	}
+	return toTenantResponse(t), nil
+}
+
+// Create stores a new tenant. Its id is part of the MQTT topics of its
+// consumers, it must be a DNS-1123 label.
+func (svc *Service) Create(ctx context.Context, r *v1.TenantCreateRequest) (*v1.Tenant, error) {
+	violations := quotaViolations(r.MaxConsumers, r.MaxResources)
+	if errs := validation.IsDNS1123Label(r.Id); len(errs) > 0 {
+		violations = append(violations, db.FieldViolation{Field: "id", Description: strings.Join(errs, ", ")})
+	}
+	if len(violations) > 0 {
+		return nil, &db.ErrorInvalidArgument{Violations: violations}
+	}
+	audit.SetTarget(ctx, db.TenantKind, r.Id)
+
+	t := &db.Tenant{
+		Id:           r.Id,
+		MaxConsumers: r.MaxConsumers,
+		MaxResources: r.MaxResources,
+	}
+	err := db.CreateTenant(t)
+	if err != nil {
+		return nil, err
+	}
+	audit.SetChange(ctx, 0, 0, changedQuotas(&db.Tenant{}, t))
+
+	return toTenantResponse(t), nil
+}
+
+// Update sets the quotas of a tenant, the default tenant included.
+func (svc *Service) Update(ctx context.Context, r *v1.TenantUpdateRequest) (*v1.Tenant, error) {
+	if violations := quotaViolations(r.MaxConsumers, r.MaxResources); len(violations) > 0 {
+		return nil, &db.ErrorInvalidArgument{Violations: violations}
+	}
+
+	previous, err := db.GetTenant(r.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := db.UpdateTenantQuotas(r.Id, r.MaxConsumers, r.MaxResources)
+	if err != nil {
+		return nil, err
+	}
+	audit.SetChange(ctx, 0, 0, changedQuotas(previous, t))
+
+	return toTenantResponse(t), nil
+}
+
+func quotaViolations(maxConsumers, maxResources int64) []db.FieldViolation {
+	var violations []db.FieldViolation
+	if maxConsumers < 0 {
+		violations = append(violations, db.FieldViolation{Field: "maxConsumers", Description: "must not be negative"})
+	}
+	if maxResources < 0 {
+		violations = append(violations, db.FieldViolation{Field: "maxResources", Description: "must not be negative"})
+	}
+	return violations
+}
+
+func changedQuotas(old, new *db.Tenant) []string {
+	var changes []string
+	if old.MaxConsumers != new.MaxConsumers {
+		changes = append(changes, "maxConsumers")
+	}
+	if old.MaxResources != new.MaxResources {
+		changes = append(changes, "maxResources")
+	}
+	return changes
+}
+
+func toTenantResponse(t *db.Tenant) *v1.Tenant {
+	return &v1.Tenant{
+		Id:            t.Id,
+		MaxConsumers:  t.MaxConsumers,
+		MaxResources:  t.MaxResources,
+		ConsumerCount: t.ConsumerCount,
+		ResourceCount: t.ResourceCount,
+	}
+}
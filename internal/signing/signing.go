@@ -0,0 +1,256 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const messageSigningKeysFile = "MESSAGE_SIGNING_KEYS_FILE"
+
+// Algorithm is the JWS algorithm of the signatures.
+const Algorithm = "EdDSA"
+
+type Config struct {
+	// Id of the key signing messages, the first key when empty.
+	ActiveKey string `json:"activeKey,omitempty"`
+	Keys      []Key  `json:"keys"`
+}
+
+// Key is an Ed25519 key pair. Keys with a public key only aren't used to
+// sign, they're published until the agents stop trusting them, e.g. after
+// a rotation.
+type Key struct {
+	Id string `json:"id"`
+	// PEM encoded PKCS #8 private key, e.g. from
+	// "openssl genpkey -algorithm ed25519".
+	PrivateKeyFile string `json:"privateKeyFile,omitempty"`
+	// PEM encoded PKIX public key.
+	PublicKeyFile string `json:"publicKeyFile,omitempty"`
+}
+
+// PublicKey is a published key.
+type PublicKey struct {
+	Id  string
+	Key ed25519.PublicKey
+}
+
+// Signer signs the messages published to the agents as JWS, see Sign.
+type Signer struct {
+	activeID  string
+	active    ed25519.PrivateKey
+	published []PublicKey
+}
+
+// New loads the keys of the file MESSAGE_SIGNING_KEYS_FILE. Without file,
+// messages aren't signed.
+func New() (*Signer, error) {
+	path := os.Getenv(messageSigningKeysFile)
+	if path == "" {
+		return &Signer{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("failed to read message signing keys %s: %w", path, err)
+	}
+	if len(config.Keys) == 0 {
+		return nil, fmt.Errorf("message signing keys %s: at least one key is required", path)
+	}
+
+	s := &Signer{activeID: config.ActiveKey}
+	if s.activeID == "" {
+		s.activeID = config.Keys[0].Id
+	}
+	ids := map[string]bool{}
+	for i, k := range config.Keys {
+		if k.Id == "" {
+			return nil, fmt.Errorf("message signing keys %s: keys[%d]: id is required", path, i)
+		}
+		if ids[k.Id] {
+			return nil, fmt.Errorf("message signing keys %s: keys[%d]: duplicate id %q", path, i, k.Id)
+		}
+		ids[k.Id] = true
+
+		public, private, err := loadKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("message signing keys %s: keys[%d]: %w", path, i, err)
+		}
+		if k.Id == s.activeID {
+			if private == nil {
+				return nil, fmt.Errorf("message signing keys %s: keys[%d]: the active key requires privateKeyFile", path, i)
+			}
+			s.active = private
+		}
+		s.published = append(s.published, PublicKey{Id: k.Id, Key: public})
+	}
+	if s.active == nil {
+		return nil, fmt.Errorf("message signing keys %s: unknown activeKey %q", path, s.activeID)
+	}
+	return s, nil
+}
+
+func loadKey(k Key) (ed25519.PublicKey, ed25519.PrivateKey, error) {
+	if k.PrivateKeyFile != "" {
+		data, err := os.ReadFile(k.PrivateKeyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, nil, fmt.Errorf("%s: a PEM encoded private key is required", k.PrivateKeyFile)
+		}
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", k.PrivateKeyFile, err)
+		}
+		private, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, nil, fmt.Errorf("%s: an Ed25519 private key is required", k.PrivateKeyFile)
+		}
+		return private.Public().(ed25519.PublicKey), private, nil
+	}
+
+	if k.PublicKeyFile == "" {
+		return nil, nil, errors.New("privateKeyFile or publicKeyFile is required")
+	}
+	data, err := os.ReadFile(k.PublicKeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	public, err := ParsePublicKey(string(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", k.PublicKeyFile, err)
+	}
+	return public, nil, nil
+}
+
+// ActiveKey returns the Id of the key signing messages, empty when
+// messages aren't signed.
+func (s *Signer) ActiveKey() string {
+	if s == nil || s.active == nil {
+		return ""
+	}
+	return s.activeID
+}
+
+// PublicKeys returns the published keys, in the order of the file.
+func (s *Signer) PublicKeys() []PublicKey {
+	if s == nil {
+		return nil
+	}
+	return s.published
+}
+
+// header is the JWS protected header of the signatures. The topic binds
+// the payload to the topic it's published to, so it can't be replayed to
+// another resource or consumer.
+type header struct {
+	Alg   string `json:"alg"`
+	Kid   string `json:"kid,omitempty"`
+	Topic string `json:"topic"`
+}
+
+// JWS is the flattened JSON serialization of a JSON Web Signature, with
+// base64url encoded fields.
+type JWS struct {
+	Protected string `json:"protected"`
+	Payload   string `json:"payload"`
+	Signature string `json:"signature"`
+}
+
+// Sign returns payload wrapped in a JWS signed with the active key, the
+// topic set in its protected header. Without active key, payload is
+// returned as it is.
+func (s *Signer) Sign(topic string, payload []byte) ([]byte, error) {
+	if s == nil || s.active == nil {
+		return payload, nil
+	}
+
+	protected, err := json.Marshal(header{Alg: Algorithm, Kid: s.activeID, Topic: topic})
+	if err != nil {
+		return nil, err
+	}
+
+	jws := JWS{
+		Protected: base64.RawURLEncoding.EncodeToString(protected),
+		Payload:   base64.RawURLEncoding.EncodeToString(payload),
+	}
+	signature := ed25519.Sign(s.active, []byte(jws.Protected+"."+jws.Payload))
+	jws.Signature = base64.RawURLEncoding.EncodeToString(signature)
+	return json.Marshal(jws)
+}
+
+// Verify checks data is a JWS of a payload published to topic, signed by
+// the PEM encoded Ed25519 publicKey, and returns the payload.
+func Verify(publicKey, topic string, data []byte) ([]byte, error) {
+	key, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	jws := JWS{}
+	if err := json.Unmarshal(data, &jws); err != nil || jws.Protected == "" || jws.Signature == "" {
+		return nil, errors.New("a JWS signed message is required")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(jws.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+	if !ed25519.Verify(key, []byte(jws.Protected+"."+jws.Payload), signature) {
+		return nil, errors.New("the signature doesn't match the key of the consumer")
+	}
+
+	protected, err := base64.RawURLEncoding.DecodeString(jws.Protected)
+	if err != nil {
+		return nil, fmt.Errorf("invalid protected header: %w", err)
+	}
+	h := header{}
+	if err := json.Unmarshal(protected, &h); err != nil {
+		return nil, fmt.Errorf("invalid protected header: %w", err)
+	}
+	if h.Alg != Algorithm {
+		return nil, fmt.Errorf("unsupported algorithm %q", h.Alg)
+	}
+	if h.Topic != topic {
+		return nil, fmt.Errorf("the message was signed for topic %q", h.Topic)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(jws.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	return payload, nil
+}
+
+// ParsePublicKey parses a PEM encoded PKIX Ed25519 public key.
+func ParsePublicKey(publicKey string) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil {
+		return nil, errors.New("a PEM encoded public key is required")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	public, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("an Ed25519 public key is required")
+	}
+	return public, nil
+}
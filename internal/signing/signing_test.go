@@ -0,0 +1,334 @@
+package signing
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const topic = "v1/default/cluster1/resource1/content"
+
+func TestSignVerify(t *testing.T) {
+	s, public := newTestSigner(t)
+	payload := []byte(`{"id":"resource1"}`)
+
+	signed, err := s.Sign(topic, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Verify(public, topic, signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(payload) {
+		t.Errorf("got payload %s, want %s", got, payload)
+	}
+
+	unsigned, err := (&Signer{}).Sign(topic, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(unsigned) != string(payload) {
+		t.Errorf("got %s without active key, want the payload as it is", unsigned)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	s, public := newTestSigner(t)
+	_, other := newTestSigner(t)
+	payload := []byte(`{"id":"resource1"}`)
+	signed, err := s.Sign(topic, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		publicKey string
+		topic     string
+		data      []byte
+		wantErr   string
+	}{
+		{
+			name:      "wrong topic",
+			publicKey: public,
+			topic:     "v1/default/cluster1/resource2/content",
+			data:      signed,
+			wantErr:   "signed for topic",
+		},
+		{
+			name:      "wrong key",
+			publicKey: other,
+			topic:     topic,
+			data:      signed,
+			wantErr:   "doesn't match the key",
+		},
+		{
+			name:      "unsigned message",
+			publicKey: public,
+			topic:     topic,
+			data:      payload,
+			wantErr:   "JWS signed message is required",
+		},
+		{
+			name:      "alg none without signature",
+			publicKey: public,
+			topic:     topic,
+			data:      newJWS(t, nil, header{Alg: "none", Topic: topic}, payload),
+			wantErr:   "JWS signed message is required",
+		},
+		{
+			name:      "alg none",
+			publicKey: public,
+			topic:     topic,
+			data:      newJWS(t, s.active, header{Alg: "none", Topic: topic}, payload),
+			wantErr:   `unsupported algorithm "none"`,
+		},
+		{
+			name:      "wrong alg",
+			publicKey: public,
+			topic:     topic,
+			data:      newJWS(t, s.active, header{Alg: "ES256", Topic: topic}, payload),
+			wantErr:   `unsupported algorithm "ES256"`,
+		},
+		{
+			name:      "tampered payload",
+			publicKey: public,
+			topic:     topic,
+			data:      tamper(t, signed),
+			wantErr:   "doesn't match the key",
+		},
+		{
+			name:      "invalid public key",
+			publicKey: "not a key",
+			topic:     topic,
+			data:      signed,
+			wantErr:   "PEM encoded public key is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Verify(tt.publicKey, tt.topic, tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+	private1, public1 := writeKey(t, dir, "key-1")
+	_, public2 := writeKey(t, dir, "key-2")
+	ecdsaKey := writeECDSAKey(t, dir)
+
+	tests := []struct {
+		name       string
+		config     string
+		wantActive string
+		wantKeys   []string
+		wantErr    string
+	}{
+		{
+			name:       "first key active",
+			config:     `{"keys": [{"id": "key-1", "privateKeyFile": "` + private1 + `"}, {"id": "key-2", "publicKeyFile": "` + public2 + `"}]}`,
+			wantActive: "key-1",
+			wantKeys:   []string{"key-1", "key-2"},
+		},
+		{
+			name:       "active key",
+			config:     "activeKey: key-1\nkeys:\n- id: key-2\n  publicKeyFile: " + public2 + "\n- id: key-1\n  privateKeyFile: " + private1 + "\n",
+			wantActive: "key-1",
+			wantKeys:   []string{"key-2", "key-1"},
+		},
+		{
+			name:    "no key",
+			config:  `{"keys": []}`,
+			wantErr: "at least one key is required",
+		},
+		{
+			name:    "missing id",
+			config:  `{"keys": [{"privateKeyFile": "` + private1 + `"}]}`,
+			wantErr: "id is required",
+		},
+		{
+			name:    "duplicate id",
+			config:  `{"keys": [{"id": "key-1", "privateKeyFile": "` + private1 + `"}, {"id": "key-1", "publicKeyFile": "` + public2 + `"}]}`,
+			wantErr: `duplicate id "key-1"`,
+		},
+		{
+			name:    "active key without private key",
+			config:  `{"keys": [{"id": "key-1", "publicKeyFile": "` + public1 + `"}]}`,
+			wantErr: "the active key requires privateKeyFile",
+		},
+		{
+			name:    "unknown active key",
+			config:  `{"activeKey": "key-3", "keys": [{"id": "key-1", "privateKeyFile": "` + private1 + `"}]}`,
+			wantErr: `unknown activeKey "key-3"`,
+		},
+		{
+			name:    "key without file",
+			config:  `{"keys": [{"id": "key-1"}]}`,
+			wantErr: "privateKeyFile or publicKeyFile is required",
+		},
+		{
+			name:    "not an Ed25519 key",
+			config:  `{"keys": [{"id": "key-1", "privateKeyFile": "` + ecdsaKey + `"}]}`,
+			wantErr: "an Ed25519 private key is required",
+		},
+		{
+			name:    "not a PEM file",
+			config:  `{"keys": [{"id": "key-1", "privateKeyFile": "` + filepath.Join(dir, "keys.json") + `"}]}`,
+			wantErr: "a PEM encoded private key is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "keys.json")
+			if err := os.WriteFile(path, []byte(tt.config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv(messageSigningKeysFile, path)
+
+			s, err := New()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.ActiveKey() != tt.wantActive {
+				t.Errorf("got active key %q, want %q", s.ActiveKey(), tt.wantActive)
+			}
+			var keys []string
+			for _, k := range s.PublicKeys() {
+				keys = append(keys, k.Id)
+			}
+			if strings.Join(keys, ",") != strings.Join(tt.wantKeys, ",") {
+				t.Errorf("got keys %v, want %v", keys, tt.wantKeys)
+			}
+		})
+	}
+
+	t.Run("without file", func(t *testing.T) {
+		t.Setenv(messageSigningKeysFile, "")
+		s, err := New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.ActiveKey() != "" || len(s.PublicKeys()) != 0 {
+			t.Errorf("got active key %q and keys %v, want messages not signed", s.ActiveKey(), s.PublicKeys())
+		}
+	})
+}
+
+// newTestSigner returns a signer with a new key, and its PEM encoded
+// public key.
+func newTestSigner(t *testing.T) (*Signer, string) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Signer{activeID: "key-1", active: private}, encodePublicKey(t, public)
+}
+
+func encodePublicKey(t *testing.T, public ed25519.PublicKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+// writeKey writes a new key pair in dir, and returns the paths of its
+// private and public key files.
+func writeKey(t *testing.T, dir, id string) (string, string) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privatePath := filepath.Join(dir, id+".pem")
+	if err := os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	publicPath := filepath.Join(dir, id+".pub")
+	if err := os.WriteFile(publicPath, []byte(encodePublicKey(t, public)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return privatePath, publicPath
+}
+
+func writeECDSAKey(t *testing.T, dir string) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "ecdsa.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newJWS returns a JWS of payload with the protected header h, signed with
+// key, or without signature when key is nil.
+func newJWS(t *testing.T, key ed25519.PrivateKey, h header, payload []byte) []byte {
+	t.Helper()
+	protected, err := json.Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jws := JWS{
+		Protected: base64.RawURLEncoding.EncodeToString(protected),
+		Payload:   base64.RawURLEncoding.EncodeToString(payload),
+	}
+	if key != nil {
+		jws.Signature = base64.RawURLEncoding.EncodeToString(ed25519.Sign(key, []byte(jws.Protected+"."+jws.Payload)))
+	}
+	data, err := json.Marshal(jws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// tamper replaces the payload of a JWS, keeping its signature.
+func tamper(t *testing.T, data []byte) []byte {
+	t.Helper()
+	jws := JWS{}
+	if err := json.Unmarshal(data, &jws); err != nil {
+		t.Fatal(err)
+	}
+	jws.Payload = base64.RawURLEncoding.EncodeToString([]byte(`{"id":"resource2"}`))
+	tampered, err := json.Marshal(jws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tampered
+}
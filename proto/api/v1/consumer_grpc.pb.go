@@ -22,6 +22,7 @@ const (
 	ConsumerService_Read_FullMethodName   = "/v1.ConsumerService/Read"
 	ConsumerService_Create_FullMethodName = "/v1.ConsumerService/Create"
 	ConsumerService_Update_FullMethodName = "/v1.ConsumerService/Update"
+	ConsumerService_Patch_FullMethodName  = "/v1.ConsumerService/Patch"
 )
 
 // ConsumerServiceClient is the client API for ConsumerService service.
@@ -31,6 +32,7 @@ type ConsumerServiceClient interface {
 	Read(ctx context.Context, in *ConsumerReadRequest, opts ...grpc.CallOption) (*Consumer, error)
 	Create(ctx context.Context, in *ConsumerCreateRequest, opts ...grpc.CallOption) (*Consumer, error)
 	Update(ctx context.Context, in *ConsumerUpdateRequest, opts ...grpc.CallOption) (*Consumer, error)
+	Patch(ctx context.Context, in *ConsumerPatchRequest, opts ...grpc.CallOption) (*Consumer, error)
 }
 
 type consumerServiceClient struct {
@@ -68,6 +70,15 @@ func (c *consumerServiceClient) Update(ctx context.Context, in *ConsumerUpdateRe
 	return out, nil
 }
 
+func (c *consumerServiceClient) Patch(ctx context.Context, in *ConsumerPatchRequest, opts ...grpc.CallOption) (*Consumer, error) {
+	out := new(Consumer)
+	err := c.cc.Invoke(ctx, ConsumerService_Patch_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ConsumerServiceServer is the server API for ConsumerService service.
 // All implementations must embed UnimplementedConsumerServiceServer
 // for forward compatibility
@@ -75,6 +86,7 @@ type ConsumerServiceServer interface {
 	Read(context.Context, *ConsumerReadRequest) (*Consumer, error)
 	Create(context.Context, *ConsumerCreateRequest) (*Consumer, error)
 	Update(context.Context, *ConsumerUpdateRequest) (*Consumer, error)
+	Patch(context.Context, *ConsumerPatchRequest) (*Consumer, error)
 	mustEmbedUnimplementedConsumerServiceServer()
 }
 
@@ -91,6 +103,9 @@ func (UnimplementedConsumerServiceServer) Create(context.Context, *ConsumerCreat
 func (UnimplementedConsumerServiceServer) Update(context.Context, *ConsumerUpdateRequest) (*Consumer, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
 }
+func (UnimplementedConsumerServiceServer) Patch(context.Context, *ConsumerPatchRequest) (*Consumer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Patch not implemented")
+}
 func (UnimplementedConsumerServiceServer) mustEmbedUnimplementedConsumerServiceServer() {}
 
 // UnsafeConsumerServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -158,6 +173,24 @@ func _ConsumerService_Update_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ConsumerService_Patch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConsumerPatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConsumerServiceServer).Patch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ConsumerService_Patch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConsumerServiceServer).Patch(ctx, req.(*ConsumerPatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ConsumerService_ServiceDesc is the grpc.ServiceDesc for ConsumerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -177,6 +210,10 @@ var ConsumerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Update",
 			Handler:    _ConsumerService_Update_Handler,
 		},
+		{
+			MethodName: "Patch",
+			Handler:    _ConsumerService_Patch_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/v1/consumer.proto",
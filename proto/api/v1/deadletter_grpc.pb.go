@@ -0,0 +1,226 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: api/v1/deadletter.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	DeadLetterService_Read_FullMethodName   = "/v1.DeadLetterService/Read"
+	DeadLetterService_List_FullMethodName   = "/v1.DeadLetterService/List"
+	DeadLetterService_Replay_FullMethodName = "/v1.DeadLetterService/Replay"
+	DeadLetterService_Delete_FullMethodName = "/v1.DeadLetterService/Delete"
+)
+
+// DeadLetterServiceClient is the client API for DeadLetterService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DeadLetterServiceClient interface {
+	Read(ctx context.Context, in *DeadLetterReadRequest, opts ...grpc.CallOption) (*DeadLetter, error)
+	List(ctx context.Context, in *DeadLetterListRequest, opts ...grpc.CallOption) (*DeadLetterList, error)
+	// Replay processes the message of a dead letter again, and removes the
+	// dead letter when it succeeds.
+	Replay(ctx context.Context, in *DeadLetterReplayRequest, opts ...grpc.CallOption) (*DeadLetter, error)
+	// Delete discards a dead letter.
+	Delete(ctx context.Context, in *DeadLetterDeleteRequest, opts ...grpc.CallOption) (*DeadLetter, error)
+}
+
+type deadLetterServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDeadLetterServiceClient(cc grpc.ClientConnInterface) DeadLetterServiceClient {
+	return &deadLetterServiceClient{cc}
+}
+
+func (c *deadLetterServiceClient) Read(ctx context.Context, in *DeadLetterReadRequest, opts ...grpc.CallOption) (*DeadLetter, error) {
+	out := new(DeadLetter)
+	err := c.cc.Invoke(ctx, DeadLetterService_Read_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deadLetterServiceClient) List(ctx context.Context, in *DeadLetterListRequest, opts ...grpc.CallOption) (*DeadLetterList, error) {
+	out := new(DeadLetterList)
+	err := c.cc.Invoke(ctx, DeadLetterService_List_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deadLetterServiceClient) Replay(ctx context.Context, in *DeadLetterReplayRequest, opts ...grpc.CallOption) (*DeadLetter, error) {
+	out := new(DeadLetter)
+	err := c.cc.Invoke(ctx, DeadLetterService_Replay_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deadLetterServiceClient) Delete(ctx context.Context, in *DeadLetterDeleteRequest, opts ...grpc.CallOption) (*DeadLetter, error) {
+	out := new(DeadLetter)
+	err := c.cc.Invoke(ctx, DeadLetterService_Delete_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeadLetterServiceServer is the server API for DeadLetterService service.
+// All implementations must embed UnimplementedDeadLetterServiceServer
+// for forward compatibility
+type DeadLetterServiceServer interface {
+	Read(context.Context, *DeadLetterReadRequest) (*DeadLetter, error)
+	List(context.Context, *DeadLetterListRequest) (*DeadLetterList, error)
+	// Replay processes the message of a dead letter again, and removes the
+	// dead letter when it succeeds.
+	Replay(context.Context, *DeadLetterReplayRequest) (*DeadLetter, error)
+	// Delete discards a dead letter.
+	Delete(context.Context, *DeadLetterDeleteRequest) (*DeadLetter, error)
+	mustEmbedUnimplementedDeadLetterServiceServer()
+}
+
+// UnimplementedDeadLetterServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedDeadLetterServiceServer struct {
+}
+
+func (UnimplementedDeadLetterServiceServer) Read(context.Context, *DeadLetterReadRequest) (*DeadLetter, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Read not implemented")
+}
+func (UnimplementedDeadLetterServiceServer) List(context.Context, *DeadLetterListRequest) (*DeadLetterList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedDeadLetterServiceServer) Replay(context.Context, *DeadLetterReplayRequest) (*DeadLetter, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Replay not implemented")
+}
+func (UnimplementedDeadLetterServiceServer) Delete(context.Context, *DeadLetterDeleteRequest) (*DeadLetter, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedDeadLetterServiceServer) mustEmbedUnimplementedDeadLetterServiceServer() {}
+
+// UnsafeDeadLetterServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DeadLetterServiceServer will
+// result in compilation errors.
+type UnsafeDeadLetterServiceServer interface {
+	mustEmbedUnimplementedDeadLetterServiceServer()
+}
+
+func RegisterDeadLetterServiceServer(s grpc.ServiceRegistrar, srv DeadLetterServiceServer) {
+	s.RegisterService(&DeadLetterService_ServiceDesc, srv)
+}
+
+func _DeadLetterService_Read_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeadLetterReadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeadLetterServiceServer).Read(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeadLetterService_Read_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeadLetterServiceServer).Read(ctx, req.(*DeadLetterReadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeadLetterService_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeadLetterListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeadLetterServiceServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeadLetterService_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeadLetterServiceServer).List(ctx, req.(*DeadLetterListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeadLetterService_Replay_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeadLetterReplayRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeadLetterServiceServer).Replay(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeadLetterService_Replay_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeadLetterServiceServer).Replay(ctx, req.(*DeadLetterReplayRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeadLetterService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeadLetterDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeadLetterServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeadLetterService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeadLetterServiceServer).Delete(ctx, req.(*DeadLetterDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DeadLetterService_ServiceDesc is the grpc.ServiceDesc for DeadLetterService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DeadLetterService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "v1.DeadLetterService",
+	HandlerType: (*DeadLetterServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Read",
+			Handler:    _DeadLetterService_Read_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _DeadLetterService_List_Handler,
+		},
+		{
+			MethodName: "Replay",
+			Handler:    _DeadLetterService_Replay_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _DeadLetterService_Delete_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/v1/deadletter.proto",
+}
@@ -0,0 +1,269 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: api/v1/placement.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	PlacementService_Read_FullMethodName   = "/v1.PlacementService/Read"
+	PlacementService_Create_FullMethodName = "/v1.PlacementService/Create"
+	PlacementService_Update_FullMethodName = "/v1.PlacementService/Update"
+	PlacementService_Resume_FullMethodName = "/v1.PlacementService/Resume"
+	PlacementService_Delete_FullMethodName = "/v1.PlacementService/Delete"
+)
+
+// PlacementServiceClient is the client API for PlacementService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PlacementServiceClient interface {
+	Read(ctx context.Context, in *PlacementReadRequest, opts ...grpc.CallOption) (*Placement, error)
+	Create(ctx context.Context, in *PlacementCreateRequest, opts ...grpc.CallOption) (*Placement, error)
+	// Update replaces the selector and object of a placement, rolling out
+	// the new generation to its targets. The resources of the consumers no
+	// longer matching are deleted.
+	Update(ctx context.Context, in *PlacementUpdateRequest, opts ...grpc.CallOption) (*Placement, error)
+	// Resume continues a paused rollout. The targets that failed so far no
+	// longer count towards its maxFailures.
+	Resume(ctx context.Context, in *PlacementResumeRequest, opts ...grpc.CallOption) (*Placement, error)
+	// Delete removes a placement and deletes its resources.
+	Delete(ctx context.Context, in *PlacementDeleteRequest, opts ...grpc.CallOption) (*Placement, error)
+}
+
+type placementServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPlacementServiceClient(cc grpc.ClientConnInterface) PlacementServiceClient {
+	return &placementServiceClient{cc}
+}
+
+func (c *placementServiceClient) Read(ctx context.Context, in *PlacementReadRequest, opts ...grpc.CallOption) (*Placement, error) {
+	out := new(Placement)
+	err := c.cc.Invoke(ctx, PlacementService_Read_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *placementServiceClient) Create(ctx context.Context, in *PlacementCreateRequest, opts ...grpc.CallOption) (*Placement, error) {
+	out := new(Placement)
+	err := c.cc.Invoke(ctx, PlacementService_Create_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *placementServiceClient) Update(ctx context.Context, in *PlacementUpdateRequest, opts ...grpc.CallOption) (*Placement, error) {
+	out := new(Placement)
+	err := c.cc.Invoke(ctx, PlacementService_Update_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *placementServiceClient) Resume(ctx context.Context, in *PlacementResumeRequest, opts ...grpc.CallOption) (*Placement, error) {
+	out := new(Placement)
+	err := c.cc.Invoke(ctx, PlacementService_Resume_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *placementServiceClient) Delete(ctx context.Context, in *PlacementDeleteRequest, opts ...grpc.CallOption) (*Placement, error) {
+	out := new(Placement)
+	err := c.cc.Invoke(ctx, PlacementService_Delete_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PlacementServiceServer is the server API for PlacementService service.
+// All implementations must embed UnimplementedPlacementServiceServer
+// for forward compatibility
+type PlacementServiceServer interface {
+	Read(context.Context, *PlacementReadRequest) (*Placement, error)
+	Create(context.Context, *PlacementCreateRequest) (*Placement, error)
+	// Update replaces the selector and object of a placement, rolling out
+	// the new generation to its targets. The resources of the consumers no
+	// longer matching are deleted.
+	Update(context.Context, *PlacementUpdateRequest) (*Placement, error)
+	// Resume continues a paused rollout. The targets that failed so far no
+	// longer count towards its maxFailures.
+	Resume(context.Context, *PlacementResumeRequest) (*Placement, error)
+	// Delete removes a placement and deletes its resources.
+	Delete(context.Context, *PlacementDeleteRequest) (*Placement, error)
+	mustEmbedUnimplementedPlacementServiceServer()
+}
+
+// UnimplementedPlacementServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedPlacementServiceServer struct {
+}
+
+func (UnimplementedPlacementServiceServer) Read(context.Context, *PlacementReadRequest) (*Placement, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Read not implemented")
+}
+func (UnimplementedPlacementServiceServer) Create(context.Context, *PlacementCreateRequest) (*Placement, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedPlacementServiceServer) Update(context.Context, *PlacementUpdateRequest) (*Placement, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedPlacementServiceServer) Resume(context.Context, *PlacementResumeRequest) (*Placement, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resume not implemented")
+}
+func (UnimplementedPlacementServiceServer) Delete(context.Context, *PlacementDeleteRequest) (*Placement, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedPlacementServiceServer) mustEmbedUnimplementedPlacementServiceServer() {}
+
+// UnsafePlacementServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PlacementServiceServer will
+// result in compilation errors.
+type UnsafePlacementServiceServer interface {
+	mustEmbedUnimplementedPlacementServiceServer()
+}
+
+func RegisterPlacementServiceServer(s grpc.ServiceRegistrar, srv PlacementServiceServer) {
+	s.RegisterService(&PlacementService_ServiceDesc, srv)
+}
+
+func _PlacementService_Read_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlacementReadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlacementServiceServer).Read(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlacementService_Read_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlacementServiceServer).Read(ctx, req.(*PlacementReadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlacementService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlacementCreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlacementServiceServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlacementService_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlacementServiceServer).Create(ctx, req.(*PlacementCreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlacementService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlacementUpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlacementServiceServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlacementService_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlacementServiceServer).Update(ctx, req.(*PlacementUpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlacementService_Resume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlacementResumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlacementServiceServer).Resume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlacementService_Resume_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlacementServiceServer).Resume(ctx, req.(*PlacementResumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlacementService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlacementDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlacementServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlacementService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlacementServiceServer).Delete(ctx, req.(*PlacementDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PlacementService_ServiceDesc is the grpc.ServiceDesc for PlacementService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PlacementService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "v1.PlacementService",
+	HandlerType: (*PlacementServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Read",
+			Handler:    _PlacementService_Read_Handler,
+		},
+		{
+			MethodName: "Create",
+			Handler:    _PlacementService_Create_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _PlacementService_Update_Handler,
+		},
+		{
+			MethodName: "Resume",
+			Handler:    _PlacementService_Resume_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _PlacementService_Delete_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/v1/placement.proto",
+}
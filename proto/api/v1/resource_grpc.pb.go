@@ -19,9 +19,16 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	ResourceService_Read_FullMethodName   = "/v1.ResourceService/Read"
-	ResourceService_Create_FullMethodName = "/v1.ResourceService/Create"
-	ResourceService_Update_FullMethodName = "/v1.ResourceService/Update"
+	ResourceService_Read_FullMethodName             = "/v1.ResourceService/Read"
+	ResourceService_Lookup_FullMethodName           = "/v1.ResourceService/Lookup"
+	ResourceService_Usage_FullMethodName            = "/v1.ResourceService/Usage"
+	ResourceService_Create_FullMethodName           = "/v1.ResourceService/Create"
+	ResourceService_Update_FullMethodName           = "/v1.ResourceService/Update"
+	ResourceService_Patch_FullMethodName            = "/v1.ResourceService/Patch"
+	ResourceService_Delete_FullMethodName           = "/v1.ResourceService/Delete"
+	ResourceService_History_FullMethodName          = "/v1.ResourceService/History"
+	ResourceService_SetDependencies_FullMethodName  = "/v1.ResourceService/SetDependencies"
+	ResourceService_SetFeedbackRules_FullMethodName = "/v1.ResourceService/SetFeedbackRules"
 )
 
 // ResourceServiceClient is the client API for ResourceService service.
@@ -29,8 +36,20 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ResourceServiceClient interface {
 	Read(ctx context.Context, in *ResourceReadRequest, opts ...grpc.CallOption) (*Resource, error)
+	Lookup(ctx context.Context, in *ResourceLookupRequest, opts ...grpc.CallOption) (*Resource, error)
+	// Usage returns the resource count and limits of a consumer.
+	Usage(ctx context.Context, in *ResourceUsageRequest, opts ...grpc.CallOption) (*ResourceUsage, error)
 	Create(ctx context.Context, in *ResourceCreateRequest, opts ...grpc.CallOption) (*Resource, error)
 	Update(ctx context.Context, in *ResourceUpdateRequest, opts ...grpc.CallOption) (*Resource, error)
+	Patch(ctx context.Context, in *ResourcePatchRequest, opts ...grpc.CallOption) (*Resource, error)
+	// Delete asks the agent to remove the object of a resource created
+	// directly, the resource is removed once it reports it did.
+	Delete(ctx context.Context, in *ResourceDeleteRequest, opts ...grpc.CallOption) (*Resource, error)
+	History(ctx context.Context, in *ResourceHistoryRequest, opts ...grpc.CallOption) (*ResourceHistory, error)
+	// SetDependencies replaces the dependencies of a resource. A held
+	// generation is published once the new dependencies are ready.
+	SetDependencies(ctx context.Context, in *ResourceDependenciesRequest, opts ...grpc.CallOption) (*Resource, error)
+	SetFeedbackRules(ctx context.Context, in *ResourceFeedbackRulesRequest, opts ...grpc.CallOption) (*Resource, error)
 }
 
 type resourceServiceClient struct {
@@ -50,6 +69,24 @@ func (c *resourceServiceClient) Read(ctx context.Context, in *ResourceReadReques
 	return out, nil
 }
 
+func (c *resourceServiceClient) Lookup(ctx context.Context, in *ResourceLookupRequest, opts ...grpc.CallOption) (*Resource, error) {
+	out := new(Resource)
+	err := c.cc.Invoke(ctx, ResourceService_Lookup_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *resourceServiceClient) Usage(ctx context.Context, in *ResourceUsageRequest, opts ...grpc.CallOption) (*ResourceUsage, error) {
+	out := new(ResourceUsage)
+	err := c.cc.Invoke(ctx, ResourceService_Usage_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *resourceServiceClient) Create(ctx context.Context, in *ResourceCreateRequest, opts ...grpc.CallOption) (*Resource, error) {
 	out := new(Resource)
 	err := c.cc.Invoke(ctx, ResourceService_Create_FullMethodName, in, out, opts...)
@@ -68,13 +105,70 @@ func (c *resourceServiceClient) Update(ctx context.Context, in *ResourceUpdateRe
 	return out, nil
 }
 
+func (c *resourceServiceClient) Patch(ctx context.Context, in *ResourcePatchRequest, opts ...grpc.CallOption) (*Resource, error) {
+	out := new(Resource)
+	err := c.cc.Invoke(ctx, ResourceService_Patch_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *resourceServiceClient) Delete(ctx context.Context, in *ResourceDeleteRequest, opts ...grpc.CallOption) (*Resource, error) {
+	out := new(Resource)
+	err := c.cc.Invoke(ctx, ResourceService_Delete_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *resourceServiceClient) History(ctx context.Context, in *ResourceHistoryRequest, opts ...grpc.CallOption) (*ResourceHistory, error) {
+	out := new(ResourceHistory)
+	err := c.cc.Invoke(ctx, ResourceService_History_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *resourceServiceClient) SetDependencies(ctx context.Context, in *ResourceDependenciesRequest, opts ...grpc.CallOption) (*Resource, error) {
+	out := new(Resource)
+	err := c.cc.Invoke(ctx, ResourceService_SetDependencies_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *resourceServiceClient) SetFeedbackRules(ctx context.Context, in *ResourceFeedbackRulesRequest, opts ...grpc.CallOption) (*Resource, error) {
+	out := new(Resource)
+	err := c.cc.Invoke(ctx, ResourceService_SetFeedbackRules_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ResourceServiceServer is the server API for ResourceService service.
 // All implementations must embed UnimplementedResourceServiceServer
 // for forward compatibility
 type ResourceServiceServer interface {
 	Read(context.Context, *ResourceReadRequest) (*Resource, error)
+	Lookup(context.Context, *ResourceLookupRequest) (*Resource, error)
+	// Usage returns the resource count and limits of a consumer.
+	Usage(context.Context, *ResourceUsageRequest) (*ResourceUsage, error)
 	Create(context.Context, *ResourceCreateRequest) (*Resource, error)
 	Update(context.Context, *ResourceUpdateRequest) (*Resource, error)
+	Patch(context.Context, *ResourcePatchRequest) (*Resource, error)
+	// Delete asks the agent to remove the object of a resource created
+	// directly, the resource is removed once it reports it did.
+	Delete(context.Context, *ResourceDeleteRequest) (*Resource, error)
+	History(context.Context, *ResourceHistoryRequest) (*ResourceHistory, error)
+	// SetDependencies replaces the dependencies of a resource. A held
+	// generation is published once the new dependencies are ready.
+	SetDependencies(context.Context, *ResourceDependenciesRequest) (*Resource, error)
+	SetFeedbackRules(context.Context, *ResourceFeedbackRulesRequest) (*Resource, error)
 	mustEmbedUnimplementedResourceServiceServer()
 }
 
@@ -85,12 +179,33 @@ type UnimplementedResourceServiceServer struct {
 func (UnimplementedResourceServiceServer) Read(context.Context, *ResourceReadRequest) (*Resource, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Read not implemented")
 }
+func (UnimplementedResourceServiceServer) Lookup(context.Context, *ResourceLookupRequest) (*Resource, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Lookup not implemented")
+}
+func (UnimplementedResourceServiceServer) Usage(context.Context, *ResourceUsageRequest) (*ResourceUsage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Usage not implemented")
+}
 func (UnimplementedResourceServiceServer) Create(context.Context, *ResourceCreateRequest) (*Resource, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
 }
 func (UnimplementedResourceServiceServer) Update(context.Context, *ResourceUpdateRequest) (*Resource, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
 }
+func (UnimplementedResourceServiceServer) Patch(context.Context, *ResourcePatchRequest) (*Resource, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Patch not implemented")
+}
+func (UnimplementedResourceServiceServer) Delete(context.Context, *ResourceDeleteRequest) (*Resource, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedResourceServiceServer) History(context.Context, *ResourceHistoryRequest) (*ResourceHistory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method History not implemented")
+}
+func (UnimplementedResourceServiceServer) SetDependencies(context.Context, *ResourceDependenciesRequest) (*Resource, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetDependencies not implemented")
+}
+func (UnimplementedResourceServiceServer) SetFeedbackRules(context.Context, *ResourceFeedbackRulesRequest) (*Resource, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetFeedbackRules not implemented")
+}
 func (UnimplementedResourceServiceServer) mustEmbedUnimplementedResourceServiceServer() {}
 
 // UnsafeResourceServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -122,6 +237,42 @@ func _ResourceService_Read_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ResourceService_Lookup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourceLookupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceServiceServer).Lookup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResourceService_Lookup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceServiceServer).Lookup(ctx, req.(*ResourceLookupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ResourceService_Usage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourceUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceServiceServer).Usage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResourceService_Usage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceServiceServer).Usage(ctx, req.(*ResourceUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ResourceService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ResourceCreateRequest)
 	if err := dec(in); err != nil {
@@ -158,6 +309,96 @@ func _ResourceService_Update_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ResourceService_Patch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourcePatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceServiceServer).Patch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResourceService_Patch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceServiceServer).Patch(ctx, req.(*ResourcePatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ResourceService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourceDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResourceService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceServiceServer).Delete(ctx, req.(*ResourceDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ResourceService_History_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourceHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceServiceServer).History(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResourceService_History_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceServiceServer).History(ctx, req.(*ResourceHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ResourceService_SetDependencies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourceDependenciesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceServiceServer).SetDependencies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResourceService_SetDependencies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceServiceServer).SetDependencies(ctx, req.(*ResourceDependenciesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ResourceService_SetFeedbackRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourceFeedbackRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceServiceServer).SetFeedbackRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResourceService_SetFeedbackRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceServiceServer).SetFeedbackRules(ctx, req.(*ResourceFeedbackRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ResourceService_ServiceDesc is the grpc.ServiceDesc for ResourceService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -169,6 +410,14 @@ var ResourceService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Read",
 			Handler:    _ResourceService_Read_Handler,
 		},
+		{
+			MethodName: "Lookup",
+			Handler:    _ResourceService_Lookup_Handler,
+		},
+		{
+			MethodName: "Usage",
+			Handler:    _ResourceService_Usage_Handler,
+		},
 		{
 			MethodName: "Create",
 			Handler:    _ResourceService_Create_Handler,
@@ -177,6 +426,26 @@ var ResourceService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Update",
 			Handler:    _ResourceService_Update_Handler,
 		},
+		{
+			MethodName: "Patch",
+			Handler:    _ResourceService_Patch_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _ResourceService_Delete_Handler,
+		},
+		{
+			MethodName: "History",
+			Handler:    _ResourceService_History_Handler,
+		},
+		{
+			MethodName: "SetDependencies",
+			Handler:    _ResourceService_SetDependencies_Handler,
+		},
+		{
+			MethodName: "SetFeedbackRules",
+			Handler:    _ResourceService_SetFeedbackRules_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/v1/resource.proto",
@@ -0,0 +1,220 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: api/v1/resourcebundle.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	ResourceBundleService_Read_FullMethodName   = "/v1.ResourceBundleService/Read"
+	ResourceBundleService_Create_FullMethodName = "/v1.ResourceBundleService/Create"
+	ResourceBundleService_Update_FullMethodName = "/v1.ResourceBundleService/Update"
+	ResourceBundleService_Delete_FullMethodName = "/v1.ResourceBundleService/Delete"
+)
+
+// ResourceBundleServiceClient is the client API for ResourceBundleService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ResourceBundleServiceClient interface {
+	Read(ctx context.Context, in *ResourceBundleReadRequest, opts ...grpc.CallOption) (*ResourceBundle, error)
+	Create(ctx context.Context, in *ResourceBundleCreateRequest, opts ...grpc.CallOption) (*ResourceBundle, error)
+	Update(ctx context.Context, in *ResourceBundleUpdateRequest, opts ...grpc.CallOption) (*ResourceBundle, error)
+	Delete(ctx context.Context, in *ResourceBundleDeleteRequest, opts ...grpc.CallOption) (*ResourceBundle, error)
+}
+
+type resourceBundleServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewResourceBundleServiceClient(cc grpc.ClientConnInterface) ResourceBundleServiceClient {
+	return &resourceBundleServiceClient{cc}
+}
+
+func (c *resourceBundleServiceClient) Read(ctx context.Context, in *ResourceBundleReadRequest, opts ...grpc.CallOption) (*ResourceBundle, error) {
+	out := new(ResourceBundle)
+	err := c.cc.Invoke(ctx, ResourceBundleService_Read_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *resourceBundleServiceClient) Create(ctx context.Context, in *ResourceBundleCreateRequest, opts ...grpc.CallOption) (*ResourceBundle, error) {
+	out := new(ResourceBundle)
+	err := c.cc.Invoke(ctx, ResourceBundleService_Create_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *resourceBundleServiceClient) Update(ctx context.Context, in *ResourceBundleUpdateRequest, opts ...grpc.CallOption) (*ResourceBundle, error) {
+	out := new(ResourceBundle)
+	err := c.cc.Invoke(ctx, ResourceBundleService_Update_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *resourceBundleServiceClient) Delete(ctx context.Context, in *ResourceBundleDeleteRequest, opts ...grpc.CallOption) (*ResourceBundle, error) {
+	out := new(ResourceBundle)
+	err := c.cc.Invoke(ctx, ResourceBundleService_Delete_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ResourceBundleServiceServer is the server API for ResourceBundleService service.
+// All implementations must embed UnimplementedResourceBundleServiceServer
+// for forward compatibility
+type ResourceBundleServiceServer interface {
+	Read(context.Context, *ResourceBundleReadRequest) (*ResourceBundle, error)
+	Create(context.Context, *ResourceBundleCreateRequest) (*ResourceBundle, error)
+	Update(context.Context, *ResourceBundleUpdateRequest) (*ResourceBundle, error)
+	Delete(context.Context, *ResourceBundleDeleteRequest) (*ResourceBundle, error)
+	mustEmbedUnimplementedResourceBundleServiceServer()
+}
+
+// UnimplementedResourceBundleServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedResourceBundleServiceServer struct {
+}
+
+func (UnimplementedResourceBundleServiceServer) Read(context.Context, *ResourceBundleReadRequest) (*ResourceBundle, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Read not implemented")
+}
+func (UnimplementedResourceBundleServiceServer) Create(context.Context, *ResourceBundleCreateRequest) (*ResourceBundle, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedResourceBundleServiceServer) Update(context.Context, *ResourceBundleUpdateRequest) (*ResourceBundle, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedResourceBundleServiceServer) Delete(context.Context, *ResourceBundleDeleteRequest) (*ResourceBundle, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedResourceBundleServiceServer) mustEmbedUnimplementedResourceBundleServiceServer() {}
+
+// UnsafeResourceBundleServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ResourceBundleServiceServer will
+// result in compilation errors.
+type UnsafeResourceBundleServiceServer interface {
+	mustEmbedUnimplementedResourceBundleServiceServer()
+}
+
+func RegisterResourceBundleServiceServer(s grpc.ServiceRegistrar, srv ResourceBundleServiceServer) {
+	s.RegisterService(&ResourceBundleService_ServiceDesc, srv)
+}
+
+func _ResourceBundleService_Read_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourceBundleReadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceBundleServiceServer).Read(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResourceBundleService_Read_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceBundleServiceServer).Read(ctx, req.(*ResourceBundleReadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ResourceBundleService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourceBundleCreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceBundleServiceServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResourceBundleService_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceBundleServiceServer).Create(ctx, req.(*ResourceBundleCreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ResourceBundleService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourceBundleUpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceBundleServiceServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResourceBundleService_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceBundleServiceServer).Update(ctx, req.(*ResourceBundleUpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ResourceBundleService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourceBundleDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResourceBundleServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResourceBundleService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResourceBundleServiceServer).Delete(ctx, req.(*ResourceBundleDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ResourceBundleService_ServiceDesc is the grpc.ServiceDesc for ResourceBundleService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ResourceBundleService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "v1.ResourceBundleService",
+	HandlerType: (*ResourceBundleServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Read",
+			Handler:    _ResourceBundleService_Read_Handler,
+		},
+		{
+			MethodName: "Create",
+			Handler:    _ResourceBundleService_Create_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _ResourceBundleService_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _ResourceBundleService_Delete_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/v1/resourcebundle.proto",
+}
@@ -0,0 +1,113 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: api/v1/signingkey.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	SigningKeyService_List_FullMethodName = "/v1.SigningKeyService/List"
+)
+
+// SigningKeyServiceClient is the client API for SigningKeyService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SigningKeyServiceClient interface {
+	// List returns the public keys verifying the messages published to
+	// agents.
+	List(ctx context.Context, in *SigningKeyListRequest, opts ...grpc.CallOption) (*SigningKeyList, error)
+}
+
+type signingKeyServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSigningKeyServiceClient(cc grpc.ClientConnInterface) SigningKeyServiceClient {
+	return &signingKeyServiceClient{cc}
+}
+
+func (c *signingKeyServiceClient) List(ctx context.Context, in *SigningKeyListRequest, opts ...grpc.CallOption) (*SigningKeyList, error) {
+	out := new(SigningKeyList)
+	err := c.cc.Invoke(ctx, SigningKeyService_List_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SigningKeyServiceServer is the server API for SigningKeyService service.
+// All implementations must embed UnimplementedSigningKeyServiceServer
+// for forward compatibility
+type SigningKeyServiceServer interface {
+	// List returns the public keys verifying the messages published to
+	// agents.
+	List(context.Context, *SigningKeyListRequest) (*SigningKeyList, error)
+	mustEmbedUnimplementedSigningKeyServiceServer()
+}
+
+// UnimplementedSigningKeyServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedSigningKeyServiceServer struct {
+}
+
+func (UnimplementedSigningKeyServiceServer) List(context.Context, *SigningKeyListRequest) (*SigningKeyList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedSigningKeyServiceServer) mustEmbedUnimplementedSigningKeyServiceServer() {}
+
+// UnsafeSigningKeyServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SigningKeyServiceServer will
+// result in compilation errors.
+type UnsafeSigningKeyServiceServer interface {
+	mustEmbedUnimplementedSigningKeyServiceServer()
+}
+
+func RegisterSigningKeyServiceServer(s grpc.ServiceRegistrar, srv SigningKeyServiceServer) {
+	s.RegisterService(&SigningKeyService_ServiceDesc, srv)
+}
+
+func _SigningKeyService_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SigningKeyListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SigningKeyServiceServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SigningKeyService_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SigningKeyServiceServer).List(ctx, req.(*SigningKeyListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SigningKeyService_ServiceDesc is the grpc.ServiceDesc for SigningKeyService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SigningKeyService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "v1.SigningKeyService",
+	HandlerType: (*SigningKeyServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "List",
+			Handler:    _SigningKeyService_List_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/v1/signingkey.proto",
+}